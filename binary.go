@@ -3,10 +3,12 @@ package texture
 import "math/rand"
 
 type Binary struct {
-	Name string
-	Seed int64
-	Perc float64
-	bits [][]bool
+	Name   string
+	Width  int
+	Height int
+	Seed   int64
+	Perc   float64
+	bits   [][]bool
 }
 
 func NewBinary(width, height int, seed int64, perc float64) *Binary {
@@ -18,7 +20,7 @@ func NewBinary(width, height int, seed int64, perc float64) *Binary {
 			ba[i][j] = lr.Float64() < perc
 		}
 	}
-	return &Binary{"Binary", seed, perc, ba}
+	return &Binary{"Binary", width, height, seed, perc, ba}
 }
 
 func (b *Binary) Eval2(x, y float64) float64 {
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	g2d "github.com/jphsd/graphics2d"
+	gi "github.com/jphsd/graphics2d/image"
+	"github.com/jphsd/texture"
+	"github.com/jphsd/texture/color"
+	"github.com/jphsd/texture/surface"
+)
+
+// bumps lights a tree with a directional light. A field is treated as a height map and a vector field as
+// the normals. One image is saved per roughness value, or a contact sheet of them all.
+func bumps(args []string) error {
+	fs := flag.NewFlagSet("bumps", flag.ExitOnError)
+	reg := regionFlags(fs, 800, 800)
+	tree := fs.String("tree", "", "JSON or DSL field or vector field (default a grid of domes)")
+	height := fs.Float64("height", 20, "height map scale")
+	light := fs.String("light", "1,1,1", "direction of the light")
+	lcol := fs.String("lcolor", "white", "CSS color of the light")
+	diffuse := fs.String("diffuse", "green", "CSS color of the diffuse reflection")
+	specular := fs.String("specular", "red", "CSS color of the specular reflection")
+	shininess := fs.Float64("shininess", 5, "specular exponent")
+	rough := fs.String("roughness", "0", "comma separated roughness values in [0,1]")
+	sheet := fs.Bool("sheet", false, "save the roughness values as a single contact sheet")
+	out := fs.String("o", "bumps", "output name prefix")
+	fs.Parse(args)
+
+	var nm texture.VectorField
+	if *tree == "" {
+		nlf := texture.NewNLCircle1()
+		wf := texture.NewNLWave([]float64{60}, []*texture.NonLinear{nlf}, false, true)
+		rg := texture.NewRadialGradient(wf)
+		xg := texture.NewTransform(rg, g2d.Translate(-60, -60))
+		tf := texture.NewTiler(xg, []float64{120, 120})
+		nm = texture.NewNormal(tf, *height, *height, 1, 1)
+	} else {
+		src, err := loadTree(*tree)
+		if err != nil {
+			return err
+		}
+		switch s := src.(type) {
+		case texture.Field:
+			nm = texture.NewNormal(s, *height, *height, 1, 1)
+		case texture.VectorField:
+			nm = s
+		default:
+			return fmt.Errorf("%T is not a field or vector field", src)
+		}
+	}
+
+	dir, err := parseFloats(*light)
+	if err != nil {
+		return err
+	}
+	if len(dir) != 3 {
+		return fmt.Errorf("light direction needs 3 values")
+	}
+	rvals, err := parseFloats(*rough)
+	if err != nil {
+		return err
+	}
+	cols := make([]color.FRGBA, 3)
+	for i, s := range []string{*lcol, *diffuse, *specular} {
+		if cols[i], err = texture.ParseCSSColor(s); err != nil {
+			return err
+		}
+	}
+
+	lights := []surface.Light{surface.NewDirectional(cols[0], dir)}
+	mat := &material{
+		color.FRGBA{0, 0, 0, 1}, // Emissive
+		color.FRGBA{1, 1, 1, 1}, // Ambient reflection
+		cols[1],                 // Diffuse reflection
+		cols[2],                 // Specular reflection
+		*shininess,              // Shininess
+		0,                       // Roughness [0,1]
+	}
+	surf := &surface.Surface{surface.DefaultAmbient, lights, mat, nm, nil, false}
+
+	if *sheet {
+		img, err := texture.SweepFunc(func(r, _ float64) (texture.ColorField, error) {
+			// The cells are all made before any are realized, so each needs its own material
+			m := *mat
+			m.Roughness = r
+			return &surface.Surface{surface.DefaultAmbient, lights, &m, nm, nil, false}, nil
+		}, reg.width, reg.height, reg.ox, reg.oy, reg.dx, reg.dy, &texture.SweepParam{"Roughness", rvals}, nil)
+		if err != nil {
+			return err
+		}
+		return gi.SaveImage(img, *out)
+	}
+
+	for i, r := range rvals {
+		mat.Roughness = r
+		name := *out
+		if len(rvals) > 1 {
+			name = fmt.Sprintf("%s%d", name, i)
+		}
+		if err := reg.save(surf, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type material struct {
+	Emissive, Ambient, Diffuse, Specular color.FRGBA
+	Shininess                            float64
+	Roughness                            float64
+}
+
+func (m *material) Eval2(x, y float64) (color.FRGBA, color.FRGBA, color.FRGBA, color.FRGBA, float64, float64) {
+	return m.Emissive, m.Ambient, m.Diffuse, m.Specular, m.Shininess, m.Roughness
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	gi "github.com/jphsd/graphics2d/image"
+	"github.com/jphsd/texture"
+	"github.com/jphsd/texture/random"
+	"github.com/jphsd/texture/surface"
+	"image"
+	"image/draw"
+)
+
+// component renders random components with their color, value and bump mapped vector fields side by side.
+func component(args []string) error {
+	fs := flag.NewFlagSet("component", flag.ExitOnError)
+	reg := regionFlags(fs, 400, 400)
+	n := fs.Int("n", 10, "number of components")
+	sd := seedFlag(fs)
+	out := fs.String("o", "", "output name prefix")
+	fs.Parse(args)
+
+	seed(*sd)
+	width, height := reg.width, reg.height
+	for i := 0; i < *n; i++ {
+		res := image.NewRGBA(image.Rect(0, 0, width*3, height))
+		cf := random.MakeComponent()
+		// Color
+		img := texture.NewTextureRGBA(width, height, cf.Color, reg.ox, reg.oy, reg.dx, reg.dy, false)
+		draw.Draw(res, image.Rect(0, 0, width, height), img, image.Point{}, draw.Src)
+		// Alpha
+		gimg := texture.NewTextureGray16(width, height, cf.Value, reg.ox, reg.oy, reg.dx, reg.dy, false)
+		draw.Draw(res, image.Rect(width, 0, 2*width, height), gimg, image.Point{}, draw.Src)
+		// Bump Map
+		bm := &surface.BumpMap{surface.DefaultAmbient, nil, nil, cf.Vector}
+		img = texture.NewTextureRGBA(width, height, bm, reg.ox, reg.oy, reg.dx, reg.dy, false)
+		draw.Draw(res, image.Rect(2*width, 0, 3*width, height), img, image.Point{}, draw.Src)
+		if err := gi.SaveImage(res, fmt.Sprintf("%s%06d", *out, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
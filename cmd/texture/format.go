@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/jphsd/texture"
+	"os"
+)
+
+// format prints a tree as DSL text, or as JSON, to convert between them.
+func format(args []string) error {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: texture fmt [flags] tree.json|tree.tdsl\n")
+		fs.PrintDefaults()
+	}
+	asJSON := fs.Bool("json", false, "print JSON rather than DSL text")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one tree")
+	}
+
+	tree, err := loadTree(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return texture.WriteJSON(os.Stdout, tree)
+	}
+	s, err := texture.FormatDSL(tree)
+	if err != nil {
+		return err
+	}
+	fmt.Print(s)
+	return nil
+}
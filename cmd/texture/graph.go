@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/jphsd/texture"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// graph writes a tree as a Graphviz DOT or Mermaid diagram, with optional thumbnails of its leaves.
+func graph(args []string) error {
+	fs := flag.NewFlagSet("graph", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: texture graph [flags] tree.json|tree.tdsl\n")
+		fs.PrintDefaults()
+	}
+	r := regionFlags(fs, 800, 800)
+	mermaid := fs.Bool("mermaid", false, "write a Mermaid flowchart rather than DOT")
+	thumbs := fs.Int("thumbs", 0, "size of the leaf thumbnails, none if 0")
+	out := fs.String("o", "", "output name, without extension (default is the tree's)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one tree")
+	}
+
+	tree, err := loadTree(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	name := *out
+	if name == "" {
+		name = strings.TrimSuffix(fs.Arg(0), filepath.Ext(fs.Arg(0)))
+	}
+
+	g := texture.NewGraph(tree)
+	if *thumbs > 0 {
+		// The thumbnails cover the same region as a w by h render
+		sx, sy := float64(r.width)/float64(*thumbs), float64(r.height)/float64(*thumbs)
+		if err := g.Thumbnails(name+"-", *thumbs, r.ox, r.oy, r.dx*sx, r.dy*sy); err != nil {
+			return err
+		}
+	}
+
+	ext := ".dot"
+	if *mermaid {
+		ext = ".mmd"
+	}
+	f, err := os.Create(name + ext)
+	if err != nil {
+		return err
+	}
+	if *mermaid {
+		err = g.WriteMermaid(f)
+	} else {
+		err = g.WriteDOT(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+// Command texture renders texture trees, saved as JSON or DSL text, and generates random ones.
+//
+// Usage:
+//
+//	texture <command> [flags] [args]
+//
+// The commands are:
+//
+//	render     render a JSON or DSL tree to PNG or EXR
+//	random     generate seeded random trees and save their images and JSON
+//	kaleido    reflect a tree into a kaleidoscope
+//	triang     reflect a tree within a triangle
+//	warp       apply a warp function to a tree
+//	component  render random components as color, value and bump map
+//	bumps      light a tree with the surface package
+//	sweep      render a contact sheet of parameter or seed variants
+//	serve      run a local node graph editor with a progressive preview
+//	fmt        print a tree as DSL text or JSON
+//	graph      write a tree as a Graphviz DOT or Mermaid diagram
+//	opt        print an optimized tree and the estimated speedup
+//	shader     print a tree as a GLSL or WGSL function
+//
+// Run texture <command> -h for the flags of each command.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{"render", "render a JSON or DSL tree to PNG or EXR", render},
+		{"random", "generate seeded random trees and save their images and JSON", randomTrees},
+		{"kaleido", "reflect a tree into a kaleidoscope", kaleido},
+		{"triang", "reflect a tree within a triangle", triang},
+		{"warp", "apply a warp function to a tree", warp},
+		{"component", "render random components as color, value and bump map", component},
+		{"bumps", "light a tree with the surface package", bumps},
+		{"sweep", "render a contact sheet of parameter or seed variants", sweep},
+		{"serve", "run a local node graph editor with a progressive preview", serve},
+		{"fmt", "print a tree as DSL text or JSON", format},
+		{"graph", "write a tree as a Graphviz DOT or Mermaid diagram", graph},
+		{"opt", "print an optimized tree and the estimated speedup", optimize},
+		{"shader", "print a tree as a GLSL or WGSL function", shader},
+	}
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := flag.Arg(0)
+	for _, c := range commands {
+		if c.name == name {
+			if err := c.run(flag.Args()[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "texture %s: %v\n", name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "texture: unknown command %q\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: texture <command> [flags] [args]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.usage)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/jphsd/texture"
+	"os"
+)
+
+// optimize prints an optimized tree as DSL text, or as JSON, and reports the changes on stderr.
+func optimize(args []string) error {
+	fs := flag.NewFlagSet("opt", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: texture opt [flags] tree.json|tree.tdsl\n")
+		fs.PrintDefaults()
+	}
+	asJSON := fs.Bool("json", false, "print JSON rather than DSL text")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one tree")
+	}
+
+	tree, err := loadTree(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	tree, stats := texture.Optimize(tree)
+	fmt.Fprintln(os.Stderr, stats)
+	if *asJSON {
+		return texture.WriteJSON(os.Stdout, tree)
+	}
+	s, err := texture.FormatDSL(tree)
+	if err != nil {
+		return err
+	}
+	fmt.Print(s)
+	return nil
+}
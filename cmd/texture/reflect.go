@@ -0,0 +1,138 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	g2d "github.com/jphsd/graphics2d"
+	"github.com/jphsd/texture"
+	"math"
+)
+
+// reflectFlags holds the flags shared by kaleido and triang.
+type reflectFlags struct {
+	reg    *region
+	tree   *string
+	seed   *int64
+	count  *int
+	cx, cy *float64
+	r      *float64
+	zoom   *float64
+	src    *bool
+	out    *string
+}
+
+func newReflectFlags(fs *flag.FlagSet, out string) *reflectFlags {
+	return &reflectFlags{
+		regionFlags(fs, 800, 800),
+		fs.String("tree", "", "JSON or DSL tree to reflect (default a random color field)"),
+		seedFlag(fs),
+		fs.Int("count", 1, "number of random trees to reflect"),
+		fs.Float64("cx", math.NaN(), "x of the center (default image center)"),
+		fs.Float64("cy", math.NaN(), "y of the center (default image center)"),
+		fs.Float64("r", 0, "radius (default half the smaller image dimension)"),
+		fs.Float64("zoom", 1, "scale the result about the center by this factor"),
+		fs.Bool("src", false, "save the unreflected source too"),
+		fs.String("o", out, "output name prefix"),
+	}
+}
+
+// run calls reflect with each source tree and saves the results.
+func (rf *reflectFlags) run(reflect func(cf texture.ColorField, cx, cy, r float64) texture.ColorField) error {
+	reg := rf.reg
+	cx, cy, r := *rf.cx, *rf.cy, *rf.r
+	if math.IsNaN(cx) {
+		cx = reg.ox + float64(reg.width)*reg.dx/2
+	}
+	if math.IsNaN(cy) {
+		cy = reg.oy + float64(reg.height)*reg.dy/2
+	}
+	if r <= 0 {
+		r = min(float64(reg.width)*reg.dx, float64(reg.height)*reg.dy) / 2
+	}
+	count := *rf.count
+	if *rf.tree != "" {
+		count = 1
+	}
+
+	seed(*rf.seed)
+	for i := 0; i < count; i++ {
+		tree, err := sourceTree(*rf.tree)
+		if err != nil {
+			return err
+		}
+		cf, err := texture.ToColorField(tree)
+		if err != nil {
+			return err
+		}
+		name := fmt.Sprintf("%s%06d", *rf.out, i)
+		if *rf.src {
+			if err := reg.save(cf, name+"-src"); err != nil {
+				return err
+			}
+		}
+		cf = reflect(cf, cx, cy, r)
+		if *rf.zoom != 1 {
+			xfm := g2d.NewAff3()
+			xfm.ScaleAbout(*rf.zoom, *rf.zoom, cx, cy)
+			cf = texture.NewTransformCF(cf, xfm)
+		}
+		if err := reg.save(cf, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kaleido reflects a tree about n lines through the center and then about the rim of a 2n sided polygon.
+func kaleido(args []string) error {
+	fs := flag.NewFlagSet("kaleido", flag.ExitOnError)
+	n := fs.Int("n", 6, "number of reflections")
+	rf := newReflectFlags(fs, "kaleido")
+	fs.Parse(args)
+
+	return rf.run(func(cf texture.ColorField, cx, cy, r float64) texture.ColorField {
+		a := 0.0
+		da := math.Pi / float64(*n)
+		pts := make([][]float64, *n*2)
+		for i := range pts {
+			pts[i] = []float64{cx + math.Cos(a)*r, cy + math.Sin(a)*r}
+			a += da
+		}
+
+		c := []float64{cx, cy}
+		for i := 0; i < *n; i++ {
+			cf = texture.NewReflectCF(cf, c, pts[i])
+		}
+
+		// Place rim
+		prev := pts[0]
+		for _, cur := range pts[1:] {
+			cf = texture.NewReflectCF(cf, prev, cur)
+			prev = cur
+		}
+		return texture.NewReflectCF(cf, prev, pts[0])
+	})
+}
+
+// triang reflects a tree about the sides of an equilateral triangle inscribed in the circle about the center.
+func triang(args []string) error {
+	fs := flag.NewFlagSet("triang", flag.ExitOnError)
+	rf := newReflectFlags(fs, "triang")
+	fs.Parse(args)
+
+	return rf.run(func(cf texture.ColorField, cx, cy, r float64) texture.ColorField {
+		pts := make([][]float64, 3)
+		for i := range pts {
+			a := -math.Pi/2 + float64(i)*2*math.Pi/3
+			pts[i] = []float64{cx + math.Cos(a)*r, cy + math.Sin(a)*r}
+		}
+
+		cf = texture.NewReflectCF(cf, pts[0], pts[1])
+		cf = texture.NewReflectCF(cf, pts[1], pts[2])
+		cf = texture.NewReflectCF(cf, pts[2], pts[0])
+
+		// Repeat to catch other reflections
+		cf = texture.NewReflectCF(cf, pts[0], pts[1])
+		return texture.NewReflectCF(cf, pts[1], pts[2])
+	})
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/jphsd/texture"
+	"github.com/jphsd/texture/random"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// render renders a JSON or DSL tree to PNG or EXR.
+func render(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: texture render [flags] tree.json|tree.tdsl\n")
+		fs.PrintDefaults()
+	}
+	reg := regionFlags(fs, 800, 800)
+	out := fs.String("o", "", "output name without extension (default tree name)")
+	exr := fs.Bool("exr", false, "write a half float, ZIP compressed OpenEXR file instead of PNG")
+	prof := fs.Bool("profile", false, "report the time spent in each node and write flame graph stacks to name.folded")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one tree")
+	}
+
+	tree, err := loadTree(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	name := *out
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(fs.Arg(0)), filepath.Ext(fs.Arg(0)))
+	}
+	var p *texture.Profile
+	if *prof {
+		p = texture.NewProfile(tree)
+		tree = p.Root
+	}
+	if *exr {
+		err = texture.SaveEXR(name, tree, reg.width, reg.height, reg.ox, reg.oy, reg.dx, reg.dy,
+			texture.EXRHalf, texture.EXRZIPCompression)
+	} else {
+		err = reg.save(tree, name)
+	}
+	if err != nil || p == nil {
+		return err
+	}
+	return writeProfile(p, name)
+}
+
+// writeProfile reports the profile on stderr and writes its stacks to name.folded.
+func writeProfile(p *texture.Profile, name string) error {
+	if err := p.WriteReport(os.Stderr); err != nil {
+		return err
+	}
+	f, err := os.Create(name + ".folded")
+	if err != nil {
+		return err
+	}
+	err = p.WriteFolded(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// randomTrees generates seeded random color fields and saves their images and JSON.
+func randomTrees(args []string) error {
+	fs := flag.NewFlagSet("random", flag.ExitOnError)
+	reg := regionFlags(fs, 800, 800)
+	n := fs.Int("n", 10, "number of trees")
+	depth := fs.Int("depth", 6, "maximum tree depth")
+	sd := seedFlag(fs)
+	sample := fs.String("sample", "", "image for the image leaves")
+	out := fs.String("o", "", "output name prefix")
+	fs.Parse(args)
+
+	if err := sampleImage(*sample); err != nil {
+		return err
+	}
+	s := seed(*sd)
+	for i := 0; i < *n; i++ {
+		// Each tree is seeded separately so it can be recreated with -seed and -n 1, although
+		// stochastic and jitter blends render differently each time
+		seed(s + int64(i))
+		name := fmt.Sprintf("%s%06d", *out, i)
+		cf := random.MakeColorField(*depth, 0)
+		if err := reg.save(cf, name); err != nil {
+			return err
+		}
+		if err := texture.SaveJSON(cf, name); err != nil {
+			// Some nodes, such as WorleyField, can't be marshaled
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		}
+		fmt.Printf("%s seed %d\n", name, s+int64(i))
+	}
+	return nil
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"flag"
+	"fmt"
+	"github.com/jphsd/texture"
+	"image/color"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed editor.html
+var editorPage []byte
+
+// Preview tile size in pixels.
+const previewTile = 64
+
+// serve runs a local HTTP server with a node graph editor and progressive preview of the current tree.
+func serve(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	dir := fs.String("dir", ".", "directory for saving and loading trees")
+	tree := fs.String("tree", "", "JSON or DSL tree to start with (default a Perlin field)")
+	fs.Parse(args)
+
+	s := &server{dir: *dir}
+	var t any = texture.NewPerlin(1)
+	if *tree != "" {
+		var err error
+		if t, err = loadTree(*tree); err != nil {
+			return err
+		}
+	}
+	if err := s.setTree(t); err != nil {
+		return err
+	}
+	s.types = nodeTypes()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(editorPage)
+	})
+	mux.HandleFunc("GET /api/types", s.handleTypes)
+	mux.HandleFunc("GET /api/node", s.handleNode)
+	mux.HandleFunc("GET /api/tree", s.handleGetTree)
+	mux.HandleFunc("POST /api/tree", s.handlePostTree)
+	mux.HandleFunc("GET /api/tile", s.handleTile)
+	mux.HandleFunc("GET /api/files", s.handleFiles)
+	mux.HandleFunc("GET /api/load", s.handleLoad)
+	mux.HandleFunc("POST /api/save", s.handleSave)
+
+	log.Printf("serving the editor on http://%s/", *addr)
+	return http.ListenAndServe(*addr, mux)
+}
+
+// server holds the current tree. Every change to the tree increments its version so that stale tile
+// requests can be rejected.
+type server struct {
+	dir   string
+	types []typeInfo
+
+	mu      sync.Mutex // Guards tree, cf and version
+	tree    any
+	cf      texture.ColorField
+	version int
+
+	// Nodes with caches aren't safe for concurrent use, so tiles are rendered one at a time
+	render sync.Mutex
+}
+
+// typeInfo describes a node type for the editor.
+type typeInfo struct {
+	Name   string
+	Fields []fieldInfo
+}
+
+// fieldInfo describes a field of a node type. Kind is "node" for a single child, "nodes" for a list of
+// children and "value" otherwise. Types lists the node types that can be used for the children.
+type fieldInfo struct {
+	Name  string
+	Kind  string
+	Types []string `json:",omitempty"`
+}
+
+func (s *server) setTree(t any) error {
+	cf, err := texture.ToColorField(t)
+	if err != nil {
+		return err
+	}
+	if err := tryEval(cf); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tree, s.cf = t, cf
+	s.version++
+	return nil
+}
+
+// tryEval checks that the tree can be evaluated, catching the panics from incomplete nodes.
+func tryEval(cf texture.ColorField) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("tree can't be evaluated: %v", r)
+		}
+	}()
+	cf.Eval2(0.5, 0.5)
+	return nil
+}
+
+func (s *server) current() (any, texture.ColorField, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree, s.cf, s.version
+}
+
+func (s *server) handleTypes(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.types)
+}
+
+func (s *server) handleNode(w http.ResponseWriter, r *http.Request) {
+	n, err := texture.NewNode(r.URL.Query().Get("name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, n)
+}
+
+func (s *server) handleGetTree(w http.ResponseWriter, r *http.Request) {
+	t, _, v := s.current()
+	writeJSON(w, map[string]any{"Version": v, "Tree": t})
+}
+
+func (s *server) handlePostTree(w http.ResponseWriter, r *http.Request) {
+	t, err := texture.ReadJSON(io.LimitReader(r.Body, 16<<20))
+	if err == nil {
+		err = s.setTree(t)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	_, _, v := s.current()
+	writeJSON(w, map[string]any{"Version": v})
+}
+
+// handleTile renders a tile of the preview pyramid for the tree version in v. The preview is size pixels
+// square, starting at ox, oy with a step of dx.
+func (s *server) handleTile(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	ints := map[string]int{}
+	for _, k := range []string{"v", "level", "col", "row", "size"} {
+		i, err := strconv.Atoi(q.Get(k))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("bad %s: %v", k, err), http.StatusBadRequest)
+			return
+		}
+		ints[k] = i
+	}
+	floats := map[string]float64{}
+	for _, k := range []string{"ox", "oy", "dx"} {
+		f, err := strconv.ParseFloat(q.Get(k), 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("bad %s: %v", k, err), http.StatusBadRequest)
+			return
+		}
+		floats[k] = f
+	}
+	size := ints["size"]
+	if size < 1 || size > 4096 {
+		http.Error(w, "bad size", http.StatusBadRequest)
+		return
+	}
+
+	_, cf, v := s.current()
+	if v != ints["v"] {
+		http.Error(w, "tree has changed", http.StatusConflict)
+		return
+	}
+	p := texture.NewTilePyramid(size, size, cf, floats["ox"], floats["oy"], floats["dx"], floats["dx"], previewTile, 0, texture.DZITiles)
+	level, col, row := ints["level"], ints["col"], ints["row"]
+	cols, rows := p.Tiles(level)
+	if level < 0 || level > p.MaxLevel() || col < 0 || col >= cols || row < 0 || row >= rows {
+		http.Error(w, "no such tile", http.StatusNotFound)
+		return
+	}
+
+	buf, err := s.renderTile(p.Tile(level, col, row))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(buf.Bytes())
+}
+
+func (s *server) renderTile(img *texture.TextureRGBA) (buf *bytes.Buffer, err error) {
+	s.render.Lock()
+	defer s.render.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("evaluation failed: %v", r)
+		}
+	}()
+	buf = &bytes.Buffer{}
+	err = png.Encode(buf, img)
+	return
+}
+
+func (s *server) handleFiles(w http.ResponseWriter, r *http.Request) {
+	names, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	res := []string{}
+	for _, n := range names {
+		res = append(res, strings.TrimSuffix(filepath.Base(n), ".json"))
+	}
+	writeJSON(w, res)
+}
+
+func (s *server) handleLoad(w http.ResponseWriter, r *http.Request) {
+	name, ok := s.fileName(w, r)
+	if !ok {
+		return
+	}
+	t, err := texture.LoadJSON(name)
+	if err == nil {
+		err = s.setTree(t)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.handleGetTree(w, r)
+}
+
+// handleSave saves the tree in the request body, which may be incomplete, rather than the current tree.
+func (s *server) handleSave(w http.ResponseWriter, r *http.Request) {
+	name, ok := s.fileName(w, r)
+	if !ok {
+		return
+	}
+	t, err := texture.ReadJSON(io.LimitReader(r.Body, 16<<20))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := texture.SaveJSON(t, name); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, "saved")
+}
+
+// fileName returns the path, without the .json extension, of the file named in the request. Names are
+// restricted to the server's directory.
+func (s *server) fileName(w http.ResponseWriter, r *http.Request) (string, bool) {
+	name := strings.TrimSuffix(r.URL.Query().Get("name"), ".json")
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		http.Error(w, "bad file name", http.StatusBadRequest)
+		return "", false
+	}
+	return filepath.Join(s.dir, name), true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := texture.WriteJSON(w, v); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+var colorType = reflect.TypeFor[color.Color]()
+
+// nodeTypes describes the node types that can be created and restored.
+func nodeTypes() []typeInfo {
+	// Find the types that survive a round trip through JSON
+	nodes := map[string]reflect.Type{}
+	for _, name := range texture.NodeNames() {
+		n, _ := texture.NewNode(name)
+		var b bytes.Buffer
+		if err := texture.WriteJSON(&b, n); err != nil {
+			continue
+		}
+		if _, err := texture.ReadJSON(&b); err != nil {
+			continue
+		}
+		nodes[name] = reflect.TypeOf(n)
+	}
+
+	// Node types that can be assigned to a field of type t
+	assignable := func(t reflect.Type) []string {
+		res := []string{}
+		for name, nt := range nodes {
+			if (t.Kind() == reflect.Interface && t != colorType && nt.Implements(t)) || nt == t {
+				res = append(res, name)
+			}
+		}
+		sort.Strings(res)
+		return res
+	}
+
+	res := []typeInfo{}
+	for name, nt := range nodes {
+		ti := typeInfo{name, []fieldInfo{}}
+		st := nt.Elem()
+		for i := range st.NumField() {
+			sf := st.Field(i)
+			if !sf.IsExported() || sf.Name == "Name" {
+				continue
+			}
+			fi := fieldInfo{sf.Name, "value", nil}
+			if types := assignable(sf.Type); len(types) > 0 {
+				fi.Kind, fi.Types = "node", types
+			} else if sf.Type.Kind() == reflect.Slice {
+				if types := assignable(sf.Type.Elem()); len(types) > 0 {
+					fi.Kind, fi.Types = "nodes", types
+				}
+			}
+			ti.Fields = append(ti.Fields, fi)
+		}
+		res = append(res, ti)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
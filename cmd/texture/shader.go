@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/jphsd/texture"
+	"os"
+)
+
+// shader prints a tree as a GLSL or WGSL function, and optionally checks it against the tree on stderr.
+func shader(args []string) error {
+	fs := flag.NewFlagSet("shader", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: texture shader [flags] tree.json|tree.tdsl\n")
+		fs.PrintDefaults()
+	}
+	r := regionFlags(fs, 256, 256)
+	wgsl := fs.Bool("wgsl", false, "print WGSL rather than GLSL")
+	check := fs.Bool("check", false, "report the largest difference from the tree over the region")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one tree")
+	}
+
+	tree, err := loadTree(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	lang := texture.GLSL
+	if *wgsl {
+		lang = texture.WGSL
+	}
+	s, err := texture.Shader(tree, lang)
+	if err != nil {
+		return err
+	}
+	fmt.Print(s)
+	if *check {
+		d, err := texture.CheckShader(tree, r.width, r.height, r.ox, r.oy, r.dx, r.dy)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "largest difference %.3g (%.1f/255)\n", d, d*255)
+	}
+	return nil
+}
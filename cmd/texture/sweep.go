@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	gi "github.com/jphsd/graphics2d/image"
+	"github.com/jphsd/texture"
+	"github.com/jphsd/texture/random"
+	"image"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// sweep renders a contact sheet of the variants of a tree as one or two of its parameters vary, or of
+// random trees for a set of seeds.
+func sweep(args []string) error {
+	fs := flag.NewFlagSet("sweep", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: texture sweep [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Parameters are given as path=values where path is the dot separated list of field names\n")
+		fmt.Fprintf(fs.Output(), "from the root of the tree, e.g. Src.WF.Lambdas[0], and values is either a comma separated\n")
+		fmt.Fprintf(fs.Output(), "list or start:end:n for n evenly spaced values.\n\n")
+		fs.PrintDefaults()
+	}
+	reg := regionFlags(fs, 200, 200)
+	tree := fs.String("tree", "", "JSON or DSL tree to sweep")
+	p := fs.String("p", "", "parameter for the rows, or the only parameter")
+	q := fs.String("q", "", "parameter for the columns")
+	seeds := fs.String("seeds", "", "random seeds as a comma separated list or first:last, instead of a tree")
+	depth := fs.Int("depth", 6, "maximum random tree depth")
+	cols := fs.Int("cols", 0, "columns for seed sweeps (default square)")
+	out := fs.String("o", "sweep", "output name")
+	fs.Parse(args)
+
+	var img *image.RGBA
+	if *seeds != "" {
+		ss, err := parseSeeds(*seeds)
+		if err != nil {
+			return err
+		}
+		nc := *cols
+		if nc < 1 {
+			nc = int(math.Ceil(math.Sqrt(float64(len(ss)))))
+		}
+		img = texture.SeedSweep(func(s int64) texture.ColorField {
+			seed(s)
+			return random.MakeColorField(*depth, 0)
+		}, ss, nc, reg.width, reg.height, reg.ox, reg.oy, reg.dx, reg.dy)
+	} else {
+		if *tree == "" || *p == "" {
+			fs.Usage()
+			return fmt.Errorf("a tree and parameter, or seeds, are required")
+		}
+		t, err := loadTree(*tree)
+		if err != nil {
+			return err
+		}
+		p1, err := parseSweep(*p)
+		if err != nil {
+			return err
+		}
+		var p2 *texture.SweepParam
+		if *q != "" {
+			if p2, err = parseSweep(*q); err != nil {
+				return err
+			}
+		}
+		if img, err = texture.Sweep(t, reg.width, reg.height, reg.ox, reg.oy, reg.dx, reg.dy, p1, p2); err != nil {
+			return err
+		}
+	}
+	return gi.SaveImage(img, *out)
+}
+
+// parseSweep parses path=a,b,c or path=start:end:n.
+func parseSweep(s string) (*texture.SweepParam, error) {
+	path, vals, ok := strings.Cut(s, "=")
+	if !ok {
+		return nil, fmt.Errorf("%q: expected path=values", s)
+	}
+	parts := strings.Split(vals, ":")
+	if len(parts) == 1 {
+		v, err := parseFloats(vals)
+		if err != nil {
+			return nil, err
+		}
+		return &texture.SweepParam{path, v}, nil
+	}
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%q: expected start:end:n", vals)
+	}
+	r, err := parseFloats(parts[0] + "," + parts[1])
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	return texture.NewSweepRange(path, r[0], r[1], n), nil
+}
+
+// parseSeeds parses a,b,c or first:last.
+func parseSeeds(s string) ([]int64, error) {
+	if first, last, ok := strings.Cut(s, ":"); ok {
+		f, err := strconv.ParseInt(first, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		l, err := strconv.ParseInt(last, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		res := []int64{}
+		for i := f; i <= l; i++ {
+			res = append(res, i)
+		}
+		return res, nil
+	}
+	res := []int64{}
+	for _, p := range strings.Split(s, ",") {
+		v, err := strconv.ParseInt(strings.TrimSpace(p), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
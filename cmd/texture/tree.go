@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	gi "github.com/jphsd/graphics2d/image"
+	"github.com/jphsd/texture"
+	"github.com/jphsd/texture/random"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// region holds the common image size and field sampling flags.
+type region struct {
+	width, height int
+	ox, oy        float64
+	dx, dy        float64
+}
+
+func regionFlags(fs *flag.FlagSet, width, height int) *region {
+	r := &region{}
+	fs.IntVar(&r.width, "w", width, "image width")
+	fs.IntVar(&r.height, "h", height, "image height")
+	fs.Float64Var(&r.ox, "ox", 0, "x origin")
+	fs.Float64Var(&r.oy, "oy", 0, "y origin")
+	fs.Float64Var(&r.dx, "dx", 1, "x step per pixel")
+	fs.Float64Var(&r.dy, "dy", 1, "y step per pixel")
+	return r
+}
+
+// save realizes the tree over the region into name.png.
+func (r *region) save(src any, name string) error {
+	cf, err := texture.ToColorField(src)
+	if err != nil {
+		return err
+	}
+	img := texture.NewTextureRGBA(r.width, r.height, cf, r.ox, r.oy, r.dx, r.dy, false)
+	return gi.SaveImage(img, name)
+}
+
+// seedFlag adds a seed flag - zero picks a seed from the time.
+func seedFlag(fs *flag.FlagSet) *int64 {
+	return fs.Int64("seed", 0, "random seed (0 for time based)")
+}
+
+func seed(s int64) int64 {
+	if s == 0 {
+		s = time.Now().UnixNano()
+	}
+	random.Rand = rand.New(rand.NewSource(s))
+	return s
+}
+
+// loadTree reads a tree from a DSL file if name ends in .tdsl, otherwise from a JSON file written by
+// texture.SaveJSON.
+func loadTree(name string) (any, error) {
+	if n, ok := strings.CutSuffix(name, ".tdsl"); ok {
+		return texture.LoadDSL(n)
+	}
+	return texture.LoadJSON(strings.TrimSuffix(name, ".json"))
+}
+
+// sourceTree loads the named tree or, if name is empty, makes a random color field.
+func sourceTree(name string) (any, error) {
+	if name == "" {
+		return random.MakeColorField(6, 0), nil
+	}
+	return loadTree(name)
+}
+
+// sampleImage sets the image used by the random package's image leaves.
+func sampleImage(name string) error {
+	if name == "" {
+		return nil
+	}
+	img, err := gi.ReadImage(name)
+	if err != nil {
+		return err
+	}
+	random.Sample = img
+	return nil
+}
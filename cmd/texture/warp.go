@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	gi "github.com/jphsd/graphics2d/image"
+	"github.com/jphsd/texture"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// warpFunc describes a warp function by its parameters, excluding the center, and their defaults.
+type warpFunc struct {
+	params   string
+	defaults []float64
+	make     func(c, p []float64) texture.WarpFunc
+}
+
+var warpFuncs = map[string]warpFunc{
+	"radial": {"rscale,cscale", []float64{1, 1}, func(c, p []float64) texture.WarpFunc {
+		return texture.NewRadialWF(c, p[0], p[1])
+	}},
+	"radialnl": {"k,effect (exponential)", []float64{3, 300}, func(c, p []float64) texture.WarpFunc {
+		return texture.NewRadialNLWF(c, texture.NewNLExponential(p[0]), p[1])
+	}},
+	"swirl": {"scale", []float64{-0.05}, func(c, p []float64) texture.WarpFunc {
+		return texture.NewSwirlWF(c, p[0])
+	}},
+	"drain": {"scale,effect", []float64{math.Pi, 250}, func(c, p []float64) texture.WarpFunc {
+		return texture.NewDrainWF(c, p[0], p[1])
+	}},
+	"pinchx": {"init,scale,alpha", []float64{0.3, 0.002, 2}, func(c, p []float64) texture.WarpFunc {
+		return texture.NewPinchXWF(c, p[0], p[1], p[2])
+	}},
+	"ripplex": {"lambda,amplitude,offset", []float64{100, 20, 12.5}, func(c, p []float64) texture.WarpFunc {
+		return texture.NewRippleXWF(p[0], p[1], p[2])
+	}},
+	"radialripple": {"lambda,amplitude,offset", []float64{100, 10, 0}, func(c, p []float64) texture.WarpFunc {
+		return texture.NewRadialRippleWF(c, p[0], p[1], p[2])
+	}},
+	"radialwiggle": {"lambda,amplitude,offset", []float64{100, 0.1, 0}, func(c, p []float64) texture.WarpFunc {
+		return texture.NewRadialWiggleWF(c, p[0], p[1], p[2])
+	}},
+}
+
+// warp applies a warp function to a tree, by default a grid of squares.
+func warp(args []string) error {
+	fs := flag.NewFlagSet("warp", flag.ExitOnError)
+	reg := regionFlags(fs, 1000, 1000)
+	names := make([]string, 0, len(warpFuncs))
+	for k := range warpFuncs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: texture warp [flags]\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nWarp functions and their parameters:\n")
+		for _, k := range names {
+			wf := warpFuncs[k]
+			fmt.Fprintf(fs.Output(), "  %-13s %s (default %v)\n", k, wf.params, wf.defaults)
+		}
+	}
+	typ := fs.String("type", "radialripple", "warp function, one of "+strings.Join(names, ", "))
+	params := fs.String("p", "", "comma separated warp function parameters")
+	tree := fs.String("tree", "", "JSON or DSL tree to warp (default squares of side 25)")
+	cx := fs.Float64("cx", math.NaN(), "x of the warp center (default image center)")
+	cy := fs.Float64("cy", math.NaN(), "y of the warp center (default image center)")
+	out := fs.String("o", "warp", "output name")
+	fs.Parse(args)
+
+	wf, ok := warpFuncs[*typ]
+	if !ok {
+		return fmt.Errorf("unknown warp function %q", *typ)
+	}
+	p, err := parseFloats(*params)
+	if err != nil {
+		return err
+	}
+	if len(p) > len(wf.defaults) {
+		return fmt.Errorf("%s takes %d parameters (%s)", *typ, len(wf.defaults), wf.params)
+	}
+	p = append(p, wf.defaults[len(p):]...)
+	c := []float64{*cx, *cy}
+	if math.IsNaN(c[0]) {
+		c[0] = reg.ox + float64(reg.width)*reg.dx/2
+	}
+	if math.IsNaN(c[1]) {
+		c[1] = reg.oy + float64(reg.height)*reg.dy/2
+	}
+	f := wf.make(c, p)
+
+	var src any = texture.NewSquares(25)
+	if *tree != "" {
+		if src, err = loadTree(*tree); err != nil {
+			return err
+		}
+	}
+	switch s := src.(type) {
+	case texture.Field:
+		img := texture.NewTextureGray16(reg.width, reg.height, texture.NewWarp(s, f), reg.ox, reg.oy, reg.dx, reg.dy, false)
+		return gi.SaveImage(img, *out)
+	case texture.VectorField:
+		return reg.save(texture.NewWarpVF(s, f), *out)
+	case texture.ColorField:
+		return reg.save(texture.NewWarpCF(s, f), *out)
+	}
+	return fmt.Errorf("%T is not a field, vector field or color field", src)
+}
+
+func parseFloats(s string) ([]float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	res := make([]float64, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = v
+	}
+	return res, nil
+}
@@ -106,30 +106,28 @@ func (c *ColorConv) Eval2(x, y float64) color.Color {
 	nt := (t - c.TVals[i-1]) / (c.TVals[i] - c.TVals[i-1])
 	c1, c2 := c.Colors[i-1], c.Colors[i]
 
-	switch c.Lerp {
-	default:
-		fallthrough
-	case LerpRGBA:
-		return g2dcol.ColorRGBALerp(nt, c1, c2)
-	case LerpHSL:
-		return g2dcol.ColorHSLLerp(nt, c1, c2)
-	case LerpHSLs:
-		return g2dcol.ColorHSLLerpS(nt, c1, c2)
-	}
+	return lerpColor(c.Lerp, nt, c1, c2)
 }
 
-// ColorFields uses the four field sources to form {R, G, B, A} or {H, S, L, A}.
+// ColorFields uses the four field sources to form {R, G, B, A} or {H, S, L, A}. If HSL is false, then
+// Space determines the color space the first three sources are mapped to (see [tcol.Space.Color]).
 type ColorFields struct {
-	Name string
-	Src1 Field
-	Src2 Field
-	Src3 Field
-	Src4 Field
-	HSL  bool
+	Name  string
+	Src1  Field
+	Src2  Field
+	Src3  Field
+	Src4  Field
+	HSL   bool
+	Space tcol.Space
 }
 
 func NewColorFields(src1, src2, src3, src4 Field, hsl bool) *ColorFields {
-	return &ColorFields{"ColorFields", src1, src2, src3, src4, hsl}
+	return &ColorFields{"ColorFields", src1, src2, src3, src4, hsl, tcol.RGBSpace}
+}
+
+// NewColorFieldsSpace returns a new ColorFields with the sources mapped to the color space.
+func NewColorFieldsSpace(src1, src2, src3, src4 Field, space tcol.Space) *ColorFields {
+	return &ColorFields{"ColorFields", src1, src2, src3, src4, false, space}
 }
 
 // Eval2 implements the ColorField interface.
@@ -142,6 +140,9 @@ func (c *ColorFields) Eval2(x, y float64) color.Color {
 	if c.HSL {
 		return g2dcol.HSL{v1, v2, v3, a}
 	}
+	if c.Space != tcol.RGBSpace {
+		return c.Space.Color(v1, v2, v3, a)
+	}
 	return color.NRGBA{uint8(v1 * 0xff), uint8(v2 * 0xff), uint8(v3 * 0xff), uint8(a * 0xff)}
 }
 
@@ -171,13 +172,46 @@ func (c *ColorVector) Eval2(x, y float64) color.Color {
 // LerpType defines the type of lerp -
 type LerpType int
 
-// Constants for lerp types.
+// Constants for lerp types. LerpRGBA interpolates in gamma encoded sRGB, and LerpHSL without taking the
+// shortest hue path. The remaining types interpolate in the named color space (see [tcol.Lerp]).
 const (
 	LerpRGBA LerpType = iota
 	LerpHSL
 	LerpHSLs
+	LerpLinearRGB
+	LerpHSV
+	LerpLab
+	LerpLCh
+	LerpOKLab
+	LerpOKLCh
 )
 
+// lerpColor interpolates between c1 and c2 using the lerp type.
+func lerpColor(lerp LerpType, t float64, c1, c2 color.Color) color.Color {
+	switch lerp {
+	default:
+		fallthrough
+	case LerpRGBA:
+		return g2dcol.ColorRGBALerp(t, c1, c2)
+	case LerpHSL:
+		return g2dcol.ColorHSLLerp(t, c1, c2)
+	case LerpHSLs:
+		return g2dcol.ColorHSLLerpS(t, c1, c2)
+	case LerpLinearRGB:
+		return tcol.Lerp(t, c1, c2, tcol.LinearRGBSpace)
+	case LerpHSV:
+		return tcol.Lerp(t, c1, c2, tcol.HSVSpace)
+	case LerpLab:
+		return tcol.Lerp(t, c1, c2, tcol.LabSpace)
+	case LerpLCh:
+		return tcol.Lerp(t, c1, c2, tcol.LChSpace)
+	case LerpOKLab:
+		return tcol.Lerp(t, c1, c2, tcol.OKLabSpace)
+	case LerpOKLCh:
+		return tcol.Lerp(t, c1, c2, tcol.OKLChSpace)
+	}
+}
+
 // ColorBlend takes colors from the two color field sources and blends them based on the value
 // from the third source using the specified color lerp.
 type ColorBlend struct {
@@ -194,16 +228,65 @@ func NewColorBlend(src1, src2 ColorField, src3 Field, lerp LerpType) *ColorBlend
 
 // Eval2 implements the ColorField interface.
 func (c *ColorBlend) Eval2(x, y float64) color.Color {
-	switch c.Lerp {
-	default:
-		fallthrough
-	case LerpRGBA:
-		return g2dcol.ColorRGBALerp((c.Src3.Eval2(x, y)+1)/2, c.Src1.Eval2(x, y), c.Src2.Eval2(x, y))
-	case LerpHSL:
-		return g2dcol.ColorHSLLerp((c.Src3.Eval2(x, y)+1)/2, c.Src1.Eval2(x, y), c.Src2.Eval2(x, y))
-	case LerpHSLs:
-		return g2dcol.ColorHSLLerpS((c.Src3.Eval2(x, y)+1)/2, c.Src1.Eval2(x, y), c.Src2.Eval2(x, y))
-	}
+	return lerpColor(c.Lerp, (c.Src3.Eval2(x, y)+1)/2, c.Src1.Eval2(x, y), c.Src2.Eval2(x, y))
+}
+
+// ColorModeBlend composites the colors from Src2 over those from Src1 using a blend mode, such as multiply
+// or screen, with the opacity of Src2 scaled by the value from the third source mapped to [0,1].
+// The alpha of both sources is respected (see [tcol.Blend]).
+type ColorModeBlend struct {
+	Name string
+	Src1 ColorField
+	Src2 ColorField
+	Src3 Field
+	Mode tcol.BlendMode
+}
+
+func NewColorModeBlend(src1, src2 ColorField, src3 Field, mode tcol.BlendMode) *ColorModeBlend {
+	return &ColorModeBlend{"ColorModeBlend", src1, src2, src3, mode}
+}
+
+// Eval2 implements the ColorField interface.
+func (c *ColorModeBlend) Eval2(x, y float64) color.Color {
+	c1, c2 := tcol.NewFRGBA(c.Src1.Eval2(x, y)), tcol.NewFRGBA(c.Src2.Eval2(x, y))
+	return tcol.Blend(c.Mode, c1, c2, (c.Src3.Eval2(x, y)+1)/2)
+}
+
+// ColorComposite combines the colors from Src1 (source) and Src2 (destination) using a Porter-Duff
+// operator (see [tcol.Composite]).
+type ColorComposite struct {
+	Name string
+	Src1 ColorField
+	Src2 ColorField
+	Op   tcol.CompositeOp
+}
+
+func NewColorComposite(src1, src2 ColorField, op tcol.CompositeOp) *ColorComposite {
+	return &ColorComposite{"ColorComposite", src1, src2, op}
+}
+
+// Eval2 implements the ColorField interface.
+func (c *ColorComposite) Eval2(x, y float64) color.Color {
+	return tcol.Composite(c.Op, tcol.NewFRGBA(c.Src1.Eval2(x, y)), tcol.NewFRGBA(c.Src2.Eval2(x, y)))
+}
+
+// ColorAlpha scales the alpha of the color source by the value from the field source mapped to [0,1].
+// Combined with a [Shape] or thresholded field, it cuts out a color layer.
+type ColorAlpha struct {
+	Name string
+	Src1 ColorField
+	Src2 Field
+}
+
+func NewColorAlpha(src1 ColorField, src2 Field) *ColorAlpha {
+	return &ColorAlpha{"ColorAlpha", src1, src2}
+}
+
+// Eval2 implements the ColorField interface.
+func (c *ColorAlpha) Eval2(x, y float64) color.Color {
+	col := tcol.NewFRGBA(c.Src1.Eval2(x, y))
+	col.A *= bcclamp((c.Src2.Eval2(x, y) + 1) / 2)
+	return col
 }
 
 // ColorSubstitute returns a color from either Src1 or Src2 depending on if the value
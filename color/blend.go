@@ -0,0 +1,177 @@
+package color
+
+import "math"
+
+// BlendMode defines how a source color is combined with a backdrop color.
+type BlendMode int
+
+// Constants for blend modes. The separable modes operate on each channel independently, the
+// non-separable ones (Hue, Saturation, Color and Luminosity) on the color as a whole.
+const (
+	NormalBlend BlendMode = iota
+	MultiplyBlend
+	ScreenBlend
+	OverlayBlend
+	SoftLightBlend
+	HardLightBlend
+	ColorDodgeBlend
+	ColorBurnBlend
+	LinearLightBlend
+	DifferenceBlend
+	ExclusionBlend
+	HueBlend
+	SaturationBlend
+	ColorBlend
+	LuminosityBlend
+)
+
+// Blend composites the source color over the backdrop using the blend mode and opacity [0,1].
+// The mode is applied where both colors are present and the result is composited source-over,
+// so the alpha of both colors is respected. See [W3C Compositing].
+//
+// [W3C Compositing]: https://www.w3.org/TR/compositing-1/
+func Blend(mode BlendMode, backdrop, source FRGBA, opacity float64) FRGBA {
+	ab, as := backdrop.A, source.A*clamp01(opacity)
+	ao := as + ab*(1-as)
+	if ao < 0.000001 {
+		return FRGBA{}
+	}
+
+	// Mix the source with the blended color by the backdrop's coverage
+	r, g, b := BlendColor(mode, backdrop, source)
+	r = (1-ab)*source.R + ab*r
+	g = (1-ab)*source.G + ab*g
+	b = (1-ab)*source.B + ab*b
+
+	// Source-over
+	r = (as*r + (1-as)*ab*backdrop.R) / ao
+	g = (as*g + (1-as)*ab*backdrop.G) / ao
+	b = (as*b + (1-as)*ab*backdrop.B) / ao
+	return FRGBA{clamp01(r), clamp01(g), clamp01(b), ao}
+}
+
+// BlendColor returns the result of applying the blend mode to the backdrop and source colors,
+// ignoring alpha.
+func BlendColor(mode BlendMode, backdrop, source FRGBA) (float64, float64, float64) {
+	cb := []float64{backdrop.R, backdrop.G, backdrop.B}
+	cs := []float64{source.R, source.G, source.B}
+	var res []float64
+	switch mode {
+	case HueBlend:
+		res = setLum(setSat(cs, sat(cb)), lum(cb))
+	case SaturationBlend:
+		res = setLum(setSat(cb, sat(cs)), lum(cb))
+	case ColorBlend:
+		res = setLum(cs, lum(cb))
+	case LuminosityBlend:
+		res = setLum(cb, lum(cs))
+	default:
+		res = []float64{blendChannel(mode, cb[0], cs[0]), blendChannel(mode, cb[1], cs[1]), blendChannel(mode, cb[2], cs[2])}
+	}
+	return res[0], res[1], res[2]
+}
+
+// blendChannel applies a separable blend mode to a single channel.
+func blendChannel(mode BlendMode, cb, cs float64) float64 {
+	switch mode {
+	default:
+		fallthrough
+	case NormalBlend:
+		return cs
+	case MultiplyBlend:
+		return cb * cs
+	case ScreenBlend:
+		return cb + cs - cb*cs
+	case OverlayBlend:
+		return blendChannel(HardLightBlend, cs, cb)
+	case SoftLightBlend:
+		if cs <= 0.5 {
+			return cb - (1-2*cs)*cb*(1-cb)
+		}
+		var d float64
+		if cb <= 0.25 {
+			d = ((16*cb-12)*cb + 4) * cb
+		} else {
+			d = math.Sqrt(cb)
+		}
+		return cb + (2*cs-1)*(d-cb)
+	case HardLightBlend:
+		if cs <= 0.5 {
+			return cb * 2 * cs
+		}
+		return blendChannel(ScreenBlend, cb, 2*cs-1)
+	case ColorDodgeBlend:
+		if cb == 0 {
+			return 0
+		}
+		if cs >= 1 {
+			return 1
+		}
+		return math.Min(1, cb/(1-cs))
+	case ColorBurnBlend:
+		if cb >= 1 {
+			return 1
+		}
+		if cs <= 0 {
+			return 0
+		}
+		return 1 - math.Min(1, (1-cb)/cs)
+	case LinearLightBlend:
+		return clamp01(cb + 2*cs - 1)
+	case DifferenceBlend:
+		return math.Abs(cb - cs)
+	case ExclusionBlend:
+		return cb + cs - 2*cb*cs
+	}
+}
+
+// Helpers for the non-separable blend modes.
+
+func lum(c []float64) float64 {
+	return 0.3*c[0] + 0.59*c[1] + 0.11*c[2]
+}
+
+func clipColor(c []float64) []float64 {
+	l := lum(c)
+	n := math.Min(c[0], math.Min(c[1], c[2]))
+	x := math.Max(c[0], math.Max(c[1], c[2]))
+	res := []float64{c[0], c[1], c[2]}
+	for i := range res {
+		if n < 0 {
+			res[i] = l + (res[i]-l)*l/(l-n)
+		}
+		if x > 1 {
+			res[i] = l + (res[i]-l)*(1-l)/(x-l)
+		}
+	}
+	return res
+}
+
+func setLum(c []float64, l float64) []float64 {
+	d := l - lum(c)
+	return clipColor([]float64{c[0] + d, c[1] + d, c[2] + d})
+}
+
+func sat(c []float64) float64 {
+	return math.Max(c[0], math.Max(c[1], c[2])) - math.Min(c[0], math.Min(c[1], c[2]))
+}
+
+func setSat(c []float64, s float64) []float64 {
+	// Find indices of the max, mid and min channels
+	imax, imid, imin := 0, 1, 2
+	if c[imax] < c[imid] {
+		imax, imid = imid, imax
+	}
+	if c[imid] < c[imin] {
+		imid, imin = imin, imid
+	}
+	if c[imax] < c[imid] {
+		imax, imid = imid, imax
+	}
+	res := []float64{0, 0, 0}
+	if c[imax] > c[imin] {
+		res[imid] = (c[imid] - c[imin]) * s / (c[imax] - c[imin])
+		res[imax] = s
+	}
+	return res
+}
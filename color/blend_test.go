@@ -0,0 +1,81 @@
+package color_test
+
+import (
+	tcol "github.com/jphsd/texture/color"
+	"testing"
+)
+
+func TestBlendColor(t *testing.T) {
+	// Expected values worked from the W3C formulas
+	cb, cs := tcol.FRGBA{0.2, 0.6, 0.9, 1}, tcol.FRGBA{0.7, 0.3, 0.5, 1}
+	edgeb, edges := tcol.FRGBA{0, 1, 0.5, 1}, tcol.FRGBA{1, 0, 0.5, 1}
+	red, white := tcol.FRGBA{1, 0, 0, 1}, tcol.FRGBA{1, 1, 1, 1}
+	tests := []struct {
+		name   string
+		mode   tcol.BlendMode
+		cb, cs tcol.FRGBA
+		want   []float64
+	}{
+		{"Normal", tcol.NormalBlend, cb, cs, []float64{0.7, 0.3, 0.5}},
+		{"Multiply", tcol.MultiplyBlend, cb, cs, []float64{0.14, 0.18, 0.45}},
+		{"Screen", tcol.ScreenBlend, cb, cs, []float64{0.76, 0.72, 0.95}},
+		{"Overlay", tcol.OverlayBlend, cb, cs, []float64{0.28, 0.44, 0.9}},
+		{"SoftLight", tcol.SoftLightBlend, cb, cs, []float64{0.2992, 0.504, 0.9}},
+		{"HardLight", tcol.HardLightBlend, cb, cs, []float64{0.52, 0.36, 0.9}},
+		{"ColorDodge", tcol.ColorDodgeBlend, cb, cs, []float64{2.0 / 3, 6.0 / 7, 1}},
+		{"ColorDodge edges", tcol.ColorDodgeBlend, edgeb, edges, []float64{0, 1, 1}},
+		{"ColorBurn", tcol.ColorBurnBlend, cb, cs, []float64{0, 0, 0.8}},
+		{"ColorBurn edges", tcol.ColorBurnBlend, edgeb, edges, []float64{0, 1, 0}},
+		{"LinearLight", tcol.LinearLightBlend, cb, cs, []float64{0.6, 0.2, 0.9}},
+		{"Difference", tcol.DifferenceBlend, cb, cs, []float64{0.5, 0.3, 0.4}},
+		{"Exclusion", tcol.ExclusionBlend, cb, cs, []float64{0.62, 0.54, 0.5}},
+		{"Hue", tcol.HueBlend, cb, cs, []float64{0.9645, 0.2645, 0.6145}},
+		{"Saturation", tcol.SaturationBlend, cb, cs, []float64{0.334143, 0.562714, 0.734143}},
+		{"Color", tcol.ColorBlend, cb, cs, []float64{0.771, 0.371, 0.571}},
+		{"Luminosity", tcol.LuminosityBlend, cb, cs, []float64{0.129, 0.529, 0.829}},
+		{"Luminosity clipped", tcol.LuminosityBlend, red, white, []float64{1, 1, 1}},
+	}
+	for _, test := range tests {
+		r, g, b := tcol.BlendColor(test.mode, test.cb, test.cs)
+		if got := []float64{r, g, b}; !near(got, test.want, 1e-6) {
+			t.Errorf("%s: %v, expected %v", test.name, got, test.want)
+		}
+
+		// Opaque colors at full opacity give the blended color and at zero the backdrop
+		if c := tcol.Blend(test.mode, test.cb, test.cs, 1); !near([]float64{c.R, c.G, c.B, c.A},
+			append(test.want, 1), 1e-6) {
+			t.Errorf("%s: opacity 1 gives %v", test.name, c)
+		}
+		if c := tcol.Blend(test.mode, test.cb, test.cs, 0); c != test.cb {
+			t.Errorf("%s: opacity 0 gives %v, expected the backdrop", test.name, c)
+		}
+		want := []float64{(test.want[0] + test.cb.R) / 2, (test.want[1] + test.cb.G) / 2,
+			(test.want[2] + test.cb.B) / 2, 1}
+		if c := tcol.Blend(test.mode, test.cb, test.cs, 0.5); !near([]float64{c.R, c.G, c.B, c.A}, want, 1e-6) {
+			t.Errorf("%s: opacity 0.5 gives %v, expected %v", test.name, c, want)
+		}
+	}
+}
+
+func TestBlendAlpha(t *testing.T) {
+	cs := tcol.FRGBA{0.7, 0.3, 0.5, 1}
+
+	// Over a transparent backdrop the source is unchanged, whatever the mode
+	for mode := tcol.NormalBlend; mode <= tcol.LuminosityBlend; mode++ {
+		if c := tcol.Blend(mode, tcol.FRGBA{}, cs, 0.5); !near([]float64{c.R, c.G, c.B, c.A},
+			[]float64{0.7, 0.3, 0.5, 0.5}, 1e-9) {
+			t.Errorf("mode %d over transparent gives %v", mode, c)
+		}
+	}
+
+	// A half transparent backdrop shows half the blended color and half the source
+	c := tcol.Blend(tcol.MultiplyBlend, tcol.FRGBA{0.2, 0.6, 0.9, 0.5}, cs, 1)
+	if want := []float64{0.42, 0.24, 0.475, 1}; !near([]float64{c.R, c.G, c.B, c.A}, want, 1e-9) {
+		t.Errorf("multiply over half transparent gives %v, expected %v", c, want)
+	}
+
+	// Neither present
+	if c := tcol.Blend(tcol.ScreenBlend, tcol.FRGBA{}, tcol.FRGBA{}, 1); c != (tcol.FRGBA{}) {
+		t.Errorf("transparent colors give %v", c)
+	}
+}
@@ -0,0 +1,63 @@
+package color
+
+// CompositeOp defines a Porter-Duff compositing operator. See [Porter-Duff84].
+//
+// [Porter-Duff84]: https://dl.acm.org/doi/10.1145/964965.808606
+type CompositeOp int
+
+// Constants for compositing operators - source is composited with destination.
+const (
+	OverOp CompositeOp = iota
+	InOp
+	OutOp
+	AtopOp
+	XorOp
+)
+
+// Premul returns the color with R, G and B multiplied by A. The result is not a valid FRGBA color
+// and should only be used for arithmetic prior to calling [FRGBA.Unpremul].
+func (c FRGBA) Premul() FRGBA {
+	return FRGBA{c.R * c.A, c.G * c.A, c.B * c.A, c.A}
+}
+
+// Unpremul returns the color with R, G and B divided by A, reversing [FRGBA.Premul].
+func (c FRGBA) Unpremul() FRGBA {
+	if c.A < 0.000001 {
+		return FRGBA{}
+	}
+	return FRGBA{clamp01(c.R / c.A), clamp01(c.G / c.A), clamp01(c.B / c.A), clamp01(c.A)}
+}
+
+// LerpPremul calculates the color value at t [0,1] given a start and end color, interpolating
+// premultiplied values so that transparent colors don't contribute to the result.
+func LerpPremul(t float64, start, end FRGBA) FRGBA {
+	ps, pe := start.Premul(), end.Premul()
+	return FRGBA{lerp(t, ps.R, pe.R), lerp(t, ps.G, pe.G), lerp(t, ps.B, pe.B), lerp(t, ps.A, pe.A)}.Unpremul()
+}
+
+// Composite combines the source and destination colors using the Porter-Duff operator.
+func Composite(op CompositeOp, src, dst FRGBA) FRGBA {
+	// Fractions of the source and destination contributing to the result
+	as, ad := src.A, dst.A
+	var fs, fd float64
+	switch op {
+	default:
+		fallthrough
+	case OverOp:
+		fs, fd = 1, 1-as
+	case InOp:
+		fs, fd = ad, 0
+	case OutOp:
+		fs, fd = 1-ad, 0
+	case AtopOp:
+		fs, fd = ad, 1-as
+	case XorOp:
+		fs, fd = 1-ad, 1-as
+	}
+	ps, pd := src.Premul(), dst.Premul()
+	return FRGBA{
+		fs*ps.R + fd*pd.R,
+		fs*ps.G + fd*pd.G,
+		fs*ps.B + fd*pd.B,
+		fs*as + fd*ad}.Unpremul()
+}
@@ -6,5 +6,18 @@ A new color type and model that represents colors as float64 tuples [0,1], along
   - [Add]
   - [Prod]
   - [Scale]
+
+Additional float color types, each implementing [image/color.Color] and with a matching model:
+
+  - [LinearRGBA] linear light sRGB
+  - [HSV] and [HSL]
+  - [Lab] and [LCh] CIELAB (D65)
+  - [OKLab] and [OKLCh]
+
+[Lerp] interpolates between two colors in any of these spaces, selected with [Space].
+
+[Blend] composites one color over another using a [BlendMode], such as multiply, screen, overlay or
+luminosity, with an opacity. [Composite] combines two colors with a Porter-Duff [CompositeOp], and
+[FRGBA.Premul], [FRGBA.Unpremul] and [LerpPremul] support premultiplied alpha arithmetic.
 */
 package color
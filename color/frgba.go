@@ -23,11 +23,13 @@ func NewFRGBA(col color.Color) FRGBA {
 	return FRGBA{fr / fa, fg / fa, fb / fa, fa}
 }
 
-// RGBA implements the RGBA function from the Color interface.
+// RGBA implements the RGBA function from the Color interface. Out of range values, such as from
+// high dynamic range calculations, are clamped to [0,1].
 func (c FRGBA) RGBA() (uint32, uint32, uint32, uint32) {
 	// r, g, b premultiplied
-	r, g, b, a := c.R*c.A*0xffff, c.G*c.A*0xffff, c.B*c.A*0xffff, c.A*0xffff
-	return uint32(r), uint32(g), uint32(b), uint32(a)
+	a := clamp01(c.A)
+	r, g, b := clamp01(c.R)*a*0xffff, clamp01(c.G)*a*0xffff, clamp01(c.B)*a*0xffff
+	return uint32(r), uint32(g), uint32(b), uint32(a * 0xffff)
 }
 
 // FRGBAModel for conversion of a color to FRGBA.
@@ -62,6 +64,11 @@ func (c FRGBA) Add(c1 FRGBA) FRGBA {
 	return FRGBA{r, g, b, c.A}
 }
 
+// Sum returns the addition of c1 to the color without clamping, for high dynamic range calculations.
+func (c FRGBA) Sum(c1 FRGBA) FRGBA {
+	return FRGBA{c.R + c1.R, c.G + c1.G, c.B + c1.B, c.A}
+}
+
 // Prod returns the product of c1 with the color.
 func (c FRGBA) Prod(c1 FRGBA) FRGBA {
 	return FRGBA{c.R * c1.R, c.G * c1.G, c.B * c1.B, c.A}
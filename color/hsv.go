@@ -0,0 +1,85 @@
+package color
+
+import (
+	g2dcol "github.com/jphsd/graphics2d/color"
+	"image/color"
+	"math"
+)
+
+// HSL describes a color in Hue Saturation Lightness space. All values are in range [0,1].
+type HSL = g2dcol.HSL
+
+// NewHSL returns the color as an HSL tuple.
+func NewHSL(col color.Color) HSL {
+	return g2dcol.NewHSL(col)
+}
+
+// HSV describes a color in Hue Saturation Value space. All values are in range [0,1].
+type HSV struct {
+	H, S, V, A float64
+}
+
+// NewHSV returns the color as an HSV tuple.
+func NewHSV(col color.Color) HSV {
+	if hsv, ok := col.(HSV); ok {
+		return hsv
+	}
+	c := NewFRGBA(col)
+	max := math.Max(math.Max(c.R, c.G), c.B)
+	min := math.Min(math.Min(c.R, c.G), c.B)
+	d := max - min
+
+	var h, s float64
+	if max > 0 {
+		s = d / max
+	}
+	if d > 0 {
+		switch max {
+		case c.R:
+			h = (c.G - c.B) / d
+		case c.G:
+			h = 2 + (c.B-c.R)/d
+		default:
+			h = 4 + (c.R-c.G)/d
+		}
+		h /= 6
+		if h < 0 {
+			h += 1
+		}
+	}
+	return HSV{h, s, max, c.A}
+}
+
+// RGBA implements the RGBA function from the Color interface.
+func (c HSV) RGBA() (uint32, uint32, uint32, uint32) {
+	h := c.H - math.Floor(c.H)
+	s, v := clamp01(c.S), clamp01(c.V)
+	h *= 6
+	i := math.Floor(h)
+	f := h - i
+	p, q, t := v*(1-s), v*(1-s*f), v*(1-s*(1-f))
+	var r, g, b float64
+	switch int(i) % 6 {
+	case 0:
+		r, g, b = v, t, p
+	case 1:
+		r, g, b = q, v, p
+	case 2:
+		r, g, b = p, v, t
+	case 3:
+		r, g, b = p, q, v
+	case 4:
+		r, g, b = t, p, v
+	default:
+		r, g, b = v, p, q
+	}
+	a := clamp01(c.A)
+	return uint32(r * a * 0xffff), uint32(g * a * 0xffff), uint32(b * a * 0xffff), uint32(a * 0xffff)
+}
+
+// HSVModel for conversion of a color to HSV.
+var HSVModel color.Model = color.ModelFunc(hsvModel)
+
+func hsvModel(col color.Color) color.Color {
+	return NewHSV(col)
+}
@@ -0,0 +1,118 @@
+package color
+
+import (
+	"image/color"
+	"math"
+)
+
+// CIE XYZ D65 reference white.
+const (
+	whiteX = 0.95047
+	whiteY = 1.0
+	whiteZ = 1.08883
+)
+
+// Lab describes a color in CIELAB space using the D65 white point. L is in [0,100], A and B are
+// typically in [-128,127].
+type Lab struct {
+	L, A, B, Alpha float64
+}
+
+// NewLab returns the color as a CIELAB tuple.
+func NewLab(col color.Color) Lab {
+	if lab, ok := col.(Lab); ok {
+		return lab
+	}
+	c := NewLinearRGBA(col)
+	x, y, z := linearToXYZ(c.R, c.G, c.B)
+	fx, fy, fz := labF(x/whiteX), labF(y/whiteY), labF(z/whiteZ)
+	return Lab{116*fy - 16, 500 * (fx - fy), 200 * (fy - fz), c.A}
+}
+
+// RGBA implements the RGBA function from the Color interface.
+func (c Lab) RGBA() (uint32, uint32, uint32, uint32) {
+	fy := (c.L + 16) / 116
+	fx := fy + c.A/500
+	fz := fy - c.B/200
+	r, g, b := xyzToLinear(labFInv(fx)*whiteX, labFInv(fy)*whiteY, labFInv(fz)*whiteZ)
+	return linearRGBA(r, g, b, c.Alpha)
+}
+
+// LabModel for conversion of a color to Lab.
+var LabModel color.Model = color.ModelFunc(labModel)
+
+func labModel(col color.Color) color.Color {
+	return NewLab(col)
+}
+
+// LCh describes a color in the polar form of CIELAB. L is in [0,100], C is typically in [0,150] and H is
+// the hue angle mapped to [0,1].
+type LCh struct {
+	L, C, H, Alpha float64
+}
+
+// NewLCh returns the color as a CIELAB LCh tuple.
+func NewLCh(col color.Color) LCh {
+	if lch, ok := col.(LCh); ok {
+		return lch
+	}
+	lab := NewLab(col)
+	c, h := toPolar(lab.A, lab.B)
+	return LCh{lab.L, c, h, lab.Alpha}
+}
+
+// RGBA implements the RGBA function from the Color interface.
+func (c LCh) RGBA() (uint32, uint32, uint32, uint32) {
+	a, b := fromPolar(c.C, c.H)
+	return Lab{c.L, a, b, c.Alpha}.RGBA()
+}
+
+// LChModel for conversion of a color to LCh.
+var LChModel color.Model = color.ModelFunc(lchModel)
+
+func lchModel(col color.Color) color.Color {
+	return NewLCh(col)
+}
+
+func linearToXYZ(r, g, b float64) (float64, float64, float64) {
+	return 0.4124564*r + 0.3575761*g + 0.1804375*b,
+		0.2126729*r + 0.7151522*g + 0.0721750*b,
+		0.0193339*r + 0.1191920*g + 0.9503041*b
+}
+
+func xyzToLinear(x, y, z float64) (float64, float64, float64) {
+	return 3.2404542*x - 1.5371385*y - 0.4985314*z,
+		-0.9692660*x + 1.8760108*y + 0.0415560*z,
+		0.0556434*x - 0.2040259*y + 1.0572252*z
+}
+
+const labDelta = 6.0 / 29
+
+func labF(t float64) float64 {
+	if t > labDelta*labDelta*labDelta {
+		return math.Cbrt(t)
+	}
+	return t/(3*labDelta*labDelta) + 4.0/29
+}
+
+func labFInv(t float64) float64 {
+	if t > labDelta {
+		return t * t * t
+	}
+	return 3 * labDelta * labDelta * (t - 4.0/29)
+}
+
+// toPolar converts a, b to chroma and hue [0,1].
+func toPolar(a, b float64) (float64, float64) {
+	h := math.Atan2(b, a) / (2 * math.Pi)
+	if h < 0 {
+		h += 1
+	}
+	return math.Hypot(a, b), h
+}
+
+// fromPolar converts chroma and hue [0,1] to a, b.
+func fromPolar(c, h float64) (float64, float64) {
+	th := h * 2 * math.Pi
+	return c * math.Cos(th), c * math.Sin(th)
+}
@@ -0,0 +1,64 @@
+package color
+
+import (
+	"image/color"
+	"math"
+)
+
+// LinearRGBA represents a non-premultiplied RGBA tuple in linear light sRGB, with values typically in [0,1].
+type LinearRGBA struct {
+	R, G, B, A float64
+}
+
+// NewLinearRGBA returns the color in linear sRGB.
+func NewLinearRGBA(col color.Color) LinearRGBA {
+	if lrgba, ok := col.(LinearRGBA); ok {
+		return lrgba
+	}
+	c := NewFRGBA(col)
+	return LinearRGBA{SRGBToLinear(c.R), SRGBToLinear(c.G), SRGBToLinear(c.B), c.A}
+}
+
+// RGBA implements the RGBA function from the Color interface.
+func (c LinearRGBA) RGBA() (uint32, uint32, uint32, uint32) {
+	return linearRGBA(c.R, c.G, c.B, c.A)
+}
+
+// LinearRGBAModel for conversion of a color to LinearRGBA.
+var LinearRGBAModel color.Model = color.ModelFunc(linearRGBAModel)
+
+func linearRGBAModel(col color.Color) color.Color {
+	return NewLinearRGBA(col)
+}
+
+// SRGBToLinear converts a gamma encoded sRGB value [0,1] to linear light.
+func SRGBToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// LinearToSRGB converts a linear light value [0,1] to gamma encoded sRGB.
+func LinearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// linearRGBA converts linear values to premultiplied sRGB values in [0,0xffff], clamping out of gamut values.
+func linearRGBA(r, g, b, a float64) (uint32, uint32, uint32, uint32) {
+	r, g, b, a = clamp01(LinearToSRGB(clamp01(r))), clamp01(LinearToSRGB(clamp01(g))), clamp01(LinearToSRGB(clamp01(b))), clamp01(a)
+	return uint32(r * a * 0xffff), uint32(g * a * 0xffff), uint32(b * a * 0xffff), uint32(a * 0xffff)
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,77 @@
+package color
+
+import (
+	"image/color"
+	"math"
+)
+
+// OKLab describes a color in the OKLab perceptual color space. L is in [0,1], A and B are typically
+// in [-0.4,0.4]. See https://bottosson.github.io/posts/oklab/
+type OKLab struct {
+	L, A, B, Alpha float64
+}
+
+// NewOKLab returns the color as an OKLab tuple.
+func NewOKLab(col color.Color) OKLab {
+	if lab, ok := col.(OKLab); ok {
+		return lab
+	}
+	c := NewLinearRGBA(col)
+	l := math.Cbrt(0.4122214708*c.R + 0.5363325363*c.G + 0.0514459929*c.B)
+	m := math.Cbrt(0.2119034982*c.R + 0.6806995451*c.G + 0.1073969566*c.B)
+	s := math.Cbrt(0.0883024619*c.R + 0.2817188376*c.G + 0.6299787005*c.B)
+	return OKLab{
+		0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+		c.A}
+}
+
+// RGBA implements the RGBA function from the Color interface.
+func (c OKLab) RGBA() (uint32, uint32, uint32, uint32) {
+	l := c.L + 0.3963377774*c.A + 0.2158037573*c.B
+	m := c.L - 0.1055613458*c.A - 0.0638541728*c.B
+	s := c.L - 0.0894841775*c.A - 1.2914855480*c.B
+	l, m, s = l*l*l, m*m*m, s*s*s
+	return linearRGBA(
+		4.0767416621*l-3.3077115913*m+0.2309699292*s,
+		-1.2684380046*l+2.6097574011*m-0.3413193965*s,
+		-0.0041960863*l-0.7034186147*m+1.7076147010*s,
+		c.Alpha)
+}
+
+// OKLabModel for conversion of a color to OKLab.
+var OKLabModel color.Model = color.ModelFunc(oklabModel)
+
+func oklabModel(col color.Color) color.Color {
+	return NewOKLab(col)
+}
+
+// OKLCh describes a color in the polar form of OKLab. L is in [0,1], C is typically in [0,0.4] and H is
+// the hue angle mapped to [0,1].
+type OKLCh struct {
+	L, C, H, Alpha float64
+}
+
+// NewOKLCh returns the color as an OKLCh tuple.
+func NewOKLCh(col color.Color) OKLCh {
+	if lch, ok := col.(OKLCh); ok {
+		return lch
+	}
+	lab := NewOKLab(col)
+	c, h := toPolar(lab.A, lab.B)
+	return OKLCh{lab.L, c, h, lab.Alpha}
+}
+
+// RGBA implements the RGBA function from the Color interface.
+func (c OKLCh) RGBA() (uint32, uint32, uint32, uint32) {
+	a, b := fromPolar(c.C, c.H)
+	return OKLab{c.L, a, b, c.Alpha}.RGBA()
+}
+
+// OKLChModel for conversion of a color to OKLCh.
+var OKLChModel color.Model = color.ModelFunc(oklchModel)
+
+func oklchModel(col color.Color) color.Color {
+	return NewOKLCh(col)
+}
@@ -0,0 +1,107 @@
+package color
+
+import (
+	"image/color"
+	"math"
+)
+
+// Space defines a color space used for interpolation and for constructing colors from channel values.
+type Space int
+
+// Constants for color spaces.
+const (
+	RGBSpace Space = iota // Gamma encoded sRGB
+	LinearRGBSpace
+	HSVSpace
+	HSLSpace
+	LabSpace
+	LChSpace
+	OKLabSpace
+	OKLChSpace
+)
+
+// Lerp calculates the color value at t [0,1] given a start and end color, interpolating in the color space.
+// The RGB spaces are interpolated premultiplied.
+// Hues in the polar spaces take the shortest path. If one color is achromatic, its hue is taken from the
+// other so that the path doesn't pass through an unrelated hue.
+func Lerp(t float64, start, end color.Color, space Space) color.Color {
+	switch space {
+	default:
+		fallthrough
+	case RGBSpace:
+		return LerpPremul(t, NewFRGBA(start), NewFRGBA(end))
+	case LinearRGBSpace:
+		cs, ce := NewLinearRGBA(start), NewLinearRGBA(end)
+		c := LerpPremul(t, FRGBA{cs.R, cs.G, cs.B, cs.A}, FRGBA{ce.R, ce.G, ce.B, ce.A})
+		return LinearRGBA{c.R, c.G, c.B, c.A}
+	case HSVSpace:
+		cs, ce := NewHSV(start), NewHSV(end)
+		h := lerpHue(t, cs.H, ce.H, cs.S, ce.S, 0.0001)
+		return HSV{h, lerp(t, cs.S, ce.S), lerp(t, cs.V, ce.V), lerp(t, cs.A, ce.A)}
+	case HSLSpace:
+		cs, ce := NewHSL(start), NewHSL(end)
+		h := lerpHue(t, cs.H, ce.H, cs.S, ce.S, 0.0001)
+		return HSL{h, lerp(t, cs.S, ce.S), lerp(t, cs.L, ce.L), lerp(t, cs.A, ce.A)}
+	case LabSpace:
+		cs, ce := NewLab(start), NewLab(end)
+		return Lab{lerp(t, cs.L, ce.L), lerp(t, cs.A, ce.A), lerp(t, cs.B, ce.B), lerp(t, cs.Alpha, ce.Alpha)}
+	case LChSpace:
+		cs, ce := NewLCh(start), NewLCh(end)
+		h := lerpHue(t, cs.H, ce.H, cs.C, ce.C, 0.5)
+		return LCh{lerp(t, cs.L, ce.L), lerp(t, cs.C, ce.C), h, lerp(t, cs.Alpha, ce.Alpha)}
+	case OKLabSpace:
+		cs, ce := NewOKLab(start), NewOKLab(end)
+		return OKLab{lerp(t, cs.L, ce.L), lerp(t, cs.A, ce.A), lerp(t, cs.B, ce.B), lerp(t, cs.Alpha, ce.Alpha)}
+	case OKLChSpace:
+		cs, ce := NewOKLCh(start), NewOKLCh(end)
+		h := lerpHue(t, cs.H, ce.H, cs.C, ce.C, 0.002)
+		return OKLCh{lerp(t, cs.L, ce.L), lerp(t, cs.C, ce.C), h, lerp(t, cs.Alpha, ce.Alpha)}
+	}
+}
+
+// Color returns a color in the space from three channel values and alpha, all in [0,1]. The channels are
+// scaled to the typical range of each of the space's components.
+func (s Space) Color(v1, v2, v3, a float64) color.Color {
+	switch s {
+	default:
+		fallthrough
+	case RGBSpace:
+		return FRGBA{v1, v2, v3, a}
+	case LinearRGBSpace:
+		return LinearRGBA{v1, v2, v3, a}
+	case HSVSpace:
+		return HSV{v1, v2, v3, a}
+	case HSLSpace:
+		return HSL{v1, v2, v3, a}
+	case LabSpace:
+		return Lab{v1 * 100, v2*255 - 128, v3*255 - 128, a}
+	case LChSpace:
+		return LCh{v1 * 100, v2 * 150, v3, a}
+	case OKLabSpace:
+		return OKLab{v1, v2*0.8 - 0.4, v3*0.8 - 0.4, a}
+	case OKLChSpace:
+		return OKLCh{v1, v2 * 0.4, v3, a}
+	}
+}
+
+func lerp(t, s, e float64) float64 {
+	return (1-t)*s + t*e
+}
+
+// lerpHue interpolates hues in [0,1] taking the shortest path. Achromatic colors (chroma less than eps)
+// adopt the other color's hue.
+func lerpHue(t, hs, he, cs, ce, eps float64) float64 {
+	if cs < eps {
+		hs = he
+	} else if ce < eps {
+		he = hs
+	}
+	d := he - hs
+	if d > 0.5 {
+		d -= 1
+	} else if d < -0.5 {
+		d += 1
+	}
+	h := hs + t*d
+	return h - math.Floor(h)
+}
@@ -0,0 +1,155 @@
+package color_test
+
+import (
+	tcol "github.com/jphsd/texture/color"
+	"image/color"
+	"math"
+	"testing"
+)
+
+var (
+	red   = color.NRGBA{255, 0, 0, 255}
+	green = color.NRGBA{0, 255, 0, 255}
+	blue  = color.NRGBA{0, 0, 255, 255}
+	white = color.NRGBA{255, 255, 255, 255}
+)
+
+// near returns true if the values in a and b differ by at most tol.
+func near(a, b []float64, tol float64) bool {
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > tol {
+			return false
+		}
+	}
+	return true
+}
+
+// sameRGBA returns true if c1 and c2 are within a couple of 16 bit steps of each other.
+func sameRGBA(c1, c2 color.Color) bool {
+	r1, g1, b1, a1 := c1.RGBA()
+	r2, g2, b2, a2 := c2.RGBA()
+	return near([]float64{float64(r1), float64(g1), float64(b1), float64(a1)},
+		[]float64{float64(r2), float64(g2), float64(b2), float64(a2)}, 2)
+}
+
+func TestSRGBLinear(t *testing.T) {
+	tests := []struct {
+		s, l float64
+	}{
+		{0, 0},
+		{0.04, 0.04 / 12.92},
+		{0.5, 0.2140411404},
+		{0.8, 0.6038273389},
+		{1, 1},
+	}
+	for _, test := range tests {
+		if l := tcol.SRGBToLinear(test.s); math.Abs(l-test.l) > 1e-9 {
+			t.Errorf("SRGBToLinear(%g) is %g, expected %g", test.s, l, test.l)
+		}
+		if s := tcol.LinearToSRGB(test.l); math.Abs(s-test.s) > 1e-9 {
+			t.Errorf("LinearToSRGB(%g) is %g, expected %g", test.l, s, test.s)
+		}
+	}
+
+	c := color.NRGBA{128, 64, 200, 255}
+	if lc := tcol.NewLinearRGBA(c); !sameRGBA(lc, c) {
+		t.Errorf("%v doesn't round trip through %v", c, lc)
+	}
+}
+
+func TestLab(t *testing.T) {
+	// Reference values for D65
+	tests := []struct {
+		c        color.Color
+		lab, lch []float64
+	}{
+		{white, []float64{100, 0, 0}, []float64{100, 0}},
+		{red, []float64{53.2408, 80.0925, 67.2032}, []float64{53.2408, 104.5518, 39.9990}},
+		{green, []float64{87.7347, -86.1827, 83.1793}, []float64{87.7347, 119.7759, 136.0160}},
+		{blue, []float64{32.2970, 79.1875, -107.8602}, []float64{32.2970, 133.8076, 306.2849}},
+	}
+	for _, test := range tests {
+		lab := tcol.NewLab(test.c)
+		if !near([]float64{lab.L, lab.A, lab.B}, test.lab, 0.01) {
+			t.Errorf("Lab of %v is %v, expected %v", test.c, lab, test.lab)
+		}
+		if !sameRGBA(lab, test.c) {
+			t.Errorf("%v doesn't round trip through %v", test.c, lab)
+		}
+		lch := tcol.NewLCh(test.c)
+		got := []float64{lch.L, lch.C}
+		if len(test.lch) > 2 {
+			got = append(got, lch.H*360)
+		}
+		if !near(got, test.lch, 0.01) {
+			t.Errorf("LCh of %v is %v, expected %v", test.c, lch, test.lch)
+		}
+		if !sameRGBA(lch, test.c) {
+			t.Errorf("%v doesn't round trip through %v", test.c, lch)
+		}
+	}
+}
+
+func TestOKLab(t *testing.T) {
+	// Reference values from https://bottosson.github.io/posts/oklab/
+	tests := []struct {
+		c          color.Color
+		oklab, lch []float64
+	}{
+		{white, []float64{1, 0, 0}, []float64{1, 0}},
+		{red, []float64{0.627955, 0.224863, 0.125846}, []float64{0.627955, 0.257683, 29.2339}},
+		{green, []float64{0.866440, -0.233888, 0.179498}, []float64{0.866440, 0.294827, 142.4953}},
+		{blue, []float64{0.452014, -0.032457, -0.311528}, []float64{0.452014, 0.313214, 264.0520}},
+	}
+	for _, test := range tests {
+		lab := tcol.NewOKLab(test.c)
+		if !near([]float64{lab.L, lab.A, lab.B}, test.oklab, 1e-4) {
+			t.Errorf("OKLab of %v is %v, expected %v", test.c, lab, test.oklab)
+		}
+		if !sameRGBA(lab, test.c) {
+			t.Errorf("%v doesn't round trip through %v", test.c, lab)
+		}
+		lch := tcol.NewOKLCh(test.c)
+		got := []float64{lch.L, lch.C}
+		tol := []float64{1e-4, 1e-4, 0.01}
+		if len(test.lch) > 2 {
+			got = append(got, lch.H*360)
+		}
+		for i := range got {
+			if math.Abs(got[i]-test.lch[i]) > tol[i] {
+				t.Errorf("OKLCh of %v is %v, expected %v", test.c, lch, test.lch)
+				break
+			}
+		}
+		if !sameRGBA(lch, test.c) {
+			t.Errorf("%v doesn't round trip through %v", test.c, lch)
+		}
+	}
+}
+
+func TestLerpHue(t *testing.T) {
+	tests := []struct {
+		space tcol.Space
+		hue   func(color.Color) float64
+	}{
+		{tcol.LChSpace, func(c color.Color) float64 { return c.(tcol.LCh).H }},
+		{tcol.OKLChSpace, func(c color.Color) float64 { return c.(tcol.OKLCh).H }},
+	}
+	for _, test := range tests {
+		// Red to blue takes the short path, through magenta and across 0
+		hr, hb := test.hue(tcol.Lerp(0, red, blue, test.space)), test.hue(tcol.Lerp(1, red, blue, test.space))
+		d := hb - hr - 1
+		for _, tv := range []float64{0.25, 0.5, 0.75} {
+			want := hr + tv*d
+			want -= math.Floor(want)
+			if h := test.hue(tcol.Lerp(tv, red, blue, test.space)); math.Abs(h-want) > 1e-9 {
+				t.Errorf("space %d: hue at %g is %g, expected %g", test.space, tv, h, want)
+			}
+		}
+
+		// White has no hue of its own, so the mid point has blue's
+		if h := test.hue(tcol.Lerp(0.5, white, blue, test.space)); math.Abs(h-hb) > 1e-9 {
+			t.Errorf("space %d: white to blue hue is %g, expected %g", test.space, h, hb)
+		}
+	}
+}
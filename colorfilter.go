@@ -0,0 +1,240 @@
+package texture
+
+import (
+	tcol "github.com/jphsd/texture/color"
+	"image/color"
+	"math"
+)
+
+// Color filter functions map colors to colors. If a filter's Mod field is set, its value mapped to [0,1]
+// scales the strength of the adjustment at that location, otherwise the adjustment is applied in full.
+
+// ColorLevels maps the R, G and B channels from [InBlack,InWhite] to [0,1], applies the gamma correction
+// and then maps the result to [OutBlack,OutWhite]. All values are in [0,1] except for Gamma.
+type ColorLevels struct {
+	Name     string
+	Src      ColorField
+	InBlack  float64
+	InWhite  float64
+	Gamma    float64
+	OutBlack float64
+	OutWhite float64
+	Mod      Field
+}
+
+func NewColorLevels(src ColorField, inb, inw, gamma, outb, outw float64, mod Field) *ColorLevels {
+	return &ColorLevels{"ColorLevels", src, inb, inw, gamma, outb, outw, mod}
+}
+
+// Eval2 implements the ColorField interface.
+func (f *ColorLevels) Eval2(x, y float64) color.Color {
+	c := tcol.NewFRGBA(f.Src.Eval2(x, y))
+	d := f.InWhite - f.InBlack
+	if d < 0.000001 {
+		d = 0.000001
+	}
+	g := f.Gamma
+	if g <= 0 {
+		g = 1
+	}
+	level := func(v float64) float64 {
+		v = bcclamp((v - f.InBlack) / d)
+		v = math.Pow(v, 1/g)
+		return f.OutBlack + v*(f.OutWhite-f.OutBlack)
+	}
+	return modColor(f.Mod, x, y, c, tcol.FRGBA{level(c.R), level(c.G), level(c.B), c.A})
+}
+
+// ColorCurves applies a [NonLinear] curve to each of the R, G and B channels. A nil curve leaves
+// the channel unchanged.
+type ColorCurves struct {
+	Name string
+	Src  ColorField
+	R    *NonLinear
+	G    *NonLinear
+	B    *NonLinear
+	Mod  Field
+}
+
+func NewColorCurves(src ColorField, r, g, b *NonLinear, mod Field) *ColorCurves {
+	return &ColorCurves{"ColorCurves", src, r, g, b, mod}
+}
+
+// Eval2 implements the ColorField interface.
+func (f *ColorCurves) Eval2(x, y float64) color.Color {
+	c := tcol.NewFRGBA(f.Src.Eval2(x, y))
+	curve := func(nl *NonLinear, v float64) float64 {
+		if nl == nil {
+			return v
+		}
+		return bcclamp(nl.Eval0(v))
+	}
+	return modColor(f.Mod, x, y, c, tcol.FRGBA{curve(f.R, c.R), curve(f.G, c.G), curve(f.B, c.B), c.A})
+}
+
+// ColorHSLAdjust shifts the hue by Hue [-1,1] (in full turns), and adjusts the saturation and lightness
+// by Sat and Light [-1,1]. -1 removes all saturation (or lightness), 1 doubles the saturation (or pushes
+// the lightness to white).
+type ColorHSLAdjust struct {
+	Name  string
+	Src   ColorField
+	Hue   float64
+	Sat   float64
+	Light float64
+	Mod   Field
+}
+
+func NewColorHSLAdjust(src ColorField, hue, sat, light float64, mod Field) *ColorHSLAdjust {
+	return &ColorHSLAdjust{"ColorHSLAdjust", src, hue, sat, light, mod}
+}
+
+// Eval2 implements the ColorField interface.
+func (f *ColorHSLAdjust) Eval2(x, y float64) color.Color {
+	col := f.Src.Eval2(x, y)
+	t := modAmount(f.Mod, x, y)
+	hsl := tcol.NewHSL(col)
+	h := hsl.H + f.Hue*t
+	hsl.H = h - math.Floor(h)
+	hsl.S = bcclamp(hsl.S * (1 + f.Sat*t))
+	if l := f.Light * t; l > 0 {
+		hsl.L += (1 - hsl.L) * l
+	} else {
+		hsl.L *= 1 + l
+	}
+	return hsl
+}
+
+// ColorBalance shifts the R, G and B channels of the shadows, midtones and highlights independently.
+// Each shift is a triplet in [-1,1] for cyan-red, magenta-green and yellow-blue. The tones are weighted
+// by the luminance of the source color. If PreserveLum is set, the luminance of the source is retained.
+// Missing shifts are zero.
+type ColorBalance struct {
+	Name        string
+	Src         ColorField
+	Shadows     []float64
+	Midtones    []float64
+	Highlights  []float64
+	PreserveLum bool
+	Mod         Field
+}
+
+func NewColorBalance(src ColorField, shadows, midtones, highlights []float64, preserve bool, mod Field) *ColorBalance {
+	return &ColorBalance{"ColorBalance", src, shadows, midtones, highlights, preserve, mod}
+}
+
+// Eval2 implements the ColorField interface.
+func (f *ColorBalance) Eval2(x, y float64) color.Color {
+	c := tcol.NewFRGBA(f.Src.Eval2(x, y))
+	l := 0.3*c.R + 0.59*c.G + 0.11*c.B
+	ws, wh := (1-l)*(1-l), l*l
+	wm := 1 - ws - wh
+	shift := func(i int) float64 {
+		v := ws*elt(f.Shadows, i) + wm*elt(f.Midtones, i) + wh*elt(f.Highlights, i)
+		return v / 2
+	}
+	res := tcol.FRGBA{bcclamp(c.R + shift(0)), bcclamp(c.G + shift(1)), bcclamp(c.B + shift(2)), c.A}
+	if f.PreserveLum {
+		res = tcol.Blend(tcol.LuminosityBlend, res, c, 1)
+	}
+	return modColor(f.Mod, x, y, c, res)
+}
+
+// ColorMixer forms each output channel from a weighted sum of the input channels plus a constant.
+// Mix holds three rows, for R, G and B, of {r, g, b, constant} weights. A missing row leaves its channel
+// unchanged and missing weights are zero.
+type ColorMixer struct {
+	Name string
+	Src  ColorField
+	Mix  [][]float64
+	Mod  Field
+}
+
+func NewColorMixer(src ColorField, mix [][]float64, mod Field) *ColorMixer {
+	return &ColorMixer{"ColorMixer", src, mix, mod}
+}
+
+// Eval2 implements the ColorField interface.
+func (f *ColorMixer) Eval2(x, y float64) color.Color {
+	c := tcol.NewFRGBA(f.Src.Eval2(x, y))
+	in := []float64{c.R, c.G, c.B}
+	mix := func(i int) float64 {
+		if i >= len(f.Mix) {
+			return in[i]
+		}
+		w := f.Mix[i]
+		return bcclamp(elt(w, 0)*c.R + elt(w, 1)*c.G + elt(w, 2)*c.B + elt(w, 3))
+	}
+	return modColor(f.Mod, x, y, c, tcol.FRGBA{mix(0), mix(1), mix(2), c.A})
+}
+
+// elt returns s[i], or 0 if s is too short.
+func elt(s []float64, i int) float64 {
+	if i < len(s) {
+		return s[i]
+	}
+	return 0
+}
+
+// ColorInvert replaces the R, G and B channels with 1 - value.
+type ColorInvert struct {
+	Name string
+	Src  ColorField
+	Mod  Field
+}
+
+func NewColorInvert(src ColorField, mod Field) *ColorInvert {
+	return &ColorInvert{"ColorInvert", src, mod}
+}
+
+// Eval2 implements the ColorField interface.
+func (f *ColorInvert) Eval2(x, y float64) color.Color {
+	c := tcol.NewFRGBA(f.Src.Eval2(x, y))
+	return modColor(f.Mod, x, y, c, tcol.FRGBA{1 - c.R, 1 - c.G, 1 - c.B, c.A})
+}
+
+// SepiaTint is a typical tint color for use with [ColorTint].
+var SepiaTint = color.NRGBA{112, 66, 20, 255}
+
+// ColorTint converts the source to monochrome and then applies the hue and saturation of the tint color,
+// preserving the source's luminance. A white or gray tint produces a plain monochrome result.
+type ColorTint struct {
+	Name string
+	Src  ColorField
+	Tint color.Color
+	Mod  Field
+}
+
+func NewColorTint(src ColorField, tint color.Color, mod Field) *ColorTint {
+	return &ColorTint{"ColorTint", src, tint, mod}
+}
+
+// Eval2 implements the ColorField interface.
+func (f *ColorTint) Eval2(x, y float64) color.Color {
+	c := tcol.NewFRGBA(f.Src.Eval2(x, y))
+	tint := tcol.NewFRGBA(f.Tint)
+	tint.A = 1
+	res := tcol.Blend(tcol.ColorBlend, tcol.FRGBA{c.R, c.G, c.B, 1}, tint, 1)
+	res.A = c.A
+	return modColor(f.Mod, x, y, c, res)
+}
+
+// modAmount returns the modulation field's value at x, y mapped to [0,1], or 1 if there's no field.
+func modAmount(mod Field, x, y float64) float64 {
+	if mod == nil {
+		return 1
+	}
+	return bcclamp((mod.Eval2(x, y) + 1) / 2)
+}
+
+// modColor returns the mix of the original and adjusted colors given by the modulation field.
+func modColor(mod Field, x, y float64, orig, adj tcol.FRGBA) tcol.FRGBA {
+	if mod == nil {
+		return adj
+	}
+	t := modAmount(mod, x, y)
+	return tcol.FRGBA{
+		lerp(t, orig.R, adj.R),
+		lerp(t, orig.G, adj.G),
+		lerp(t, orig.B, adj.B),
+		lerp(t, orig.A, adj.A)}
+}
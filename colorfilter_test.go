@@ -0,0 +1,43 @@
+package texture_test
+
+import (
+	"github.com/jphsd/texture"
+	tcol "github.com/jphsd/texture/color"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestColorFilterShortParams(t *testing.T) {
+	src := texture.NewUniformCF(color.NRGBA{200, 100, 50, 255})
+	want := tcol.NewFRGBA(src.Eval2(0, 0))
+	near := func(c color.Color, r, g, b float64) bool {
+		fc := tcol.NewFRGBA(c)
+		return math.Abs(fc.R-r) < 1e-9 && math.Abs(fc.G-g) < 1e-9 && math.Abs(fc.B-b) < 1e-9
+	}
+
+	// A mixer from NewNode has no rows and leaves the color unchanged
+	n, err := texture.NewNode("ColorMixer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mixer := n.(*texture.ColorMixer)
+	mixer.Src = src
+	if c := mixer.Eval2(0, 0); !near(c, want.R, want.G, want.B) {
+		t.Errorf("empty mixer gives %v, expected %v", c, want)
+	}
+
+	// Rows without a constant, and a missing B row
+	mixer = texture.NewColorMixer(src, [][]float64{{0, 1, 0}, {1, 0}}, nil)
+	if c := mixer.Eval2(0, 0); !near(c, want.G, want.R, want.B) {
+		t.Errorf("short mixer gives %v", c)
+	}
+
+	// Nil and short shifts are zero
+	bal := texture.NewColorBalance(src, nil, []float64{0.2}, []float64{}, false, nil)
+	l := 0.3*want.R + 0.59*want.G + 0.11*want.B
+	wm := 1 - (1-l)*(1-l) - l*l
+	if c := bal.Eval2(0, 0); !near(c, want.R+wm*0.1, want.G, want.B) {
+		t.Errorf("short balance gives %v", c)
+	}
+}
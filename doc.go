@@ -139,6 +139,16 @@ Some filters take A and B parameters, in which case the value filtered is A*valu
 
 # 5.3 Color Filters (CF)
 
+Color filters adjust the color supplied by their source. The strength of the adjustment can be modulated
+by an optional field.
+  - [ColorBalance] shifts the shadows, midtones and highlights towards red, green or blue
+  - [ColorCurves] applies a [NonLinear] curve to each of R, G and B
+  - [ColorHSLAdjust] shifts the hue and adjusts the saturation and lightness
+  - [ColorInvert] applies 1 - value to R, G and B
+  - [ColorLevels] remaps R, G and B using input and output black and white points, and gamma
+  - [ColorMixer] forms each of R, G and B from a weighted sum of the source channels
+  - [ColorTint] creates a monochrome or tinted, such as [SepiaTint], version of the source
+
 # 6. Nodes - Combiners
 
 The expressive range of the [texture] package is due to the ability to combine multiple source
@@ -163,11 +173,16 @@ fields together using some heuristic.
 
 # 6.2 Vector Combiners (VF)
   - [ShapeCombinerVF] src1 if location inside of shape, src2 otherwise
+  - [NormalBlend] layers the detail normals in src2 onto src1 using whiteout, RNM or UDN blending. See [Barre-Brisebois12]
 
 # 6.3 Color Combiners (CF)
   - [ColorBlend] src1 and src2 are blended according to the value in src3 (F)
+  - [ColorModeBlend] src2 is composited over src1 using a Photoshop style [color.BlendMode] with opacity src3 (F)
+  - [ColorComposite] src1 and src2 are combined using a Porter-Duff [color.CompositeOp] (over, in, out, atop, xor)
   - [ColorSubstitute] src1 if src3 < A or src3 > B, otherwise src2 (src3 is F)
   - [ShapeCombinerCF] src1 if location inside of shape, src2 otherwise
+  - [LayerStack] composites an ordered, editable list of [Layer]s, each with its own mask, blend mode, opacity and
+    enabled flag - a flat alternative to nesting the combiners above
 
 # 7. Nodes - Converters
 
@@ -183,9 +198,12 @@ Convert between F, VF and CF fields
   - [Normal] - converts a field to a vector field of normals using the finite distance method
   - [ColorGray] maps [-1,1] to [Black,White] [image/color.Gray16] values
   - [ColorSinCos] uses one of six modes to convert [-1,1] to color using [math.Sin] and [math.Cos]
-  - [ColorConv] uses a color interpolator to map [-1,1] to color
-  - [ColorFields] uses 4 sources [-1,1], one each for R, G, B, A or H, S, L, A colors
+  - [ColorConv] uses a color interpolator to map [-1,1] to color, interpolating in any of the [LerpType] spaces
+  - [ColorAlpha] scales the alpha of a color field by a field, such as a [Shape], to cut out a layer
+  - [ColorFields] uses 4 sources [-1,1], one each for R, G, B, A or H, S, L, A colors, or the channels of a [color.Space]
   - [ColorVector] uses VF triplets to map to either RGB or HSL colors (A is opaque)
+  - [ColorNormalMap] encodes VF unit normals as OpenGL or DirectX normal map colors
+  - [NormalMap] decodes OpenGL or DirectX normal map colors, such as from an [Image], to VF unit normals
 
 # 8. Nodes - Transformers
 
@@ -338,6 +356,12 @@ once and cache it in an image that can then be referenced through an [Image] nod
   - [TextureGray16] takes a value field
   - [TextureRGBA] takes a color field
   - [TextureRGBA64] takes a color field
+  - [TextureFRGBA] takes a color field and keeps high dynamic range values as float32
+  - [TextureFloat] takes a value field and keeps values outside of [-1,1] as float32
+
+High dynamic range colors, such as from a [github.com/jphsd/texture/surface.Surface] with HDR set, are
+brought back into [0,1] with a [ToneMap] (Reinhard, ACES or filmic, with exposure and gamma).
+[ColorHighlights] extracts the bright areas and [ColorBloom] adds a blurred copy of them back to the source.
 
 # 11.2 Gradients
 
@@ -358,10 +382,108 @@ In the latter case, by using [github.com/jphsd/graphics2d/image.Colorizer].
   - [NewEllipticalRGBA]
   - [NewConicRGBA]
 
+# 11.3 PBR Export
+
+A [PBRComponent] extends a [Component] with roughness, metallic and ambient occlusion fields.
+[SavePBR] realizes it as a set of aligned maps - albedo, normal, height, roughness, metallic, AO and a packed
+ORM map - along with a glTF 2.0 material that references them.
+
+# 11.4 Gradient Maps and Palettes
+
+Gradients and palettes authored in other tools can be imported for use with [ColorConv].
+  - [ReadGGR] reads a GIMP .ggr [Gradient], including its segment blend and coloring types
+  - [ParseCSSGradient] parses the color stops of a CSS linear-gradient into a [Gradient]
+  - [ReadGPL] reads a GIMP .gpl [Palette]
+  - [ReadPDNPalette] reads a Paint.NET [Palette]
+
+[Gradient.ColorConv] and [Palette.ColorConv] create the gradient map for a field.
+The palette's colors, or a list of gradients, can also be supplied to the random package.
+
+[ExtractPalette] finds the representative colors of an image, such as a reference photograph, using
+median cut or k-means in OKLab space. [ColorQuantize] maps a color field to the nearest palette colors,
+with optional ordered or error diffusion dithering.
+
+# 11.5 Full Precision Output
+
+Fields, vector fields and color fields can be written directly to full precision files over a region,
+one row at a time, without realizing the whole image in memory.
+  - [SaveEXR] and [WriteEXR] write OpenEXR with half or float channels, uncompressed or ZIP compressed
+  - [SavePFM] and [WritePFM] write Portable Float Maps
+  - [SaveTIFF] and [WriteTIFF] write 16 bit or float TIFFs
+
+For images too large to hold in memory, [BandRenderer] evaluates a color field in horizontal bands that are
+saved to disk as they complete, reports progress and resumes interrupted renders from the last completed
+band. The bands are then streamed into an 8 bit PNG or TIFF.
+
+# 11.6 Tile Pyramids
+
+[TilePyramid] renders a color field into a Deep Zoom (DZI) or XYZ (slippy map) tile pyramid for zoomable
+viewers. Each level is evaluated directly at its own step size rather than downsampled from the level
+below, and [TilePyramid.SaveViewport] renders just the tiles covering a region at a given level.
+
+# 11.7 Saving and Loading Trees
+
+[SaveJSON] writes a tree as JSON and [LoadJSON] reads it back, identifying each node by its Name field.
+Nodes that hold images, shapes or functions can't be restored. The texture command in cmd/texture renders
+saved trees, generates seeded random ones and provides the kaleidoscope, triangle, warp, component and
+bump lighting modes. Its serve mode runs a node graph editor on localhost, built on [NodeNames] and
+[NewNode], that previews the tree progressively tile by tile.
+
+# 11.8 Parameter Sweeps
+
+[Sweep] renders a labeled contact sheet of the variants of a tree as one parameter, or two laid out as rows
+by columns, takes a list or range of values. Parameters are addressed by their path from the root of the
+tree, such as "Src.WF.Lambdas[0]", and set with [SetParam]. [SweepFunc] does the same for variants made in
+code and [SeedSweep] for trees made from a set of random seeds.
+
+# 11.9 Text DSL
+
+[ParseDSL] builds a tree from a short text description, with named bindings for shared subtrees:
+
+	p = perlin(seed=3)
+	fractal(p, scale(2, 2), fbm(0.5, 2, 6), octaves=6) * radialgradient(nlwave([60], [nlsin()], false, true))
+
+Any constructor can be called by its name less the New, with positional or named arguments, and the
+operators *, +, - make the matching combiners. Shorthands for fractals, gradients and single waves cover
+the common cases:
+
+	fbm(perlin(seed=3), octaves=6) * radial(wave=sin(60))
+
+[FormatDSL] turns a tree back into text, writing nodes as literals of their fields such as
+Perlin{Seed: 3}. [LoadDSL] and [SaveDSL] read and write .tdsl files, which the texture command accepts
+wherever it takes a JSON tree.
+
+# 11.10 Tree Introspection
+
+All the nodes implement [Node], which lists a node's inputs with Children, replaces them with SetChild
+and describes its other fields, with their types, ranges and values, with Params. [Walk] visits the nodes
+of a tree, [Clone] deep copies it, [Map] makes a copy with nodes substituted and [Replace] edits it in place.
+
+[NewGraph] turns a tree into a DAG, with shared subtrees appearing once, which can be written as a Graphviz
+DOT or Mermaid diagram with each node labeled with its type and key parameters. [Graph.Thumbnails] adds
+images of the leaves, rendered with [NewTextureRGBA].
+
+[Optimize] returns a copy of a tree with constant subtrees folded into uniforms, consecutive transforms
+merged, InvertFilter pairs and never selected branches removed, and equal subtrees shared. It reports the
+estimated speedup in evaluations per sample.
+
+[NewProfile] makes an instrumented copy of a tree that counts the Eval2 calls made to each node and the time
+spent in it. After a render, [Profile.WriteReport] lists the nodes by their own time and [Profile.WriteFolded]
+writes stacks for flame graph tools.
+
+# 11.11 Shader Generation
+
+[Shader] compiles a tree of gradients, waves, Perlin noise, combiners, filters, transforms, warps, fractals and
+color conversions into a self-contained GLSL (3.30 or ES 3.00) or WGSL function, tex, that returns the color at
+a point in the tree's coordinates. Nodes it can't compile are listed in an [UnsupportedError]. [CheckShader]
+parses and interprets the GLSL and WGSL text on the CPU and reports its largest difference from the tree over a
+region.
+
 # 12. Package Examples
 
 [Chequered]: https://pkg.go.dev/github.com/jphsd/texture#hdr-4_1_Chequered__F_
 [Barnsley88]: https://doi.org/10.1016/c2013-0-10335-2
+[Barre-Brisebois12]: https://blog.selfshadow.com/publications/blending-in-detail/
 [Blinn82]: https://dl.acm.org/doi/10.1145/357306.357310
 [OpenSimplex]: https://pkg.go.dev/github.com/ojrac/opensimplex-go
 [Perlin93]: https://dl.acm.org/doi/10.1145/325165.325247
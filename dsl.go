@@ -0,0 +1,770 @@
+package texture
+
+import (
+	"fmt"
+	g2d "github.com/jphsd/graphics2d"
+	tcol "github.com/jphsd/texture/color"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/scanner"
+)
+
+// DSLError is a syntax or type error in DSL text, at line Line and column Col.
+type DSLError struct {
+	Line, Col int
+	Msg       string
+}
+
+func (e *DSLError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Col, e.Msg)
+}
+
+// ParseDSL parses a tree described in the texture DSL and returns the value of its final expression.
+//
+// A program is a sequence of bindings, name = expr, followed by an expression, separated by newlines or
+// semicolons. Newlines inside brackets are ignored and comments are as in Go. Expressions are:
+//
+//   - numbers, "strings", true, false, nil and [lists]
+//   - constructor calls, Perlin(3) or perlin(seed=3), with the constructor's name less the New and with
+//     positional or named arguments - omitted arguments are zero valued
+//   - struct literals of a node's exported fields, Perlin{Seed: 3}, as written by [FormatDSL]
+//   - the transforms Translate, Rotate, RotateAbout, Scale, ScaleAbout, Shear and ShearAbout, which
+//     compose with *, and lists of six numbers
+//   - the named constants of the package, such as LerpHSL and OverOp
+//   - the paths Line, Polygon, RegularPolygon, ReentrantPolygon, Rectangle, Circle and Ellipse, which
+//     are passed to Shape singly or as a list
+//   - the functions DistanceE, DistanceESquared and Exp, or a NonLinear, for the functions taken by
+//     BlinnField and WorleyField, which default to those described by their types
+//   - the shorthands fbm(src, octaves, hurst, lacunarity) and mf(src, octaves, hurst, lacunarity, offset)
+//     for a Fractal with an FBM or MF combiner, hurst and lacunarity defaulting to 1 and 2; linear(wave),
+//     radial(wave) and conic(wave) for the gradients; and saw(lambda), triangle(lambda) and sin(lambda)
+//     for single NLWaves, as in fbm(perlin(seed=3), octaves=6) * radial(wave=sin(60))
+//   - a * b, a + b, a - b and -a which make MulCombiner, AddCombiner, SubCombiner and InvertFilter
+//     from fields, or numbers which are lifted to Uniform if the other operand is a field
+//
+// Function and type names are case insensitive, with fbm and mf calling FBM and MF unless their first
+// argument is a field. Strings can be used for colors in CSS notation.
+func ParseDSL(src string) (any, error) {
+	toks, err := dslScan(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &dslParser{toks: toks, binds: map[string]any{}}
+	return p.program()
+}
+
+// LoadDSL reads a tree from name.tdsl.
+func LoadDSL(name string) (any, error) {
+	fn := fmt.Sprintf("%s.tdsl", name)
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	v, err := ParseDSL(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", fn, err)
+	}
+	return v, nil
+}
+
+// SaveDSL writes v to name.tdsl using [FormatDSL].
+func SaveDSL(v any, name string) error {
+	s, err := FormatDSL(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fmt.Sprintf("%s.tdsl", name), []byte(s), 0644)
+}
+
+type dslToken struct {
+	tok       rune
+	text      string
+	line, col int
+}
+
+func (t dslToken) String() string {
+	switch t.tok {
+	case scanner.EOF:
+		return "end of input"
+	case '\n':
+		return "newline"
+	}
+	return strconv.Quote(t.text)
+}
+
+func dslScan(src string) ([]dslToken, error) {
+	var s scanner.Scanner
+	s.Init(strings.NewReader(src))
+	s.Whitespace = 1<<'\t' | 1<<'\r' | 1<<' '
+	var err error
+	s.Error = func(s *scanner.Scanner, msg string) {
+		if err == nil {
+			err = &DSLError{s.Position.Line, s.Position.Column, msg}
+		}
+	}
+
+	var res []dslToken
+	for {
+		tok := s.Scan()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, dslToken{tok, s.TokenText(), s.Position.Line, s.Position.Column})
+		if tok == scanner.EOF {
+			// Report the end of input after the last token
+			res[len(res)-1].line, res[len(res)-1].col = s.Pos().Line, s.Pos().Column
+			return res, nil
+		}
+	}
+}
+
+type dslParser struct {
+	toks  []dslToken
+	pos   int
+	depth int // Bracket nesting, inside which newlines are ignored
+	binds map[string]any
+}
+
+// dslArg is an argument to a call or a field of a literal.
+type dslArg struct {
+	name string
+	v    any
+	tok  dslToken // Start of the value
+	ntok dslToken // Start of the argument, its name if it has one
+}
+
+func (p *dslParser) skip() {
+	for p.depth > 0 && p.toks[p.pos].tok == '\n' {
+		p.pos++
+	}
+}
+
+// newlines skips newlines, which are allowed after an operator.
+func (p *dslParser) newlines() {
+	for p.toks[p.pos].tok == '\n' {
+		p.pos++
+	}
+}
+
+func (p *dslParser) peek() dslToken {
+	p.skip()
+	return p.toks[p.pos]
+}
+
+// peek2 returns the token after the next one.
+func (p *dslParser) peek2() dslToken {
+	p.skip()
+	if p.pos+1 < len(p.toks) {
+		return p.toks[p.pos+1]
+	}
+	return p.toks[p.pos]
+}
+
+func (p *dslParser) next() dslToken {
+	t := p.peek()
+	if t.tok != scanner.EOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *dslParser) expect(tok rune) (dslToken, error) {
+	t := p.next()
+	if t.tok != tok {
+		return t, p.errorf(t, "expected %q, found %s", tok, t)
+	}
+	return t, nil
+}
+
+func (p *dslParser) errorf(t dslToken, format string, args ...any) error {
+	return &DSLError{t.line, t.col, fmt.Sprintf(format, args...)}
+}
+
+func (p *dslParser) program() (any, error) {
+	for {
+		t := p.next()
+		switch t.tok {
+		case '\n', ';':
+			continue
+		case scanner.EOF:
+			return nil, p.errorf(t, "missing final expression")
+		}
+		p.pos--
+
+		if t.tok == scanner.Ident && p.peek2().tok == '=' {
+			p.pos += 2
+			if _, ok := p.binds[t.text]; ok {
+				return nil, p.errorf(t, "%s is already bound", t.text)
+			}
+			if dslReserved(t.text) {
+				return nil, p.errorf(t, "can't bind %s", t.text)
+			}
+			v, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			p.binds[t.text] = v
+			if t := p.next(); t.tok != '\n' && t.tok != ';' && t.tok != scanner.EOF {
+				return nil, p.errorf(t, "expected newline or ';' after binding, found %s", t)
+			}
+			continue
+		}
+
+		v, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		for {
+			t := p.next()
+			switch t.tok {
+			case '\n', ';':
+				continue
+			case scanner.EOF:
+				return v, nil
+			}
+			return nil, p.errorf(t, "unexpected %s after the final expression", t)
+		}
+	}
+}
+
+func dslReserved(name string) bool {
+	return name == "true" || name == "false" || name == "nil"
+}
+
+// expr parses sums and differences.
+func (p *dslParser) expr() (any, error) {
+	x, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if op.tok != '+' && op.tok != '-' {
+			return x, nil
+		}
+		p.next()
+		p.newlines()
+		y, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		if x, err = p.binary(op, x, y); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// term parses products and quotients.
+func (p *dslParser) term() (any, error) {
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if op.tok != '*' && op.tok != '/' {
+			return x, nil
+		}
+		p.next()
+		p.newlines()
+		y, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if x, err = p.binary(op, x, y); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *dslParser) unary() (any, error) {
+	if op := p.peek(); op.tok == '-' {
+		p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		switch v := x.(type) {
+		case int64:
+			return -v, nil
+		case float64:
+			return -v, nil
+		case Field:
+			return NewInvertFilter(v), nil
+		}
+		return nil, p.errorf(op, "can't negate %s", dslTypeName(x))
+	}
+	return p.primary()
+}
+
+func (p *dslParser) binary(op dslToken, x, y any) (any, error) {
+	if a, ok := x.(int64); ok {
+		if b, ok := y.(int64); ok && op.tok != '/' {
+			switch op.tok {
+			case '+':
+				return a + b, nil
+			case '-':
+				return a - b, nil
+			}
+			return a * b, nil
+		}
+	}
+	a, aok := dslFloat(x)
+	b, bok := dslFloat(y)
+	if aok && bok {
+		switch op.tok {
+		case '+':
+			return a + b, nil
+		case '-':
+			return a - b, nil
+		case '*':
+			return a * b, nil
+		}
+		return a / b, nil
+	}
+
+	if op.tok == '*' {
+		a, aok := x.(*g2d.Aff3)
+		b, bok := y.(*g2d.Aff3)
+		if aok && bok {
+			r := *a
+			return r.Concatenate(*b), nil
+		}
+	}
+
+	f1, ok1 := dslField(x)
+	f2, ok2 := dslField(y)
+	if ok1 && ok2 {
+		switch op.tok {
+		case '+':
+			return NewAddCombiner(f1, f2), nil
+		case '-':
+			return NewSubCombiner(f1, f2), nil
+		case '*':
+			return NewMulCombiner(f1, f2), nil
+		}
+	}
+	return nil, p.errorf(op, "can't use %q with %s and %s", op.tok, dslTypeName(x), dslTypeName(y))
+}
+
+// dslFloat returns v as a float if it's a number.
+func dslFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// dslField returns v as a field, lifting numbers to Uniform.
+func dslField(v any) (Field, bool) {
+	if f, ok := v.(Field); ok {
+		return f, true
+	}
+	if n, ok := dslFloat(v); ok {
+		return NewUniform(n), true
+	}
+	return nil, false
+}
+
+func (p *dslParser) primary() (any, error) {
+	t := p.next()
+	switch t.tok {
+	case scanner.Int:
+		// Integers are kept exact for seeds
+		if i, err := strconv.ParseInt(t.text, 0, 64); err == nil {
+			return i, nil
+		}
+		fallthrough
+	case scanner.Float:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf(t, "bad number %s", t.text)
+		}
+		return f, nil
+	case scanner.String, scanner.RawString:
+		s, err := strconv.Unquote(t.text)
+		if err != nil {
+			return nil, p.errorf(t, "bad string %s", t.text)
+		}
+		return s, nil
+	case '(':
+		p.depth++
+		v, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.peek(); t.tok != ')' {
+			return nil, p.errorf(t, "expected ')', found %s", t)
+		}
+		p.depth--
+		p.next()
+		return v, nil
+	case '[':
+		elts, err := p.list(']', false)
+		if err != nil {
+			return nil, err
+		}
+		res := make([]any, len(elts))
+		for i, e := range elts {
+			res[i] = e.v
+		}
+		return res, nil
+	case scanner.Ident:
+		return p.ident(t)
+	}
+	return nil, p.errorf(t, "unexpected %s", t)
+}
+
+func (p *dslParser) ident(t dslToken) (any, error) {
+	switch t.text {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "nil":
+		return nil, nil
+	}
+
+	switch p.toks[p.pos].tok {
+	case '(':
+		p.next()
+		f, ok := dslFuncs[strings.ToLower(t.text)]
+		if !ok {
+			if _, ok := jsonTypes[t.text]; ok {
+				return nil, p.errorf(t, "%s has no constructor, write it as %s{...}", t.text, t.text)
+			}
+			return nil, p.errorf(t, "unknown function %s", t.text)
+		}
+		args, err := p.list(')', true)
+		if err != nil {
+			return nil, err
+		}
+		return p.call(t, f.pick(args), args)
+	case '{':
+		p.next()
+		fields, err := p.list('}', true)
+		if err != nil {
+			return nil, err
+		}
+		return p.literal(t, fields)
+	}
+
+	if v, ok := p.binds[t.text]; ok {
+		return v, nil
+	}
+	if v, ok := dslConsts[t.text]; ok {
+		return v, nil
+	}
+	if v, ok := dslFuncValues[t.text]; ok {
+		return v, nil
+	}
+	if f, ok := dslFuncs[strings.ToLower(t.text)]; ok {
+		return nil, p.errorf(t, "%s is a function, call it as %s(...)", t.text, f.name)
+	}
+	return nil, p.errorf(t, "undefined: %s", t.text)
+}
+
+// list parses the comma separated expressions up to end, the opening bracket having been read. If named
+// is set, they may be preceded by name = or name:.
+func (p *dslParser) list(end rune, named bool) ([]dslArg, error) {
+	p.depth++
+	var res []dslArg
+	for p.peek().tok != end {
+		t := p.peek()
+		a := dslArg{tok: t, ntok: t}
+		if sep := p.peek2().tok; named && t.tok == scanner.Ident && (sep == '=' || sep == ':') {
+			p.pos += 2
+			a.name = t.text
+			a.tok = p.peek()
+		}
+		v, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		a.v = v
+		res = append(res, a)
+		if t := p.peek(); t.tok == ',' {
+			p.next()
+		} else if t.tok != end {
+			return nil, p.errorf(t, "expected ',' or %q, found %s", end, t)
+		}
+	}
+	p.depth--
+	p.next()
+	return res, nil
+}
+
+// call calls f with the arguments, converting them to the types of its parameters.
+func (p *dslParser) call(t dslToken, f *dslFunc, args []dslArg) (res any, err error) {
+	ft := reflect.TypeOf(f.fn)
+	params := strings.Fields(f.params)
+	in := make([]reflect.Value, ft.NumIn())
+	last := len(in) - 1
+	var rest []reflect.Value // Positional arguments for a variadic parameter
+	named := false
+	for i, a := range args {
+		n := -1
+		if a.name == "" {
+			if named {
+				return nil, p.errorf(a.tok, "positional argument after named arguments")
+			}
+			if i < len(in) {
+				n = i
+			} else if ft.IsVariadic() {
+				n = last
+			} else {
+				return nil, p.errorf(a.tok, "too many arguments to %s (%s)", f.name, f.params)
+			}
+		} else {
+			named = true
+			for j, pn := range params {
+				if strings.EqualFold(pn, a.name) {
+					n = j
+				}
+			}
+			if n < 0 {
+				return nil, p.errorf(a.ntok, "%s has no parameter %s (%s)", f.name, a.name, f.params)
+			}
+			if in[n].IsValid() {
+				return nil, p.errorf(a.ntok, "parameter %s of %s is set twice", params[n], f.name)
+			}
+		}
+
+		pt := ft.In(n)
+		if ft.IsVariadic() && n == last && a.name == "" {
+			v, err := dslConvert(a.v, pt.Elem())
+			if err != nil {
+				return nil, p.errorf(a.tok, "argument %s of %s: %v", params[n], f.name, err)
+			}
+			rest = append(rest, v)
+			continue
+		}
+		v, err := dslConvert(a.v, pt)
+		if err != nil {
+			return nil, p.errorf(a.tok, "argument %s of %s: %v", params[n], f.name, err)
+		}
+		in[n] = v
+	}
+	if rest != nil {
+		if in[last].IsValid() {
+			return nil, p.errorf(t, "parameter %s of %s is set twice", params[last], f.name)
+		}
+		in[last] = reflect.Append(reflect.MakeSlice(ft.In(last), 0, len(rest)), rest...)
+	}
+	for i, v := range in {
+		if !v.IsValid() {
+			in[i] = reflect.Zero(ft.In(i))
+		}
+	}
+
+	// Some constructors evaluate their sources
+	defer func() {
+		if r := recover(); r != nil {
+			err = p.errorf(t, "%s: %v", f.name, r)
+		}
+	}()
+	var out []reflect.Value
+	if ft.IsVariadic() {
+		out = reflect.ValueOf(f.fn).CallSlice(in)
+	} else {
+		out = reflect.ValueOf(f.fn).Call(in)
+	}
+	return out[0].Interface(), nil
+}
+
+// literal makes a node, named nonlinear function or other struct from its fields.
+func (p *dslParser) literal(t dslToken, fields []dslArg) (any, error) {
+	for name, f := range nlConstructors {
+		if strings.EqualFold(name, t.text) {
+			if len(fields) > 0 {
+				return nil, p.errorf(fields[0].tok, "%s has no fields", name)
+			}
+			return f(), nil
+		}
+	}
+
+	var res reflect.Value
+	for name, nt := range jsonTypes {
+		if strings.EqualFold(name, t.text) {
+			res = reflect.New(nt)
+			if nf := res.Elem().FieldByName("Name"); nf.IsValid() && nf.Kind() == reflect.String {
+				nf.SetString(name)
+			}
+			break
+		}
+	}
+	if !res.IsValid() {
+		for name, st := range dslStructs {
+			if strings.EqualFold(name, t.text) {
+				res = reflect.New(st)
+				break
+			}
+		}
+	}
+	if !res.IsValid() {
+		return nil, p.errorf(t, "unknown type %s", t.text)
+	}
+
+	v := res.Elem()
+	st := v.Type()
+	set := map[int]bool{}
+	for _, f := range fields {
+		if f.name == "" {
+			return nil, p.errorf(f.tok, "missing field name in %s literal", st.Name())
+		}
+		n := -1
+		for i := range st.NumField() {
+			sf := st.Field(i)
+			if sf.IsExported() && sf.Name != "Name" && strings.EqualFold(sf.Name, f.name) {
+				n = i
+				break
+			}
+		}
+		if n < 0 {
+			return nil, p.errorf(f.ntok, "%s has no field %s", st.Name(), f.name)
+		}
+		if set[n] {
+			return nil, p.errorf(f.ntok, "field %s of %s is set twice", st.Field(n).Name, st.Name())
+		}
+		set[n] = true
+		fv, err := dslConvert(f.v, st.Field(n).Type)
+		if err != nil {
+			return nil, p.errorf(f.tok, "field %s of %s: %v", st.Field(n).Name, st.Name(), err)
+		}
+		v.Field(n).Set(fv)
+	}
+
+	if _, ok := jsonTypes[st.Name()]; ok {
+		if err := restore(v); err != nil {
+			return nil, p.errorf(t, "%v", err)
+		}
+		return res.Interface(), nil
+	}
+	return v.Interface(), nil
+}
+
+// dslConvert converts a DSL value to type t.
+func dslConvert(v any, t reflect.Type) (reflect.Value, error) {
+	if v == nil {
+		return reflect.Zero(t), nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Type().AssignableTo(t) {
+		return rv, nil
+	}
+
+	switch t {
+	case reflect.TypeFor[*g2d.Shape]():
+		// Shapes are made from a path or a list of paths
+		switch x := v.(type) {
+		case *g2d.Path:
+			return reflect.ValueOf(g2d.NewShape(x)), nil
+		case []any:
+			paths := make([]*g2d.Path, len(x))
+			for i, e := range x {
+				p, ok := e.(*g2d.Path)
+				if !ok {
+					return reflect.Value{}, fmt.Errorf("[%d]: can't use %s as a path", i, dslTypeName(e))
+				}
+				paths[i] = p
+			}
+			return reflect.ValueOf(g2d.NewShape(paths...)), nil
+		}
+	case reflect.TypeFor[func(float64) float64]():
+		if nl, ok := v.(*NonLinear); ok {
+			return reflect.ValueOf(nl.Eval0), nil
+		}
+	}
+
+	switch x := v.(type) {
+	case int64:
+		switch t.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return rv.Convert(t), nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if reflect.Zero(t).OverflowInt(x) {
+				return reflect.Value{}, fmt.Errorf("%d overflows %s", x, t)
+			}
+			return rv.Convert(t), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if x < 0 || reflect.Zero(t).OverflowUint(uint64(x)) {
+				return reflect.Value{}, fmt.Errorf("%d overflows %s", x, t)
+			}
+			return rv.Convert(t), nil
+		}
+	case float64:
+		switch t.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return rv.Convert(t), nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if x != math.Trunc(x) {
+				return reflect.Value{}, fmt.Errorf("%v is not an integer", x)
+			}
+			return rv.Convert(t), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if x != math.Trunc(x) || x < 0 {
+				return reflect.Value{}, fmt.Errorf("%v is not an unsigned integer", x)
+			}
+			return rv.Convert(t), nil
+		}
+	case string:
+		if reflect.TypeFor[tcol.FRGBA]().AssignableTo(t) {
+			c, err := ParseCSSColor(x)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(c).Convert(t), nil
+		}
+	case []any:
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array:
+			var res reflect.Value
+			if t.Kind() == reflect.Slice {
+				res = reflect.MakeSlice(t, len(x), len(x))
+			} else if len(x) > t.Len() {
+				return reflect.Value{}, fmt.Errorf("too many elements for %s", t)
+			} else {
+				res = reflect.New(t).Elem()
+			}
+			for i, e := range x {
+				ev, err := dslConvert(e, t.Elem())
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("[%d]: %w", i, err)
+				}
+				res.Index(i).Set(ev)
+			}
+			return res, nil
+		case reflect.Pointer:
+			ev, err := dslConvert(v, t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			res := reflect.New(t.Elem())
+			res.Elem().Set(ev)
+			return res, nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("can't use %s as %s", dslTypeName(v), t)
+}
+
+func dslTypeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "nil"
+	case int64, float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case []any:
+		return "list"
+	}
+	return reflect.TypeOf(v).String()
+}
@@ -0,0 +1,189 @@
+package texture_test
+
+import (
+	"github.com/jphsd/graphics2d"
+	"github.com/jphsd/texture"
+	tcol "github.com/jphsd/texture/color"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+)
+
+// sameField returns false if f1 and f2 differ anywhere on a grid of points.
+func sameField(f1, f2 texture.Field) bool {
+	for y := range 16 {
+		for x := range 16 {
+			fx, fy := float64(x)*13.5-50, float64(y)*9.25-30
+			if f1.Eval2(fx, fy) != f2.Eval2(fx, fy) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestParseDSLShorthands(t *testing.T) {
+	v, err := texture.ParseDSL("fbm(perlin(seed=3), octaves=6) * radial(wave=sin(60))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lac := 2.0
+	w := texture.NewNLWave([]float64{60}, []*texture.NonLinear{texture.NewNLSin()}, true, false)
+	want := texture.NewMulCombiner(
+		texture.NewFractal(texture.NewPerlin(3), graphics2d.Scale(lac, lac), texture.NewFBM(1, lac, 7), 6),
+		texture.NewRadialGradient(w))
+	f, ok := v.(texture.Field)
+	if !ok || !sameField(f, want) {
+		t.Errorf("shorthand tree %#v doesn't match", v)
+	}
+
+	// fbm is the constructor unless its first argument is a field
+	if v, err := texture.ParseDSL("fbm(0.5, 2, 6)"); err != nil {
+		t.Error(err)
+	} else if _, ok := v.(*texture.FBM); !ok {
+		t.Errorf("fbm(0.5, 2, 6) made %T", v)
+	}
+}
+
+func TestParseDSLShapes(t *testing.T) {
+	tests := []struct {
+		src  string
+		want texture.Field
+	}{
+		{"Shape(ReentrantPolygon([0, 0], 50, 5, 0.65, 0), BinaryStyle)",
+			texture.NewShape(graphics2d.NewShape(graphics2d.ReentrantPolygon([]float64{0, 0}, 50, 5, 0.65, 0)),
+				texture.BinaryStyle)},
+		{"Shape([Circle([0, 0], 40), Rectangle([10, 10], 30, 20)], PathOccStyle)",
+			texture.NewShape(graphics2d.NewShape(graphics2d.Circle([]float64{0, 0}, 40),
+				graphics2d.Rectangle([]float64{10, 10}, 30, 20)), texture.PathOccStyle)},
+		{"BlinnField([[0, 0], [40, 20]], [-0.001, -0.002], [1, 0.5], nil, nil, 1, -0.5)",
+			texture.NewBlinnField([][]float64{{0, 0}, {40, 20}}, []float64{-0.001, -0.002}, []float64{1, 0.5},
+				func(a, b []float64) float64 { return (a[0]-b[0])*(a[0]-b[0]) + (a[1]-b[1])*(a[1]-b[1]) },
+				math.Exp, 1, -0.5)},
+		{"ShapeCombiner(perlin(3), Uniform(0.5), Circle([0, 0], 40))",
+			texture.NewShapeCombiner(texture.NewPerlin(3), texture.NewUniform(0.5),
+				graphics2d.NewShape(graphics2d.Circle([]float64{0, 0}, 40)))},
+		{"WorleyField([[0, 0], [40, 20], [-30, 10]], nil, [1, -1], DistanceE, NLSquare(), 0.01, 0)",
+			texture.NewWorleyField([][]float64{{0, 0}, {40, 20}, {-30, 10}}, nil, []float64{1, -1},
+				func(a, b []float64) float64 { return math.Hypot(a[0]-b[0], a[1]-b[1]) },
+				texture.NewNLSquare().Eval0, 0.01, 0)},
+	}
+	for _, test := range tests {
+		v, err := texture.ParseDSL(test.src)
+		if err != nil {
+			t.Errorf("%s: %v", test.src, err)
+			continue
+		}
+		if f, ok := v.(texture.Field); !ok || !sameField(f, test.want) {
+			t.Errorf("%s doesn't match its constructor", test.src)
+		}
+	}
+}
+
+func TestParseDSLErrors(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"perlin(3", "1:9: expected ',' or ')'"},
+		{"p = perlin(3)\np = perlin(4)\np", "2:1: p is already bound"},
+		{"perlin(3) * \n  foo", "2:3: undefined: foo"},
+		{"perlin(seed=3, seed=4)", "1:16: parameter seed of Perlin is set twice"},
+		{"perlin(3, 4)", "1:11: too many arguments to Perlin"},
+		{"radialgradient(wf=3)", "1:19: argument wf of RadialGradient"},
+		{"Perlin{Seed: 3, Hash: 1}", "1:17: Perlin has no field Hash"},
+		{"perlin(seed=3, hash=4)", "1:16: Perlin has no parameter hash"},
+		{"x = 1\n\n  lineargradient(\n    nlwave([10], [nlsin()], true, false),\n    2)",
+			"5:5: too many arguments to LinearGradient"},
+		{"Shape([Circle([0, 0], 1), 2], BinaryStyle)", "1:7: argument shape of Shape: [1]"},
+		{"perlin", "1:1: perlin is a function"},
+		{"\n\n", "3:1: missing final expression"},
+	}
+	for _, test := range tests {
+		_, err := texture.ParseDSL(test.src)
+		if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("%q gave %v, expected %s...", test.src, err, test.want)
+		}
+	}
+}
+
+func TestFormatDSLRoundTrip(t *testing.T) {
+	shared := testField()
+	colors := []color.Color{
+		color.NRGBA{255, 0, 0, 128}, tcol.Lab{50, 20, -30, 1}, tcol.OKLCh{0.5, 0.2, 240, 1},
+		tcol.HSV{0.5, 0.25, 0.75, 1},
+	}
+	trees := []any{
+		testColorField(),
+		texture.NewColorConv(shared, colors[0], colors[1], colors[2:], []float64{0.5, 0.75}, texture.LerpOKLab),
+		texture.NewMulCombiner(shared, texture.NewTransform(texture.NewInvertFilter(shared),
+			graphics2d.Rotate(0.5))),
+		testLayerStack(),
+	}
+	for _, tree := range trees {
+		s, err := texture.FormatDSL(tree)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := texture.ParseDSL(s)
+		if err != nil {
+			t.Fatalf("%v in\n%s", err, s)
+		}
+		s2, err := texture.FormatDSL(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != s2 {
+			t.Errorf("formatted text changed from\n%s\nto\n%s", s, s2)
+		}
+		if !sameTree(tree, v) {
+			t.Errorf("reparsed tree differs from\n%s", s)
+		}
+	}
+}
+
+// gauss is a curve with a parameter, for testing the writing of parameterized NonLinears.
+type gauss struct {
+	K float64
+}
+
+func (g *gauss) Transform(t float64) float64    { return math.Exp(-g.K * (t - 1) * (t - 1)) }
+func (g *gauss) InvTransform(v float64) float64 { return 1 - math.Sqrt(-math.Log(v)/g.K) }
+
+func TestFormatDSLErrors(t *testing.T) {
+	shape := graphics2d.NewShape(graphics2d.Circle([]float64{0, 0}, 40))
+	lin := texture.NewLinearGradient(texture.NewNLWave([]float64{20},
+		[]*texture.NonLinear{{Name: "NLGauss", NLF: &gauss{5}}}, false, false))
+	tests := []struct {
+		tree any
+		want string
+	}{
+		{texture.NewShapeCombiner(texture.NewPerlin(3), texture.NewUniform(0.5), shape), "ShapeCombiner can't"},
+		{texture.NewShapeCombinerCF(testColorField(), testColorField(), shape), "ShapeCombinerCF can't"},
+		{texture.NewShapeCombinerVF(nil, nil, shape), "ShapeCombinerVF can't"},
+		{lin, "NLGauss's parameters can't"},
+	}
+	for _, test := range tests {
+		if _, err := texture.FormatDSL(test.tree); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%T: error %v, expected %q", test.tree, err, test.want)
+		}
+	}
+}
+
+// sameTree returns false if the field or color field trees t1 and t2 differ anywhere on a grid of points.
+func sameTree(t1, t2 any) bool {
+	cf1, err1 := texture.ToColorField(t1)
+	cf2, err2 := texture.ToColorField(t2)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	for y := range 16 {
+		for x := range 16 {
+			fx, fy := float64(x)*13.5-50, float64(y)*9.25-30
+			if cf1.Eval2(fx, fy) != cf2.Eval2(fx, fy) {
+				return false
+			}
+		}
+	}
+	return true
+}
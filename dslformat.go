@@ -0,0 +1,309 @@
+package texture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Width in characters beyond which formatted literals and lists are split over several lines.
+const dslWidth = 100
+
+// FormatDSL returns the text of a tree in the texture DSL (see [ParseDSL]). Nodes are written as literals
+// of their non-zero exported fields, except for those made by the operators, and subtrees used more than
+// once are bound to names. As with JSON, nodes whose state isn't captured by their exported fields can't be
+// written. Nor can shapes, or parameterized [NonLinear]s with other than their default parameters, since
+// the literals would read back differently.
+func FormatDSL(tree any) (string, error) {
+	f := &dslFormatter{uses: map[any]int{}, names: map[any]string{}, counts: map[string]int{}}
+	f.count(reflect.ValueOf(tree))
+	root, err := f.format(reflect.ValueOf(tree), 0)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, b := range f.binds {
+		sb.WriteString(b)
+		sb.WriteString("\n")
+	}
+	if len(f.binds) > 0 {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(root.s)
+	sb.WriteString("\n")
+	return sb.String(), nil
+}
+
+type dslFormatter struct {
+	uses   map[any]int    // Number of references to each node
+	names  map[any]string // Bound names of shared nodes
+	counts map[string]int // Number of bindings of each type, for naming
+	binds  []string
+}
+
+// dslText is formatted text with the precedence of its outermost operator.
+type dslText struct {
+	s    string
+	prec int
+}
+
+// Operator precedences
+const (
+	dslSum = iota
+	dslProduct
+	dslUnary
+	dslPrimary
+)
+
+// dslConstNames maps the named constants to their names.
+var dslConstNames = map[any]string{}
+
+func init() {
+	for k, v := range dslConsts {
+		dslConstNames[v] = k
+	}
+}
+
+func dslIsNode(v reflect.Value) bool {
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return false
+	}
+	t := v.Type().Elem()
+	return jsonTypes[t.Name()] == t
+}
+
+// count records the number of references to each node.
+func (f *dslFormatter) count(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if !v.IsNil() {
+			f.count(v.Elem())
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		if dslIsNode(v) {
+			key := v.Interface()
+			f.uses[key]++
+			if f.uses[key] > 1 {
+				return
+			}
+		}
+		f.count(v.Elem())
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				f.count(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			f.count(v.Index(i))
+		}
+	}
+}
+
+func (f *dslFormatter) format(v reflect.Value, indent int) (dslText, error) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return dslText{"nil", dslPrimary}, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return dslText{"nil", dslPrimary}, nil
+		}
+		return f.format(v.Elem(), indent)
+	case reflect.Pointer:
+		if v.IsNil() {
+			return dslText{"nil", dslPrimary}, nil
+		}
+		if nl, ok := v.Interface().(*NonLinear); ok {
+			c, ok := nlConstructors[nl.Name]
+			if !ok {
+				return dslText{}, fmt.Errorf("unknown nonlinear function %q", nl.Name)
+			}
+			// A literal reads back with the default parameters
+			b1, err1 := json.Marshal(nl.NLF)
+			b2, err2 := json.Marshal(c().NLF)
+			if err1 != nil || err2 != nil || !bytes.Equal(b1, b2) {
+				return dslText{}, fmt.Errorf("%s's parameters can't be written in the DSL", nl.Name)
+			}
+			return dslText{nl.Name + "{}", dslPrimary}, nil
+		}
+		if !dslIsNode(v) {
+			return f.format(v.Elem(), indent)
+		}
+		key := v.Interface()
+		if name, ok := f.names[key]; ok {
+			return dslText{name, dslPrimary}, nil
+		}
+		if f.uses[key] < 2 {
+			return f.node(v, indent)
+		}
+		t, err := f.node(v, 0)
+		if err != nil {
+			return dslText{}, err
+		}
+		tn := strings.ToLower(v.Type().Elem().Name())
+		f.counts[tn]++
+		name := fmt.Sprintf("%s%d", tn, f.counts[tn])
+		f.binds = append(f.binds, fmt.Sprintf("%s = %s", name, t.s))
+		f.names[key] = name
+		return dslText{name, dslPrimary}, nil
+	case reflect.Struct:
+		return f.literal(v.Type().Name(), v, indent)
+	case reflect.Slice, reflect.Array:
+		elts := make([]string, v.Len())
+		fill := true
+		for i := range elts {
+			t, err := f.format(v.Index(i), indent+1)
+			if err != nil {
+				return dslText{}, err
+			}
+			elts[i] = t.s
+			fill = fill && !strings.ContainsAny(t.s, "{\n")
+		}
+		return dslText{dslJoin("[", "]", elts, indent, fill), dslPrimary}, nil
+	case reflect.Float32, reflect.Float64:
+		x := v.Float()
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			return dslText{}, fmt.Errorf("can't write %v", x)
+		}
+		prec := dslPrimary
+		if x < 0 {
+			prec = dslUnary
+		}
+		return dslText{strconv.FormatFloat(x, 'g', -1, v.Type().Bits()), prec}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if name, ok := dslConstNames[v.Interface()]; ok {
+			return dslText{name, dslPrimary}, nil
+		}
+		prec := dslPrimary
+		if v.Int() < 0 {
+			prec = dslUnary
+		}
+		return dslText{strconv.FormatInt(v.Int(), 10), prec}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return dslText{strconv.FormatUint(v.Uint(), 10), dslPrimary}, nil
+	case reflect.Bool:
+		return dslText{strconv.FormatBool(v.Bool()), dslPrimary}, nil
+	case reflect.String:
+		return dslText{strconv.Quote(v.String()), dslPrimary}, nil
+	}
+	return dslText{}, fmt.Errorf("%s can't be written in the DSL", v.Type())
+}
+
+// node formats a node, using the operators for the nodes they make.
+func (f *dslFormatter) node(v reflect.Value, indent int) (dslText, error) {
+	switch n := v.Interface().(type) {
+	case *Image, *Shape, *ShapeCombiner, *ShapeCombinerCF, *ShapeCombinerVF, *BlinnField, *WorleyField:
+		return dslText{}, fmt.Errorf("%s can't be written in the DSL", v.Type().Elem().Name())
+	case *MulCombiner:
+		if reflect.DeepEqual(n, NewMulCombiner(n.Src1, n.Src2)) {
+			return f.binary("*", dslProduct, n.Src1, n.Src2, indent)
+		}
+	case *AddCombiner:
+		if reflect.DeepEqual(n, NewAddCombiner(n.Src1, n.Src2)) {
+			return f.binary("+", dslSum, n.Src1, n.Src2, indent)
+		}
+	case *SubCombiner:
+		if reflect.DeepEqual(n, NewSubCombiner(n.Src1, n.Src2)) {
+			return f.binary("-", dslSum, n.Src1, n.Src2, indent)
+		}
+	case *InvertFilter:
+		if n.Src != nil {
+			t, err := f.operand(n.Src, dslUnary, false, indent)
+			if err != nil {
+				return dslText{}, err
+			}
+			return dslText{"-" + t, dslUnary}, nil
+		}
+	}
+	return f.literal(v.Type().Elem().Name(), v.Elem(), indent)
+}
+
+func (f *dslFormatter) binary(op string, prec int, x, y Field, indent int) (dslText, error) {
+	if x == nil || y == nil {
+		return dslText{}, fmt.Errorf("missing operand for %s", op)
+	}
+	// Lift one of a pair of unshared uniforms to a number
+	_, xu := x.(*Uniform)
+	_, yu := y.(*Uniform)
+	s1, err := f.operand(x, prec, xu && !yu, indent)
+	if err != nil {
+		return dslText{}, err
+	}
+	s2, err := f.operand(y, prec+1, yu && !xu, indent)
+	if err != nil {
+		return dslText{}, err
+	}
+	return dslText{s1 + " " + op + " " + s2, prec}, nil
+}
+
+// operand formats an operand, parenthesized if its precedence is below prec. A Uniform is written as its
+// value if lift is set and it isn't shared.
+func (f *dslFormatter) operand(x Field, prec int, lift bool, indent int) (string, error) {
+	v := reflect.ValueOf(x)
+	if u, ok := x.(*Uniform); ok && lift && f.uses[x] < 2 && reflect.DeepEqual(u, NewUniform(u.Value)) {
+		v = reflect.ValueOf(u.Value)
+	}
+	t, err := f.format(v, indent)
+	if err != nil {
+		return "", err
+	}
+	if t.prec < prec {
+		return "(" + t.s + ")", nil
+	}
+	return t.s, nil
+}
+
+// literal formats a struct as a literal of its non-zero exported fields.
+func (f *dslFormatter) literal(name string, v reflect.Value, indent int) (dslText, error) {
+	var fields []string
+	st := v.Type()
+	for i := range st.NumField() {
+		sf := st.Field(i)
+		if !sf.IsExported() || sf.Name == "Name" || v.Field(i).IsZero() {
+			continue
+		}
+		t, err := f.format(v.Field(i), indent+1)
+		if err != nil {
+			return dslText{}, fmt.Errorf("%s.%s: %w", name, sf.Name, err)
+		}
+		fields = append(fields, sf.Name+": "+t.s)
+	}
+	return dslText{dslJoin(name+"{", "}", fields, indent, false), dslPrimary}, nil
+}
+
+// dslJoin joins the elements with commas between open and close, on one line if it fits or else split over
+// several lines. If fill is set the lines are filled, otherwise there's one element per line.
+func dslJoin(open, close string, elts []string, indent int, fill bool) string {
+	s := open + strings.Join(elts, ", ") + close
+	if indent*4+len(s) <= dslWidth && !strings.Contains(s, "\n") {
+		return s
+	}
+	var sb strings.Builder
+	sb.WriteString(open)
+	tabs := "\n" + strings.Repeat("\t", indent+1)
+	n := dslWidth
+	for _, e := range elts {
+		if !fill || n+len(e)+2 > dslWidth {
+			sb.WriteString(tabs)
+			n = (indent + 1) * 4
+		} else {
+			sb.WriteString(" ")
+			n++
+		}
+		sb.WriteString(e)
+		sb.WriteString(",")
+		n += len(e) + 1
+	}
+	sb.WriteString("\n")
+	sb.WriteString(strings.Repeat("\t", indent))
+	sb.WriteString(close)
+	return sb.String()
+}
@@ -0,0 +1,371 @@
+package texture
+
+import (
+	g2d "github.com/jphsd/graphics2d"
+	g2dcol "github.com/jphsd/graphics2d/color"
+	"github.com/jphsd/graphics2d/util"
+	tcol "github.com/jphsd/texture/color"
+	"image/color"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// dslFunc is a function that can be called from the DSL, with the names of its parameters.
+type dslFunc struct {
+	name   string
+	fn     any
+	params string
+}
+
+// dslAlts holds the shorthands sharing a name with a constructor, keyed by their lower cased names. They're
+// called instead of the constructor when their first argument is a field.
+var dslAlts = map[string]*dslFunc{}
+
+// pick returns the function to call with args.
+func (f *dslFunc) pick(args []dslArg) *dslFunc {
+	if alt, ok := dslAlts[strings.ToLower(f.name)]; ok && len(args) > 0 {
+		if _, ok := args[0].v.(Field); ok {
+			return alt
+		}
+	}
+	return f
+}
+
+// dslFuncs holds the DSL functions keyed by their lower cased names.
+var dslFuncs = map[string]*dslFunc{}
+
+func init() {
+	// The package constructors
+	for _, f := range []*dslFunc{
+		{"Binary", NewBinary, "width height seed perc"},
+		{"BlinnField", dslBlinnField, "points a b d f scale offset"},
+		{"WorleyField", dslWorleyField, "points a b d f scale offset"},
+		{"Shape", NewShape, "shape style"},
+		{"ShapeCombiner", NewShapeCombiner, "src1 src2 shape"},
+		{"ShapeCombinerCF", NewShapeCombinerCF, "src1 src2 shape"},
+		{"ShapeCombinerVF", NewShapeCombinerVF, "src1 src2 shape"},
+		{"BlockNoise", NewBlockNoise, "w h r c d"},
+		{"Cache", NewCache, "src resolution limit"},
+		{"Triangles", NewTriangles, "s"},
+		{"Squares", NewSquares, "s"},
+		{"Hexagons", NewHexagons, "s"},
+		{"ColorGray", NewColorGray, "src"},
+		{"ColorSinCos", NewColorSinCos, "src mode hsl"},
+		{"ColorConv", NewColorConv, "src start end cols tvals lerp"},
+		{"ColorFields", NewColorFields, "src1 src2 src3 src4 hsl"},
+		{"ColorFieldsSpace", NewColorFieldsSpace, "src1 src2 src3 src4 space"},
+		{"ColorVector", NewColorVector, "src hsl"},
+		{"ColorBlend", NewColorBlend, "src1 src2 src3 lerp"},
+		{"ColorModeBlend", NewColorModeBlend, "src1 src2 src3 mode"},
+		{"ColorComposite", NewColorComposite, "src1 src2 op"},
+		{"ColorAlpha", NewColorAlpha, "src1 src2"},
+		{"ColorSubstitute", NewColorSubstitute, "src1 src2 src3 a b"},
+		{"ColorLevels", NewColorLevels, "src inb inw gamma outb outw mod"},
+		{"ColorCurves", NewColorCurves, "src r g b mod"},
+		{"ColorHSLAdjust", NewColorHSLAdjust, "src hue sat light mod"},
+		{"ColorBalance", NewColorBalance, "src shadows midtones highlights preserve mod"},
+		{"ColorMixer", NewColorMixer, "src mix mod"},
+		{"ColorInvert", NewColorInvert, "src mod"},
+		{"ColorTint", NewColorTint, "src tint mod"},
+		{"MulCombiner", NewMulCombiner, "src1 src2"},
+		{"AddCombiner", NewAddCombiner, "src1 src2"},
+		{"SubCombiner", NewSubCombiner, "src1 src2"},
+		{"MinCombiner", NewMinCombiner, "src1 src2"},
+		{"MaxCombiner", NewMaxCombiner, "src1 src2"},
+		{"AvgCombiner", NewAvgCombiner, "src1 src2"},
+		{"DiffCombiner", NewDiffCombiner, "src1 src2"},
+		{"WindowedCombiner", NewWindowedCombiner, "src1 src2 a b"},
+		{"WeightedCombiner", NewWeightedCombiner, "src1 src2 a b"},
+		{"Blend", NewBlend, "src1 src2 src3"},
+		{"StochasticBlend", NewStochasticBlend, "src1 src2 src3"},
+		{"JitterBlend", NewJitterBlend, "src1 src2 src3 perc"},
+		{"SubstituteCombiner", NewSubstituteCombiner, "src1 src2 src3 a b"},
+		{"ThresholdCombiner", NewThresholdCombiner, "src1 src2 src3 a"},
+		{"Component", NewComponent, "src c1 c2 c3 lerp bscale"},
+		{"Convolution", NewConvolution, "src kern norm"},
+		{"Displace", NewDisplace, "in dx dy scale"},
+		{"Displace2", NewDisplace2, "in dx dy xfmx xfmy"},
+		{"DisplaceVF", NewDisplaceVF, "in dx dy scale"},
+		{"Displace2VF", NewDisplace2VF, "in dx dy xfmx xfmy"},
+		{"DisplaceCF", NewDisplaceCF, "in dx dy scale"},
+		{"Displace2CF", NewDisplace2CF, "in dx dy xfmx xfmy"},
+		{"Distort", NewDistort, "src dist"},
+		{"NLFilter", NewNLFilter, "src nlf a b"},
+		{"InvertFilter", NewInvertFilter, "src"},
+		{"QuantizeFilter", NewQuantizeFilter, "src a b c"},
+		{"ClipFilter", NewClipFilter, "src a b"},
+		{"OffsScaleFilter", NewOffsScaleFilter, "src a b"},
+		{"AbsFilter", NewAbsFilter, "src a b"},
+		{"FoldFilter", NewFoldFilter, "src a b"},
+		{"RandQuantFilter", NewRandQuantFilter, "src a b c"},
+		{"RemapFilter", NewRemapFilter, "src a b"},
+		{"FloorFilter", NewFloorFilter, "src a b c"},
+		{"CeilFilter", NewCeilFilter, "src a b c"},
+		{"Fractal", NewFractal, "src xfm comb octaves"},
+		{"VariableFractal", NewVariableFractal, "src xfm comb octsrc scale"},
+		{"FBM", NewFBM, "hurst lacunarity maxoct"},
+		{"MF", NewMF, "hurst lacunarity offset maxoct"},
+		{"LinearGradient", NewLinearGradient, "wf"},
+		{"RadialGradient", NewRadialGradient, "wf"},
+		{"ConicGradient", NewConicGradient, "wf"},
+		{"IFS", NewIFS, "dom xfms itr"},
+		{"IFSCombiner", NewIFSCombiner, "src1 src2 dom xfms itr"},
+		{"Image", NewImage, "img interp"},
+		{"Layer", NewLayer, "src mask mode opacity"},
+		{"LayerStack", NewLayerStack, "layers"},
+		{"Erode", NewErode, "src supp"},
+		{"Dilate", NewDilate, "src supp"},
+		{"EdgeIn", NewEdgeIn, "src supp"},
+		{"EdgeOut", NewEdgeOut, "src supp"},
+		{"Edge", NewEdge, "src supp"},
+		{"Close", NewClose, "src supp"},
+		{"Open", NewOpen, "src supp"},
+		{"TopHat", NewTopHat, "src supp"},
+		{"BottomHat", NewBottomHat, "src supp"},
+		{"NLLinear", NewNLLinear, ""},
+		{"NLSquare", NewNLSquare, ""},
+		{"NLCube", NewNLCube, ""},
+		{"NLExponential", NewNLExponential, "v"},
+		{"NLLogarithmic", NewNLLogarithmic, "v"},
+		{"NLSin", NewNLSin, ""},
+		{"NLSin1", NewNLSin1, ""},
+		{"NLSin2", NewNLSin2, ""},
+		{"NLCircle1", NewNLCircle1, ""},
+		{"NLCircle2", NewNLCircle2, ""},
+		{"NLCatenary", NewNLCatenary, ""},
+		{"NLGauss", NewNLGauss, "v"},
+		{"NLLogistic", NewNLLogistic, "u v"},
+		{"NLP3", NewNLP3, ""},
+		{"NLP5", NewNLP5, ""},
+		{"ColorNormalMap", NewColorNormalMap, "src typ"},
+		{"NormalMap", NewNormalMap, "src typ"},
+		{"NormalMapImage", NewNormalMapImage, "img typ"},
+		{"NormalBlend", NewNormalBlend, "src1 src2 typ"},
+		{"PBRComponent", NewPBRComponent, "c rough metal ao"},
+		{"Perlin", NewPerlin, "seed"},
+		{"Pixelate", NewPixelate, "src resolution"},
+		{"PixelateVF", NewPixelateVF, "src resolution"},
+		{"PixelateCF", NewPixelateCF, "src resolution"},
+		{"ColorQuantize", NewColorQuantize, "src colors dither size"},
+		{"Reflect", NewReflect, "src lp1 lp2"},
+		{"ReflectVF", NewReflectVF, "src lp1 lp2"},
+		{"ReflectCF", NewReflectCF, "src lp1 lp2"},
+		{"Strip", NewStrip, "src y"},
+		{"StripCF", NewStripCF, "src y"},
+		{"StripVF", NewStripVF, "src y"},
+		{"TextureRGBA", NewTextureRGBA, "width height src ox oy dx dy cache"},
+		{"TextureRGBA64", NewTextureRGBA64, "width height src ox oy dx dy cache"},
+		{"TextureGray16", NewTextureGray16, "width height src ox oy dx dy cache"},
+		{"TextureFRGBA", NewTextureFRGBA, "width height src ox oy dx dy cache"},
+		{"TextureFloat", NewTextureFloat, "width height src ox oy dx dy cache"},
+		{"ThresholdFilter", NewThresholdFilter, "src a b c"},
+		{"Tiler", NewTiler, "src dom"},
+		{"TilerCF", NewTilerCF, "src dom"},
+		{"TilerVF", NewTilerVF, "src dom"},
+		{"StochasticTiler", NewStochasticTiler, "srcs dom"},
+		{"StochasticTilerCF", NewStochasticTilerCF, "srcs dom"},
+		{"StochasticTilerVF", NewStochasticTilerVF, "srcs dom"},
+		{"ToneMap", NewToneMap, "src op exposure gamma"},
+		{"ColorHighlights", NewColorHighlights, "src threshold knee"},
+		{"ColorBloom", NewColorBloom, "src threshold knee radius intensity"},
+		{"Transform", NewTransform, "src xfm"},
+		{"TransformVF", NewTransformVF, "src xfm"},
+		{"TransformCF", NewTransformCF, "src xfm"},
+		{"Uniform", NewUniform, "v"},
+		{"UniformCF", NewUniformCF, "v"},
+		{"UniformVF", NewUniformVF, "v"},
+		{"LinearGray16", NewLinearGray16, "w h p1 p2 wf mirror once"},
+		{"RadialGray16", NewRadialGray16, "w h c r wf mirror once"},
+		{"EllipticalGray16", NewEllipticalGray16, "w h c rx ry th wf mirror once"},
+		{"ConicGray16", NewConicGray16, "w h c th wf"},
+		{"LinearRGBA", NewLinearRGBA, "w h p1 p2 c1 c2 wf mirror once"},
+		{"RadialRGBA", NewRadialRGBA, "w h c r c1 c2 wf mirror once"},
+		{"EllipticalRGBA", NewEllipticalRGBA, "w h c rx ry th c1 c2 wf mirror once"},
+		{"ConicRGBA", NewConicRGBA, "w h c th c1 c2 wf"},
+		{"ColorToGray", NewColorToGray, "src"},
+		{"ColorSelect", NewColorSelect, "src ch"},
+		{"Direction", NewDirection, "src"},
+		{"Magnitude", NewMagnitude, "src scale"},
+		{"Select", NewSelect, "src ch scale"},
+		{"Weighted", NewWeighted, "src w"},
+		{"VectorFields", NewVectorFields, "srcs"},
+		{"VectorColor", NewVectorColor, "src"},
+		{"Normal", NewNormal, "src sx sy dx dy"},
+		{"Warp", NewWarp, "src wf"},
+		{"WarpVF", NewWarpVF, "src wf"},
+		{"WarpCF", NewWarpCF, "src wf"},
+		{"RadialWF", NewRadialWF, "c rs cs"},
+		{"SwirlWF", NewSwirlWF, "c s"},
+		{"DrainWF", NewDrainWF, "c s e"},
+		{"RadialNLWF", NewRadialNLWF, "c nl e"},
+		{"PinchXWF", NewPinchXWF, "c i s a"},
+		{"RippleXWF", NewRippleXWF, "l a o"},
+		{"RadialRippleWF", NewRadialRippleWF, "c l a o"},
+		{"RadialWiggleWF", NewRadialWiggleWF, "c l a o"},
+		{"NLWave", NewNLWave, "lambdas nlfs mirror once"},
+		{"DCWave", NewDCWave, "lambdas nlfs once"},
+		{"ACWave", NewACWave, "lambdas nlfs once"},
+		{"PatternWave", NewPatternWave, "lambdas patterns mirror once"},
+		{"InvertWave", NewInvertWave, "src"},
+
+		// Transforms
+		{"Translate", g2d.Translate, "x y"},
+		{"Rotate", g2d.Rotate, "th"},
+		{"RotateAbout", g2d.RotateAbout, "th ax ay"},
+		{"Scale", g2d.Scale, "sx sy"},
+		{"ScaleAbout", g2d.ScaleAbout, "sx sy ax ay"},
+		{"Shear", g2d.Shear, "shx shy"},
+		{"ShearAbout", g2d.ShearAbout, "shx shy ax ay"},
+
+		// Paths, for Shape
+		{"Line", g2d.Line, "pt1 pt2"},
+		{"Polygon", g2d.Polygon, "pts"},
+		{"RegularPolygon", g2d.RegularPolygon, "pt1 pt2 n"},
+		{"ReentrantPolygon", g2d.ReentrantPolygon, "c r n t ang"},
+		{"Rectangle", g2d.Rectangle, "c w h"},
+		{"Circle", g2d.Circle, "c r"},
+		{"Ellipse", g2d.Ellipse, "c rx ry xang"},
+
+		// Shorthands
+		{"fbm", dslFBM, "src octaves hurst lacunarity"},
+		{"mf", dslMF, "src octaves hurst lacunarity offset"},
+		{"linear", NewLinearGradient, "wave"},
+		{"radial", NewRadialGradient, "wave"},
+		{"conic", NewConicGradient, "wave"},
+		{"saw", dslSaw, "lambda"},
+		{"triangle", dslTriangle, "lambda"},
+		{"sin", dslSin, "lambda"},
+	} {
+		k := strings.ToLower(f.name)
+		if _, ok := dslFuncs[k]; ok {
+			dslAlts[k] = f
+		} else {
+			dslFuncs[k] = f
+		}
+	}
+}
+
+// dslFBM returns an FBM Fractal of src. The hurst exponent defaults to 1 and the lacunarity to 2.
+func dslFBM(src Field, octaves, hurst, lacunarity float64) *Fractal {
+	if hurst == 0 {
+		hurst = 1
+	}
+	if lacunarity == 0 {
+		lacunarity = 2
+	}
+	return NewFractal(src, g2d.Scale(lacunarity, lacunarity), NewFBM(hurst, lacunarity, int(octaves+1)), octaves)
+}
+
+// dslMF returns a multifractal Fractal of src, with the same defaults as dslFBM.
+func dslMF(src Field, octaves, hurst, lacunarity, offset float64) *Fractal {
+	if hurst == 0 {
+		hurst = 1
+	}
+	if lacunarity == 0 {
+		lacunarity = 2
+	}
+	return NewFractal(src, g2d.Scale(lacunarity, lacunarity), NewMF(hurst, lacunarity, offset, int(octaves+1)),
+		octaves)
+}
+
+func dslSaw(lambda float64) *NLWave {
+	return NewNLWave([]float64{lambda}, []*NonLinear{NewNLLinear()}, false, false)
+}
+
+func dslTriangle(lambda float64) *NLWave {
+	return NewNLWave([]float64{lambda}, []*NonLinear{NewNLLinear()}, true, false)
+}
+
+func dslSin(lambda float64) *NLWave {
+	return NewNLWave([]float64{lambda}, []*NonLinear{NewNLSin()}, true, false)
+}
+
+// dslBlinnField is NewBlinnField with d defaulting to the distance squared and f to math.Exp.
+func dslBlinnField(points [][]float64, a, b []float64, d func([]float64, []float64) float64,
+	f func(float64) float64, scale, offset float64) *BlinnField {
+	if d == nil {
+		d = util.DistanceESquared
+	}
+	if f == nil {
+		f = math.Exp
+	}
+	return NewBlinnField(points, a, b, d, f, scale, offset)
+}
+
+// dslWorleyField is NewWorleyField with d defaulting to the Euclidean distance and f to the identity.
+func dslWorleyField(points [][]float64, a, b []float64, d func([]float64, []float64) float64,
+	f func(float64) float64, scale, offset float64) *WorleyField {
+	if d == nil {
+		d = util.DistanceE
+	}
+	if f == nil {
+		f = func(v float64) float64 { return v }
+	}
+	return NewWorleyField(points, a, b, d, f, scale, offset)
+}
+
+// dslFuncValues holds the functions that can be passed to BlinnField and WorleyField.
+var dslFuncValues = map[string]any{
+	"DistanceE":        util.DistanceE,
+	"DistanceESquared": util.DistanceESquared,
+	"Exp":              math.Exp,
+}
+
+// dslConsts holds the named constants that can be used in the DSL.
+var dslConsts = map[string]any{
+	"LerpRGBA": LerpRGBA, "LerpHSL": LerpHSL, "LerpHSLs": LerpHSLs, "LerpLinearRGB": LerpLinearRGB,
+	"LerpHSV": LerpHSV, "LerpLab": LerpLab, "LerpLCh": LerpLCh, "LerpOKLab": LerpOKLab, "LerpOKLCh": LerpOKLCh,
+
+	"LinearSegment": LinearSegment, "CurvedSegment": CurvedSegment, "SineSegment": SineSegment,
+	"SphereIncSegment": SphereIncSegment, "SphereDecSegment": SphereDecSegment, "StepSegment": StepSegment,
+	"RGBColoring": RGBColoring, "HSVCCWColoring": HSVCCWColoring, "HSVCWColoring": HSVCWColoring,
+
+	"NearestInterp": NearestInterp, "LinearInterp": LinearInterp, "CubicInterp": CubicInterp,
+	"P3Interp": P3Interp, "P5Interp": P5Interp,
+
+	"OpenGLNormalMap": OpenGLNormalMap, "DirectXNormalMap": DirectXNormalMap,
+	"WhiteoutBlend": WhiteoutBlend, "RNMBlend": RNMBlend, "UDNBlend": UDNBlend,
+
+	"NoDither": NoDither, "OrderedDither": OrderedDither, "DiffusionDither": DiffusionDither,
+
+	"ClipToneMap": ClipToneMap, "ReinhardToneMap": ReinhardToneMap, "ACESToneMap": ACESToneMap,
+	"FilmicToneMap": FilmicToneMap,
+
+	"RGBSpace": tcol.RGBSpace, "LinearRGBSpace": tcol.LinearRGBSpace, "HSVSpace": tcol.HSVSpace,
+	"HSLSpace": tcol.HSLSpace, "LabSpace": tcol.LabSpace, "LChSpace": tcol.LChSpace,
+	"OKLabSpace": tcol.OKLabSpace, "OKLChSpace": tcol.OKLChSpace,
+
+	"NormalBlend": tcol.NormalBlend, "MultiplyBlend": tcol.MultiplyBlend, "ScreenBlend": tcol.ScreenBlend,
+	"OverlayBlend": tcol.OverlayBlend, "SoftLightBlend": tcol.SoftLightBlend,
+	"HardLightBlend": tcol.HardLightBlend, "ColorDodgeBlend": tcol.ColorDodgeBlend,
+	"ColorBurnBlend": tcol.ColorBurnBlend, "LinearLightBlend": tcol.LinearLightBlend,
+	"DifferenceBlend": tcol.DifferenceBlend, "ExclusionBlend": tcol.ExclusionBlend, "HueBlend": tcol.HueBlend,
+	"SaturationBlend": tcol.SaturationBlend, "ColorBlend": tcol.ColorBlend,
+	"LuminosityBlend": tcol.LuminosityBlend,
+
+	"BinaryStyle": BinaryStyle, "PathSumStyle": PathSumStyle, "PathOccStyle": PathOccStyle,
+
+	"OverOp": tcol.OverOp, "InOp": tcol.InOp, "OutOp": tcol.OutOp, "AtopOp": tcol.AtopOp, "XorOp": tcol.XorOp,
+}
+
+// dslStructs holds the struct types, other than nodes, that can be written as literals in the DSL.
+var dslStructs = map[string]reflect.Type{
+	"GradientSegment": reflect.TypeFor[GradientSegment](),
+	"FRGBA":           reflect.TypeFor[tcol.FRGBA](),
+	"HSV":             reflect.TypeFor[tcol.HSV](),
+	"HSL":             reflect.TypeFor[g2dcol.HSL](),
+	"LinearRGBA":      reflect.TypeFor[tcol.LinearRGBA](),
+	"Lab":             reflect.TypeFor[tcol.Lab](),
+	"LCh":             reflect.TypeFor[tcol.LCh](),
+	"OKLab":           reflect.TypeFor[tcol.OKLab](),
+	"OKLCh":           reflect.TypeFor[tcol.OKLCh](),
+	"RGBA":            reflect.TypeFor[color.RGBA](),
+	"NRGBA":           reflect.TypeFor[color.NRGBA](),
+	"RGBA64":          reflect.TypeFor[color.RGBA64](),
+	"NRGBA64":         reflect.TypeFor[color.NRGBA64](),
+	"Gray":            reflect.TypeFor[color.Gray](),
+	"Gray16":          reflect.TypeFor[color.Gray16](),
+	"Alpha":           reflect.TypeFor[color.Alpha](),
+	"Alpha16":         reflect.TypeFor[color.Alpha16](),
+}
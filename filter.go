@@ -175,6 +175,7 @@ func (f *FoldFilter) Eval2(x, y float64) float64 {
 
 // RandQuantFilter supports a randomized quatization filter.
 type RandQuantFilter struct {
+	Name string
 	Src  Field
 	A, B float64
 	C    int
@@ -183,6 +184,12 @@ type RandQuantFilter struct {
 
 // NewRandQuantFilter returns a new RandFilter instance for use in quantization.
 func NewRandQuantFilter(src Field, a, b float64, c int) *RandQuantFilter {
+	return NewRandQuantFilterRand(src, a, b, c, nil)
+}
+
+// NewRandQuantFilterRand is NewRandQuantFilter with the levels shuffled by r, or by the global source
+// if r is nil.
+func NewRandQuantFilterRand(src Field, a, b float64, c int, r *rand.Rand) *RandQuantFilter {
 	if c < 2 {
 		c = 2
 	}
@@ -192,8 +199,12 @@ func NewRandQuantFilter(src Field, a, b float64, c int) *RandQuantFilter {
 	for i := 1; i < c; i++ {
 		mm[i] = clamp(mm[i-1] + dx)
 	}
-	rand.Shuffle(c, func(i, j int) { mm[i], mm[j] = mm[j], mm[i] })
-	return &RandQuantFilter{src, a, b, c, mm}
+	shuffle := rand.Shuffle
+	if r != nil {
+		shuffle = r.Shuffle
+	}
+	shuffle(c, func(i, j int) { mm[i], mm[j] = mm[j], mm[i] })
+	return &RandQuantFilter{"RandQuantFilter", src, a, b, c, mm}
 }
 
 // Eval2 implements the Field interface.
@@ -6,9 +6,7 @@ require (
 	github.com/jphsd/datastruct v0.0.0-20230819004359-fdb942b23eca
 	github.com/jphsd/graphics2d v0.0.0-20250710212629-6bce14f9f02a
 	github.com/jphsd/nonlinear v0.0.0-20250710220814-5a1984037da0
+	golang.org/x/image v0.28.0
 )
 
-require (
-	golang.org/x/image v0.28.0 // indirect
-	golang.org/x/text v0.26.0 // indirect
-)
+require golang.org/x/text v0.26.0 // indirect
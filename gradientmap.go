@@ -0,0 +1,475 @@
+package texture
+
+import (
+	"bufio"
+	"fmt"
+	tcol "github.com/jphsd/texture/color"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// GradientBlend defines how the position within a gradient segment is mapped to the blend factor.
+type GradientBlend int
+
+// Constants for gradient blend types, as used by GIMP.
+const (
+	LinearSegment GradientBlend = iota
+	CurvedSegment
+	SineSegment
+	SphereIncSegment
+	SphereDecSegment
+	StepSegment
+)
+
+// GradientColoring defines how colors are interpolated within a gradient segment.
+type GradientColoring int
+
+// Constants for gradient coloring types, as used by GIMP.
+const (
+	RGBColoring GradientColoring = iota
+	HSVCCWColoring
+	HSVCWColoring
+)
+
+// GradientSegment describes a section of a gradient between Left and Right [0,1] with Mid being the
+// location where the blend factor is 0.5.
+type GradientSegment struct {
+	Left, Mid, Right float64
+	LeftColor        tcol.FRGBA
+	RightColor       tcol.FRGBA
+	Blend            GradientBlend
+	Coloring         GradientColoring
+}
+
+// Gradient is an ordered list of segments spanning [0,1], such as is loaded from a GIMP .ggr file or
+// a CSS gradient definition.
+type Gradient struct {
+	Name     string
+	Segments []GradientSegment
+}
+
+// Lerp returns the color at t [0,1].
+func (g *Gradient) Lerp(t float64) color.Color {
+	n := len(g.Segments)
+	if n == 0 {
+		return color.Transparent
+	}
+	t = bcclamp(t)
+	var s GradientSegment
+	for _, s = range g.Segments {
+		if t <= s.Right {
+			break
+		}
+	}
+	return s.lerp(t)
+}
+
+// ColorConv returns a [ColorConv] that maps the values from src to the gradient. Each segment is
+// approximated with n linear steps (n is ignored for linear RGB segments).
+func (g *Gradient) ColorConv(src Field, n int) *ColorConv {
+	cols, tvals := g.ColorsTVals(n)
+	last := len(cols) - 1
+	return NewColorConv(src, cols[0], cols[last], cols[1:last], tvals[1:last], LerpRGBA)
+}
+
+// ColorsTVals samples the gradient into colors and t values [0,1] suitable for [NewColorConv]. Each
+// segment is approximated with n linear steps (n is ignored for linear RGB segments).
+func (g *Gradient) ColorsTVals(n int) ([]color.Color, []float64) {
+	if n < 1 {
+		n = 1
+	}
+	cols, tvals := []color.Color{}, []float64{}
+	for _, s := range g.Segments {
+		w := s.Right - s.Left
+		if w <= 0 {
+			continue
+		}
+		sn := n
+		if s.Blend == LinearSegment && s.Coloring == RGBColoring && math.Abs(s.Mid-(s.Left+s.Right)/2) < 0.000001 {
+			sn = 1
+		}
+		for i := 0; i <= sn; i++ {
+			t := s.Left + float64(i)/float64(sn)*w
+			cols = append(cols, s.lerp(t))
+			tvals = append(tvals, t)
+		}
+	}
+	if len(cols) == 0 {
+		return []color.Color{color.Transparent, color.Transparent}, []float64{0, 1}
+	}
+	return cols, tvals
+}
+
+// lerp returns the color at t within the segment.
+func (s GradientSegment) lerp(t float64) color.Color {
+	w := s.Right - s.Left
+	var pos, mid float64
+	if w > 0 {
+		pos = bcclamp((t - s.Left) / w)
+		mid = (s.Mid - s.Left) / w
+	}
+	mid = math.Min(math.Max(mid, 0.000001), 1-0.000001)
+
+	// Blend factor, see GIMP's gimpgradient.c
+	var f float64
+	switch s.Blend {
+	default:
+		fallthrough
+	case LinearSegment:
+		f = linearSegment(pos, mid)
+	case CurvedSegment:
+		f = math.Pow(pos, math.Log(0.5)/math.Log(mid))
+	case SineSegment:
+		f = (math.Sin(-math.Pi/2+math.Pi*linearSegment(pos, mid)) + 1) / 2
+	case SphereIncSegment:
+		f = linearSegment(pos, mid) - 1
+		f = math.Sqrt(1 - f*f)
+	case SphereDecSegment:
+		f = linearSegment(pos, mid)
+		f = 1 - math.Sqrt(1-f*f)
+	case StepSegment:
+		if pos >= mid {
+			f = 1
+		}
+	}
+
+	c1, c2 := s.LeftColor, s.RightColor
+	a := lerp(f, c1.A, c2.A)
+	if s.Coloring == RGBColoring {
+		return tcol.FRGBA{lerp(f, c1.R, c2.R), lerp(f, c1.G, c2.G), lerp(f, c1.B, c2.B), a}
+	}
+	h1, h2 := tcol.NewHSV(tcol.FRGBA{c1.R, c1.G, c1.B, 1}), tcol.NewHSV(tcol.FRGBA{c2.R, c2.G, c2.B, 1})
+	d := h2.H - h1.H
+	if s.Coloring == HSVCCWColoring {
+		if d < 0 {
+			d += 1
+		}
+	} else if d > 0 {
+		d -= 1
+	}
+	h := h1.H + d*f
+	h -= math.Floor(h)
+	return tcol.NewFRGBA(tcol.HSV{h, lerp(f, h1.S, h2.S), lerp(f, h1.V, h2.V), a})
+}
+
+func linearSegment(pos, mid float64) float64 {
+	if pos <= mid {
+		return 0.5 * pos / mid
+	}
+	return 0.5 + 0.5*(pos-mid)/(1-mid)
+}
+
+// ReadGGR reads a gradient in GIMP's .ggr format.
+func ReadGGR(r io.Reader) (*Gradient, error) {
+	scanner := bufio.NewScanner(r)
+	lines := []string{}
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) < 2 || lines[0] != "GIMP Gradient" {
+		return nil, fmt.Errorf("not a GIMP gradient")
+	}
+	lines = lines[1:]
+
+	g := &Gradient{}
+	if name, ok := strings.CutPrefix(lines[0], "Name:"); ok {
+		g.Name = strings.TrimSpace(name)
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("missing segment count")
+	}
+	n, err := strconv.Atoi(lines[0])
+	if err != nil {
+		return nil, fmt.Errorf("bad segment count: %w", err)
+	}
+	lines = lines[1:]
+	if len(lines) < n {
+		return nil, fmt.Errorf("expected %d segments, found %d", n, len(lines))
+	}
+
+	for i := 0; i < n; i++ {
+		fields := strings.Fields(lines[i])
+		if len(fields) < 13 {
+			return nil, fmt.Errorf("segment %d: expected at least 13 values, found %d", i, len(fields))
+		}
+		v := make([]float64, 11)
+		for j := range v {
+			if v[j], err = strconv.ParseFloat(fields[j], 64); err != nil {
+				return nil, fmt.Errorf("segment %d: %w", i, err)
+			}
+		}
+		blend, err := strconv.Atoi(fields[11])
+		if err != nil {
+			return nil, fmt.Errorf("segment %d: %w", i, err)
+		}
+		coloring, err := strconv.Atoi(fields[12])
+		if err != nil {
+			return nil, fmt.Errorf("segment %d: %w", i, err)
+		}
+		g.Segments = append(g.Segments, GradientSegment{
+			v[0], v[1], v[2],
+			tcol.FRGBA{v[3], v[4], v[5], v[6]},
+			tcol.FRGBA{v[7], v[8], v[9], v[10]},
+			GradientBlend(blend), GradientColoring(coloring)})
+	}
+	return g, nil
+}
+
+// ParseCSSGradient parses a CSS linear-gradient, radial-gradient or conic-gradient definition, or just
+// its list of color stops, into a gradient. Only the color stops are used - any direction, shape or
+// position arguments are ignored. Stop positions must be percentages and missing ones are distributed
+// evenly as per the CSS specification. Hex, rgb(), rgba(), hsl(), hsla() and named colors are supported.
+func ParseCSSGradient(s string) (*Gradient, error) {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "("); i >= 0 && strings.HasSuffix(s[:i], "gradient") {
+		s = strings.TrimSuffix(s[i+1:], ")")
+	}
+	args := splitCSS(s, ',')
+	if len(args) > 0 && !isCSSStop(args[0]) {
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		return nil, fmt.Errorf("a gradient needs at least two color stops")
+	}
+
+	// Expand stops with two positions into two stops
+	cols, pos := []tcol.FRGBA{}, []float64{}
+	for _, arg := range args {
+		parts := splitCSS(arg, ' ')
+		if len(parts) == 0 || len(parts) > 3 {
+			return nil, fmt.Errorf("bad color stop %q", arg)
+		}
+		c, err := ParseCSSColor(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		if len(parts) == 1 {
+			cols, pos = append(cols, c), append(pos, math.NaN())
+			continue
+		}
+		for _, p := range parts[1:] {
+			v, err := parseCSSPercent(p)
+			if err != nil {
+				return nil, err
+			}
+			cols, pos = append(cols, c), append(pos, v)
+		}
+	}
+
+	// Fix up missing and out of order positions
+	n := len(pos)
+	if math.IsNaN(pos[0]) {
+		pos[0] = 0
+	}
+	if math.IsNaN(pos[n-1]) {
+		pos[n-1] = 1
+	}
+	for i := 1; i < n; i++ {
+		if math.IsNaN(pos[i]) {
+			j := i + 1
+			for math.IsNaN(pos[j]) {
+				j++
+			}
+			for k := i; k < j; k++ {
+				pos[k] = pos[i-1] + float64(k-i+1)/float64(j-i+1)*(pos[j]-pos[i-1])
+			}
+		}
+		if pos[i] < pos[i-1] {
+			pos[i] = pos[i-1]
+		}
+	}
+
+	g := &Gradient{}
+	if pos[0] > 0 {
+		g.Segments = append(g.Segments, cssSegment(0, pos[0], cols[0], cols[0]))
+	}
+	for i := 1; i < n; i++ {
+		g.Segments = append(g.Segments, cssSegment(pos[i-1], pos[i], cols[i-1], cols[i]))
+	}
+	if pos[n-1] < 1 {
+		g.Segments = append(g.Segments, cssSegment(pos[n-1], 1, cols[n-1], cols[n-1]))
+	}
+	return g, nil
+}
+
+func cssSegment(l, r float64, lc, rc tcol.FRGBA) GradientSegment {
+	return GradientSegment{l, (l + r) / 2, r, lc, rc, LinearSegment, RGBColoring}
+}
+
+// isCSSStop returns true if the argument starts with a color rather than a direction or shape.
+func isCSSStop(arg string) bool {
+	parts := splitCSS(arg, ' ')
+	if len(parts) == 0 {
+		return false
+	}
+	_, err := ParseCSSColor(parts[0])
+	return err == nil
+}
+
+// splitCSS splits s on sep, ignoring separators within parentheses, and trims the parts.
+func splitCSS(s string, sep rune) []string {
+	res := []string{}
+	depth, start := 0, 0
+	add := func(p string) {
+		if p = strings.TrimSpace(p); p != "" {
+			res = append(res, p)
+		}
+	}
+	for i, r := range s {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == sep && depth == 0:
+			add(s[start:i])
+			start = i + 1
+		}
+	}
+	add(s[start:])
+	return res
+}
+
+func parseCSSPercent(s string) (float64, error) {
+	v, ok := strings.CutSuffix(s, "%")
+	if !ok {
+		return 0, fmt.Errorf("unsupported stop position %q, only percentages are supported", s)
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad stop position %q", s)
+	}
+	return f / 100, nil
+}
+
+// ParseCSSColor parses a CSS hex, rgb(), rgba(), hsl(), hsla() or named color.
+func ParseCSSColor(s string) (tcol.FRGBA, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if hex, ok := strings.CutPrefix(s, "#"); ok {
+		return parseHexColor(hex)
+	}
+	if i := strings.Index(s, "("); i > 0 && strings.HasSuffix(s, ")") {
+		fn, body := s[:i], s[i+1:len(s)-1]
+		body = strings.ReplaceAll(body, "/", " ")
+		body = strings.ReplaceAll(body, ",", " ")
+		args := strings.Fields(body)
+		if len(args) < 3 || len(args) > 4 {
+			return tcol.FRGBA{}, fmt.Errorf("bad color %q", s)
+		}
+		v := []float64{0, 0, 0, 1}
+		for j, arg := range args {
+			var err error
+			if v[j], err = parseCSSValue(arg, fn, j); err != nil {
+				return tcol.FRGBA{}, fmt.Errorf("bad color %q", s)
+			}
+		}
+		switch fn {
+		case "rgb", "rgba":
+			return tcol.FRGBA{bcclamp(v[0]), bcclamp(v[1]), bcclamp(v[2]), bcclamp(v[3])}, nil
+		case "hsl", "hsla":
+			h := v[0] - math.Floor(v[0])
+			return tcol.NewFRGBA(tcol.HSL{h, bcclamp(v[1]), bcclamp(v[2]), bcclamp(v[3])}), nil
+		}
+		return tcol.FRGBA{}, fmt.Errorf("unsupported color function %q", fn)
+	}
+	if hex, ok := cssNamedColors[s]; ok {
+		return parseHexColor(hex)
+	}
+	return tcol.FRGBA{}, fmt.Errorf("unknown color %q", s)
+}
+
+// parseCSSValue parses the ith argument of a CSS color function, returning it in [0,1].
+func parseCSSValue(arg, fn string, i int) (float64, error) {
+	if v, ok := strings.CutSuffix(arg, "%"); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		return f / 100, err
+	}
+	if i == 0 && strings.HasPrefix(fn, "hsl") {
+		v := strings.TrimSuffix(arg, "deg")
+		f, err := strconv.ParseFloat(v, 64)
+		return f / 360, err
+	}
+	f, err := strconv.ParseFloat(arg, 64)
+	if i < 3 && strings.HasPrefix(fn, "rgb") {
+		f /= 255
+	}
+	return f, err
+}
+
+// parseHexColor parses rgb, rgba, rrggbb and rrggbbaa hex colors.
+func parseHexColor(hex string) (tcol.FRGBA, error) {
+	if len(hex) == 3 || len(hex) == 4 {
+		var sb strings.Builder
+		for _, r := range hex {
+			sb.WriteRune(r)
+			sb.WriteRune(r)
+		}
+		hex = sb.String()
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return tcol.FRGBA{}, fmt.Errorf("bad hex color #%s", hex)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return tcol.FRGBA{}, fmt.Errorf("bad hex color #%s", hex)
+	}
+	return tcol.FRGBA{
+		float64(v>>24&0xff) / 0xff,
+		float64(v>>16&0xff) / 0xff,
+		float64(v>>8&0xff) / 0xff,
+		float64(v&0xff) / 0xff}, nil
+}
+
+// CSS Color Module Level 4 named colors.
+var cssNamedColors = map[string]string{
+	"transparent": "00000000",
+	"aliceblue":   "f0f8ff", "antiquewhite": "faebd7", "aqua": "00ffff", "aquamarine": "7fffd4",
+	"azure": "f0ffff", "beige": "f5f5dc", "bisque": "ffe4c4", "black": "000000",
+	"blanchedalmond": "ffebcd", "blue": "0000ff", "blueviolet": "8a2be2", "brown": "a52a2a",
+	"burlywood": "deb887", "cadetblue": "5f9ea0", "chartreuse": "7fff00", "chocolate": "d2691e",
+	"coral": "ff7f50", "cornflowerblue": "6495ed", "cornsilk": "fff8dc", "crimson": "dc143c",
+	"cyan": "00ffff", "darkblue": "00008b", "darkcyan": "008b8b", "darkgoldenrod": "b8860b",
+	"darkgray": "a9a9a9", "darkgreen": "006400", "darkgrey": "a9a9a9", "darkkhaki": "bdb76b",
+	"darkmagenta": "8b008b", "darkolivegreen": "556b2f", "darkorange": "ff8c00", "darkorchid": "9932cc",
+	"darkred": "8b0000", "darksalmon": "e9967a", "darkseagreen": "8fbc8f", "darkslateblue": "483d8b",
+	"darkslategray": "2f4f4f", "darkslategrey": "2f4f4f", "darkturquoise": "00ced1", "darkviolet": "9400d3",
+	"deeppink": "ff1493", "deepskyblue": "00bfff", "dimgray": "696969", "dimgrey": "696969",
+	"dodgerblue": "1e90ff", "firebrick": "b22222", "floralwhite": "fffaf0", "forestgreen": "228b22",
+	"fuchsia": "ff00ff", "gainsboro": "dcdcdc", "ghostwhite": "f8f8ff", "gold": "ffd700",
+	"goldenrod": "daa520", "gray": "808080", "green": "008000", "greenyellow": "adff2f",
+	"grey": "808080", "honeydew": "f0fff0", "hotpink": "ff69b4", "indianred": "cd5c5c",
+	"indigo": "4b0082", "ivory": "fffff0", "khaki": "f0e68c", "lavender": "e6e6fa",
+	"lavenderblush": "fff0f5", "lawngreen": "7cfc00", "lemonchiffon": "fffacd", "lightblue": "add8e6",
+	"lightcoral": "f08080", "lightcyan": "e0ffff", "lightgoldenrodyellow": "fafad2", "lightgray": "d3d3d3",
+	"lightgreen": "90ee90", "lightgrey": "d3d3d3", "lightpink": "ffb6c1", "lightsalmon": "ffa07a",
+	"lightseagreen": "20b2aa", "lightskyblue": "87cefa", "lightslategray": "778899", "lightslategrey": "778899",
+	"lightsteelblue": "b0c4de", "lightyellow": "ffffe0", "lime": "00ff00", "limegreen": "32cd32",
+	"linen": "faf0e6", "magenta": "ff00ff", "maroon": "800000", "mediumaquamarine": "66cdaa",
+	"mediumblue": "0000cd", "mediumorchid": "ba55d3", "mediumpurple": "9370db", "mediumseagreen": "3cb371",
+	"mediumslateblue": "7b68ee", "mediumspringgreen": "00fa9a", "mediumturquoise": "48d1cc", "mediumvioletred": "c71585",
+	"midnightblue": "191970", "mintcream": "f5fffa", "mistyrose": "ffe4e1", "moccasin": "ffe4b5",
+	"navajowhite": "ffdead", "navy": "000080", "oldlace": "fdf5e6", "olive": "808000",
+	"olivedrab": "6b8e23", "orange": "ffa500", "orangered": "ff4500", "orchid": "da70d6",
+	"palegoldenrod": "eee8aa", "palegreen": "98fb98", "paleturquoise": "afeeee", "palevioletred": "db7093",
+	"papayawhip": "ffefd5", "peachpuff": "ffdab9", "peru": "cd853f", "pink": "ffc0cb",
+	"plum": "dda0dd", "powderblue": "b0e0e6", "purple": "800080", "rebeccapurple": "663399",
+	"red": "ff0000", "rosybrown": "bc8f8f", "royalblue": "4169e1", "saddlebrown": "8b4513",
+	"salmon": "fa8072", "sandybrown": "f4a460", "seagreen": "2e8b57", "seashell": "fff5ee",
+	"sienna": "a0522d", "silver": "c0c0c0", "skyblue": "87ceeb", "slateblue": "6a5acd",
+	"slategray": "708090", "slategrey": "708090", "snow": "fffafa", "springgreen": "00ff7f",
+	"steelblue": "4682b4", "tan": "d2b48c", "teal": "008080", "thistle": "d8bfd8",
+	"tomato": "ff6347", "turquoise": "40e0d0", "violet": "ee82ee", "wheat": "f5deb3",
+	"white": "ffffff", "whitesmoke": "f5f5f5", "yellow": "ffff00", "yellowgreen": "9acd32",
+}
@@ -0,0 +1,219 @@
+package texture
+
+import (
+	"fmt"
+	gi "github.com/jphsd/graphics2d/image"
+	"html"
+	"image/color"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Limits on the parameters shown in a node's label.
+const (
+	graphParams   = 4  // Maximum number of parameters
+	graphValueLen = 24 // Maximum length of a parameter value
+	graphListLen  = 4  // Maximum number of list elements
+)
+
+// Graph is a tree as a directed acyclic graph, for export as a Graphviz DOT or Mermaid diagram. Nodes
+// are listed parents first and a shared subtree appears once, with an edge from each of its parents.
+type Graph struct {
+	Nodes []*GraphNode
+	Edges []GraphEdge
+}
+
+// GraphNode is a node of a Graph. Params holds its key parameters, the first few of its non-zero
+// parameters, as name=value. Image is the name of its thumbnail file, if it has one.
+type GraphNode struct {
+	ID     string
+	Type   string
+	Params []string
+	Leaf   bool
+	Image  string
+	Node   any
+}
+
+// GraphEdge links a node to the child in its named input slot.
+type GraphEdge struct {
+	From, To string
+	Slot     string
+}
+
+// NewGraph returns the graph of the tree rooted at tree.
+func NewGraph(tree any) *Graph {
+	g := &Graph{}
+	ids := map[any]string{}
+	Walk(tree, func(n any) bool {
+		gn := &GraphNode{ID: fmt.Sprintf("n%d", len(g.Nodes)+1), Type: graphType(n), Leaf: true, Node: n}
+		ids[n] = gn.ID
+		if nd, ok := n.(Node); ok {
+			for _, p := range nd.Params() {
+				pv := reflect.ValueOf(p.Value)
+				if len(gn.Params) == graphParams || !pv.IsValid() || pv.IsZero() || pv.Kind() == reflect.Func {
+					continue
+				}
+				s := graphValue(pv)
+				if len(s) > graphValueLen {
+					s = s[:graphValueLen-3] + "..."
+				}
+				gn.Params = append(gn.Params, p.Name+"="+s)
+			}
+		}
+		g.Nodes = append(g.Nodes, gn)
+		return true
+	})
+
+	// Walk visits parents first so all the children have IDs by now
+	for _, gn := range g.Nodes {
+		nd, ok := gn.Node.(Node)
+		if !ok {
+			continue
+		}
+		names := childNames(gn.Node)
+		for i, c := range nd.Children() {
+			if c == nil {
+				continue
+			}
+			slot := strconv.Itoa(i)
+			if i < len(names) {
+				slot = names[i]
+			}
+			g.Edges = append(g.Edges, GraphEdge{gn.ID, ids[c], slot})
+			gn.Leaf = false
+		}
+	}
+	return g
+}
+
+// Thumbnails renders each of the leaf fields, vector fields and color fields as a size by size
+// thumbnail, starting at ox, oy with steps of dx and dy, and saves it to prefix followed by the node's
+// ID and .png. The Image of each rendered node is set to the name of its file.
+func (g *Graph) Thumbnails(prefix string, size int, ox, oy, dx, dy float64) error {
+	for _, gn := range g.Nodes {
+		if !gn.Leaf {
+			continue
+		}
+		cf, err := ToColorField(gn.Node)
+		if err != nil {
+			// Waves and the like have no image
+			continue
+		}
+		img := NewTextureRGBA(size, size, cf, ox, oy, dx, dy, false)
+		name := prefix + gn.ID
+		if err := gi.SaveImage(img, name); err != nil {
+			return err
+		}
+		gn.Image = name + ".png"
+	}
+	return nil
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language with HTML-like labels, parents above their
+// children.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph texture {\n")
+	sb.WriteString("\tnode [shape=box, fontname=\"Helvetica\", fontsize=10];\n")
+	sb.WriteString("\tedge [fontname=\"Helvetica\", fontsize=8];\n")
+	for _, gn := range g.Nodes {
+		label := gn.label("<BR/>", "<B>", "</B>")
+		if gn.Image != "" {
+			label = fmt.Sprintf("<TABLE BORDER=\"0\" CELLSPACING=\"0\"><TR><TD><IMG SRC=\"%s\"/></TD></TR>"+
+				"<TR><TD>%s</TD></TR></TABLE>", html.EscapeString(gn.Image), label)
+		}
+		fmt.Fprintf(&sb, "\t%s [label=<%s>];\n", gn.ID, label)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "\t%s -> %s [label=%s];\n", e.From, e.To, strconv.Quote(e.Slot))
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart, parents above their children. Thumbnails are
+// included as img tags, which Mermaid only renders with HTML labels enabled.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	for _, gn := range g.Nodes {
+		label := gn.label("<br/>", "<b>", "</b>")
+		if gn.Image != "" {
+			label = fmt.Sprintf("<img src='%s'/><br/>%s", html.EscapeString(gn.Image), label)
+		}
+		fmt.Fprintf(&sb, "\t%s[\"%s\"]\n", gn.ID, label)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "\t%s -->|\"%s\"| %s\n", e.From, html.EscapeString(e.Slot), e.To)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// label returns the node's type, in bold, and parameters on separate lines. The text is HTML escaped,
+// which also removes any double quotes.
+func (gn *GraphNode) label(br, bold, unbold string) string {
+	parts := []string{bold + html.EscapeString(gn.Type) + unbold}
+	for _, p := range gn.Params {
+		parts = append(parts, html.EscapeString(p))
+	}
+	return strings.Join(parts, br)
+}
+
+// graphType returns the type name of a node.
+func graphType(n any) string {
+	t := reflect.TypeOf(n)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// graphValue returns a short description of a parameter value.
+func graphValue(v reflect.Value) string {
+	if v.Type().Implements(colorType) && v.Kind() != reflect.Interface {
+		if v.Kind() != reflect.Pointer || !v.IsNil() {
+			c := color.NRGBAModel.Convert(v.Interface().(color.Color)).(color.NRGBA)
+			return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+		}
+	}
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return "nil"
+		}
+		if nl, ok := v.Interface().(*NonLinear); ok {
+			return nl.Name
+		}
+		if dslIsNode(v) {
+			return v.Type().Elem().Name()
+		}
+		return graphValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		elts := []string{}
+		for i := range min(v.Len(), graphListLen) {
+			elts = append(elts, graphValue(v.Index(i)))
+		}
+		if v.Len() > graphListLen {
+			elts = append(elts, "...")
+		}
+		return "[" + strings.Join(elts, " ") + "]"
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', 3, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if name, ok := dslConstNames[v.Interface()]; ok {
+			return name
+		}
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.String:
+		return strconv.Quote(v.String())
+	}
+	return v.Type().Name()
+}
@@ -0,0 +1,95 @@
+package texture_test
+
+import (
+	"github.com/jphsd/texture"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGraphDOT(t *testing.T) {
+	tree, _ := testShared()
+	var sb strings.Builder
+	if err := texture.NewGraph(tree).WriteDOT(&sb); err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph texture {
+	node [shape=box, fontname="Helvetica", fontsize=10];
+	edge [fontname="Helvetica", fontsize=8];
+	n1 [label=<<B>MulCombiner</B><BR/>Scal=1>];
+	n2 [label=<<B>Perlin</B><BR/>Seed=7>];
+	n3 [label=<<B>Transform</B><BR/>Xfm=[0.878 -0.479 0 0.479...>];
+	n4 [label=<<B>InvertFilter</B>>];
+	n1 -> n2 [label="Src1"];
+	n1 -> n3 [label="Src2"];
+	n3 -> n4 [label="Src"];
+	n4 -> n2 [label="Src"];
+}
+`
+	if sb.String() != want {
+		t.Errorf("DOT is\n%s\nexpected\n%s", sb.String(), want)
+	}
+}
+
+func TestGraphMermaid(t *testing.T) {
+	tree, _ := testShared()
+	var sb strings.Builder
+	if err := texture.NewGraph(tree).WriteMermaid(&sb); err != nil {
+		t.Fatal(err)
+	}
+	want := `flowchart TD
+	n1["<b>MulCombiner</b><br/>Scal=1"]
+	n2["<b>Perlin</b><br/>Seed=7"]
+	n3["<b>Transform</b><br/>Xfm=[0.878 -0.479 0 0.479..."]
+	n4["<b>InvertFilter</b>"]
+	n1 -->|"Src1"| n2
+	n1 -->|"Src2"| n3
+	n3 -->|"Src"| n4
+	n4 -->|"Src"| n2
+`
+	if sb.String() != want {
+		t.Errorf("Mermaid is\n%s\nexpected\n%s", sb.String(), want)
+	}
+}
+
+func TestGraphThumbnails(t *testing.T) {
+	tree, _ := testShared()
+	cf := texture.NewColorConv(tree, color.NRGBA{255, 0, 0, 255}, color.NRGBA{0, 0, 255, 64}, nil, nil,
+		texture.LerpHSL)
+	g := texture.NewGraph(cf)
+	prefix := filepath.Join(t.TempDir(), "thumb")
+	if err := g.Thumbnails(prefix, 8, 0, 0, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	images := 0
+	for _, gn := range g.Nodes {
+		if gn.Image == "" {
+			continue
+		}
+		images++
+		if !gn.Leaf {
+			t.Errorf("%s %s has a thumbnail", gn.ID, gn.Type)
+		}
+		if _, err := os.Stat(gn.Image); err != nil {
+			t.Error(err)
+		}
+	}
+	// The shared Perlin is the only leaf
+	if images != 1 {
+		t.Errorf("%d thumbnails, expected 1", images)
+	}
+
+	var sb strings.Builder
+	if err := g.WriteDOT(&sb); err != nil {
+		t.Fatal(err)
+	}
+	dot := sb.String()
+	if !strings.Contains(dot, `<IMG SRC="`+prefix) {
+		t.Error("DOT has no thumbnails")
+	}
+	if !strings.Contains(dot, "Colors=[#ff0000ff #0000ff40]") || !strings.Contains(dot, "Lerp=LerpHSL") {
+		t.Errorf("DOT is missing the color and constant parameters\n%s", dot)
+	}
+}
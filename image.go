@@ -112,15 +112,24 @@ func (f *Image) getValue(x, y int) tcol.FRGBA {
 	return fc
 }
 
-// biPatch uses interp to calculate the value of f(u,v) for u,v in range [0,1).
+// biPatch uses interp to calculate the value of f(u,v) for u,v in range [0,1). The channels are interpolated
+// premultiplied to avoid dark fringes around transparent regions.
 func (f *Image) biPatch(u, v float64, p [][]tcol.FRGBA) tcol.FRGBA {
 	row := make([]float64, 4)
 	col := make([]float64, 4)
 
+	pp := make([][]tcol.FRGBA, 4)
+	for i := 0; i < 4; i++ {
+		pp[i] = make([]tcol.FRGBA, 4)
+		for j := 0; j < 4; j++ {
+			pp[i][j] = p[i][j].Premul()
+		}
+	}
+
 	// R
 	for j := 0; j < 4; j++ {
 		for i := 0; i < 4; i++ {
-			col[i] = p[i][j].R
+			col[i] = pp[i][j].R
 		}
 		row[j] = bcclamp(f.interp(v, col))
 	}
@@ -129,16 +138,16 @@ func (f *Image) biPatch(u, v float64, p [][]tcol.FRGBA) tcol.FRGBA {
 	// G
 	for j := 0; j < 4; j++ {
 		for i := 0; i < 4; i++ {
-			col[i] = p[i][j].G
+			col[i] = pp[i][j].G
 		}
-		row[j] = bcclamp(Cubic(v, col))
+		row[j] = bcclamp(f.interp(v, col))
 	}
 	g := bcclamp(f.interp(u, row))
 
 	// B
 	for j := 0; j < 4; j++ {
 		for i := 0; i < 4; i++ {
-			col[i] = p[i][j].B
+			col[i] = pp[i][j].B
 		}
 		row[j] = bcclamp(f.interp(v, col))
 	}
@@ -147,13 +156,13 @@ func (f *Image) biPatch(u, v float64, p [][]tcol.FRGBA) tcol.FRGBA {
 	// A
 	for j := 0; j < 4; j++ {
 		for i := 0; i < 4; i++ {
-			col[i] = p[i][j].A
+			col[i] = pp[i][j].A
 		}
 		row[j] = bcclamp(f.interp(v, col))
 	}
 	a := bcclamp(f.interp(u, row))
 
-	return tcol.FRGBA{r, g, b, a}
+	return tcol.FRGBA{r, g, b, a}.Unpremul()
 }
 
 func bcclamp(v float64) float64 {
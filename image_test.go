@@ -0,0 +1,31 @@
+package texture_test
+
+import (
+	"github.com/jphsd/texture"
+	tcol "github.com/jphsd/texture/color"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestImageChannels(t *testing.T) {
+	// Gray with varying alpha stays gray, whatever the interpolation
+	img := image.NewNRGBA(image.Rect(0, 0, 6, 6))
+	for y := range 6 {
+		for x := range 6 {
+			g := uint8((x*x*37 + y*53) % 256)
+			img.SetNRGBA(x, y, color.NRGBA{g, g, g, uint8(64 + (x+y)*16)})
+		}
+	}
+	for _, interp := range []texture.Interp{texture.LinearInterp, texture.CubicInterp, texture.P3Interp,
+		texture.P5Interp} {
+		f := texture.NewImage(img, interp)
+		for _, p := range [][]float64{{1.3, 1.7}, {2.5, 3.25}, {3.9, 2.1}} {
+			c := f.Eval2(p[0], p[1]).(tcol.FRGBA)
+			if math.Abs(c.R-c.G) > 1e-9 || math.Abs(c.B-c.G) > 1e-9 {
+				t.Errorf("interp %d at %v is %v, expected gray", interp, p, c)
+			}
+		}
+	}
+}
@@ -1,18 +1,478 @@
 package texture
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	g2dcol "github.com/jphsd/graphics2d/color"
+	tcol "github.com/jphsd/texture/color"
+	"image/color"
+	"io"
 	"os"
+	"reflect"
+	"sort"
+	"strings"
 )
 
+// SaveJSON writes v to name.json.
 func SaveJSON(v any, name string) error {
 	fDst, err := os.Create(fmt.Sprintf("%s.json", name))
 	if err != nil {
 		return err
 	}
 	defer fDst.Close()
-	enc := json.NewEncoder(fDst)
+	return WriteJSON(fDst, v)
+}
+
+// WriteJSON writes v to w as indented JSON. Colors held in interface fields are written with a Type field
+// naming their type so that [ReadJSON] can restore them exactly.
+func WriteJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
-	return enc.Encode(v)
+	return enc.Encode(jsonValue(v))
+}
+
+// LoadJSON reads a tree previously written by [SaveJSON] from name.json.
+func LoadJSON(name string) (any, error) {
+	fSrc, err := os.Open(fmt.Sprintf("%s.json", name))
+	if err != nil {
+		return nil, err
+	}
+	defer fSrc.Close()
+	return ReadJSON(fSrc)
+}
+
+// ReadJSON reads a tree of nodes from r. Each node is identified by its Name field, which must match
+// a type known to the package (see [RegisterJSON]). Colors are restored as the type named by their Type
+// field. Untagged colors, from files written before colors carried their type, are guessed from their fields.
+//
+// Nodes whose state isn't captured by their exported fields, such as [Image], [Shape],
+// [BlinnField] and [WorleyField], can't be restored and result in an error.
+func ReadJSON(r io.Reader) (any, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	res := reflect.ValueOf(&v).Elem()
+	if err := decodeValue(b, res, "$"); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+var jsonTypes = map[string]reflect.Type{}
+
+// RegisterJSON adds the types of the supplied nodes, keyed by type name, to those that [ReadJSON] can
+// restore. All the node types in this package are registered already.
+func RegisterJSON(nodes ...any) {
+	for _, n := range nodes {
+		t := reflect.TypeOf(n)
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		jsonTypes[t.Name()] = t
+	}
+}
+
+func init() {
+	RegisterJSON(&Binary{}, &BlockNoise{}, &Cache{}, &Triangles{}, &Squares{}, &Hexagons{}, &ColorGray{},
+		&ColorSinCos{}, &ColorConv{}, &ColorFields{}, &ColorVector{}, &ColorBlend{}, &ColorModeBlend{},
+		&ColorComposite{}, &ColorAlpha{}, &ColorSubstitute{}, &ColorLevels{}, &ColorCurves{}, &ColorHSLAdjust{},
+		&ColorBalance{}, &ColorMixer{}, &ColorInvert{}, &ColorTint{}, &MulCombiner{}, &AddCombiner{},
+		&SubCombiner{}, &MinCombiner{}, &MaxCombiner{}, &AvgCombiner{}, &DiffCombiner{}, &WindowedCombiner{},
+		&WeightedCombiner{}, &Blend{}, &StochasticBlend{}, &JitterBlend{}, &SubstituteCombiner{},
+		&ThresholdCombiner{}, &Component{}, &Convolution{}, &Displace{}, &Displace2{}, &DisplaceVF{},
+		&Displace2VF{}, &DisplaceCF{}, &Displace2CF{}, &Distort{}, &NLFilter{}, &InvertFilter{},
+		&QuantizeFilter{}, &RandQuantFilter{}, &ClipFilter{}, &OffsScaleFilter{}, &AbsFilter{}, &FoldFilter{},
+		&RemapFilter{}, &FloorFilter{}, &CeilFilter{}, &Fractal{}, &VariableFractal{}, &FBM{}, &MF{}, &LinearGradient{},
+		&RadialGradient{}, &ConicGradient{}, &Gradient{}, &IFS{}, &IFSCombiner{}, &Image{}, &Layer{},
+		&LayerStack{}, &Erode{}, &Dilate{}, &EdgeIn{}, &EdgeOut{}, &Edge{}, &Close{}, &Open{}, &TopHat{},
+		&BottomHat{}, &ColorNormalMap{}, &NormalMap{}, &NormalBlend{}, &Palette{}, &PBRComponent{},
+		&Perlin{}, &Pixelate{}, &PixelateVF{}, &PixelateCF{}, &BlinnField{}, &WorleyField{}, &ColorQuantize{},
+		&Reflect{}, &ReflectVF{}, &ReflectCF{}, &Shape{}, &ShapeCombiner{}, &ShapeCombinerCF{},
+		&ShapeCombinerVF{}, &Strip{}, &StripCF{}, &StripVF{}, &ThresholdFilter{}, &Tiler{}, &TilerCF{},
+		&TilerVF{}, &StochasticTiler{}, &StochasticTilerCF{}, &StochasticTilerVF{}, &ToneMap{},
+		&ColorHighlights{}, &ColorBloom{}, &Transform{}, &TransformVF{}, &TransformCF{}, &Uniform{},
+		&UniformCF{}, &UniformVF{}, &ColorToGray{}, &ColorSelect{}, &Direction{}, &Magnitude{}, &Select{},
+		&Weighted{}, &VectorFields{}, &VectorColor{}, &Normal{}, &UnitVector{}, &Warp{}, &WarpVF{}, &WarpCF{},
+		&RadialWF{}, &SwirlWF{}, &DrainWF{}, &RadialNLWF{}, &PinchXWF{}, &RippleXWF{}, &RadialRippleWF{},
+		&RadialWiggleWF{}, &NLWave{}, &DCWave{}, &ACWave{}, &PatternWave{}, &InvertWave{})
+}
+
+// nlConstructors creates the named NonLinears. The parameters of the parameterized ones are overwritten
+// from the JSON where the underlying function exposes them.
+var nlConstructors = map[string]func() *NonLinear{
+	"NLLinear":      NewNLLinear,
+	"NLSquare":      NewNLSquare,
+	"NLCube":        NewNLCube,
+	"NLExponential": func() *NonLinear { return NewNLExponential(2) },
+	"NLLogarithmic": func() *NonLinear { return NewNLLogarithmic(2) },
+	"NLSin":         NewNLSin,
+	"NLSin1":        NewNLSin1,
+	"NLSin2":        NewNLSin2,
+	"NLCircle1":     NewNLCircle1,
+	"NLCircle2":     NewNLCircle2,
+	"NLCatenary":    NewNLCatenary,
+	"NLGauss":       func() *NonLinear { return NewNLGauss(1) },
+	"NLLogistic":    func() *NonLinear { return NewNLLogistic(10, 0.5) },
+	"NLP3":          NewNLP3,
+	"NLP5":          NewNLP5,
+}
+
+// NodeNames returns the sorted names of the node types, including the named [NonLinear]s, that can be
+// created with [NewNode] and restored by [ReadJSON].
+func NodeNames() []string {
+	res := make([]string, 0, len(jsonTypes)+len(nlConstructors))
+	for k := range jsonTypes {
+		res = append(res, k)
+	}
+	for k := range nlConstructors {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// NewNode returns a new node of the named type. Apart from the name, and the function of a [NonLinear],
+// its fields are zero valued and must be set before it can be evaluated.
+func NewNode(name string) (any, error) {
+	if f, ok := nlConstructors[name]; ok {
+		return f(), nil
+	}
+	t, ok := jsonTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown node %q", name)
+	}
+	res := reflect.New(t)
+	if f := res.Elem().FieldByName("Name"); f.IsValid() && f.Kind() == reflect.String {
+		f.SetString(name)
+	}
+	return res.Interface(), nil
+}
+
+var (
+	nonLinearType = reflect.TypeFor[NonLinear]()
+	colorType     = reflect.TypeFor[color.Color]()
+)
+
+// decodeValue decodes data into v, using path to report errors.
+func decodeValue(data []byte, v reflect.Value, path string) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		v.SetZero()
+		return nil
+	}
+
+	t := v.Type()
+	switch t.Kind() {
+	case reflect.Interface:
+		if t == colorType {
+			c, err := decodeColor(data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			v.Set(reflect.ValueOf(c))
+			return nil
+		}
+		var hdr struct{ Name string }
+		if err := json.Unmarshal(data, &hdr); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		nt, ok := jsonTypes[hdr.Name]
+		if !ok {
+			return fmt.Errorf("%s: unknown node %q", path, hdr.Name)
+		}
+		n := reflect.New(nt)
+		if !n.Type().AssignableTo(t) {
+			return fmt.Errorf("%s: %s is not a %s", path, hdr.Name, t.Name())
+		}
+		if err := decodeValue(data, n.Elem(), path); err != nil {
+			return err
+		}
+		v.Set(n)
+		return nil
+	case reflect.Pointer:
+		n := reflect.New(t.Elem())
+		if err := decodeValue(data, n.Elem(), path); err != nil {
+			return err
+		}
+		v.Set(n)
+		return nil
+	case reflect.Struct:
+		if t == nonLinearType {
+			return decodeNonLinear(data, v, path)
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for i := range t.NumField() {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			fd, ok := fields[sf.Name]
+			if !ok {
+				for k, d := range fields {
+					if strings.EqualFold(k, sf.Name) {
+						fd, ok = d, true
+						break
+					}
+				}
+			}
+			if !ok {
+				continue
+			}
+			if err := decodeValue(fd, v.Field(i), path+"."+sf.Name); err != nil {
+				return err
+			}
+		}
+		if err := restore(v); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		var elts []json.RawMessage
+		if err := json.Unmarshal(data, &elts); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if t.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(t, len(elts), len(elts)))
+		} else if len(elts) > v.Len() {
+			return fmt.Errorf("%s: too many elements for %s", path, t)
+		}
+		for i, d := range elts {
+			if err := decodeValue(d, v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Everything else is handled by the standard decoder
+	if err := json.Unmarshal(data, v.Addr().Interface()); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func decodeNonLinear(data []byte, v reflect.Value, path string) error {
+	var nl struct {
+		Name string
+		NLF  json.RawMessage
+	}
+	if err := json.Unmarshal(data, &nl); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	f, ok := nlConstructors[nl.Name]
+	if !ok {
+		return fmt.Errorf("%s: unknown nonlinear function %q", path, nl.Name)
+	}
+	res := f()
+	if len(nl.NLF) > 0 {
+		if err := json.Unmarshal(nl.NLF, res.NLF); err != nil {
+			return fmt.Errorf("%s.NLF: %w", path, err)
+		}
+	}
+	v.Set(reflect.ValueOf(*res))
+	return nil
+}
+
+// jsonColors maps the type names written by jsonColor to the color types they restore to.
+var jsonColors = map[string]reflect.Type{}
+
+func init() {
+	for _, c := range []color.Color{
+		color.RGBA{}, color.NRGBA{}, color.RGBA64{}, color.NRGBA64{},
+		color.Alpha{}, color.Alpha16{}, color.Gray{}, color.Gray16{}, color.CMYK{},
+		tcol.FRGBA{}, tcol.LinearRGBA{}, tcol.HSL{}, tcol.HSV{},
+		tcol.Lab{}, tcol.LCh{}, tcol.OKLab{}, tcol.OKLCh{},
+	} {
+		t := reflect.TypeOf(c)
+		jsonColors[t.Name()] = t
+	}
+}
+
+// jsonColor wraps a color so that it's written with the name of its type.
+type jsonColor struct {
+	color.Color
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c jsonColor) MarshalJSON() ([]byte, error) {
+	col, name := c.Color, reflect.TypeOf(c.Color).Name()
+	if jsonColors[name] != reflect.TypeOf(col) {
+		// Other color types are written as their premultiplied value
+		col, name = color.RGBA64Model.Convert(col), "RGBA64"
+	}
+	b, err := json.Marshal(col)
+	if err != nil {
+		return nil, err
+	}
+	hdr := fmt.Sprintf(`{"Type":%q`, name)
+	if len(b) > 2 {
+		hdr += ","
+	}
+	return append([]byte(hdr), b[1:]...), nil
+}
+
+// jsonValue returns a copy of v in which the colors held in interface values are wrapped by jsonColor.
+func jsonValue(v any) any {
+	if v == nil {
+		return nil
+	}
+	return tagColors(reflect.ValueOf(v), map[any]reflect.Value{}).Interface()
+}
+
+var jsonColorType = reflect.TypeFor[jsonColor]()
+
+func tagColors(v reflect.Value, memo map[any]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.New(v.Type()).Elem()
+		if c, ok := v.Elem().Interface().(color.Color); ok && jsonColorType.AssignableTo(v.Type()) {
+			res.Set(reflect.ValueOf(jsonColor{c}))
+		} else {
+			res.Set(tagColors(v.Elem(), memo))
+		}
+		return res
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		if res, ok := memo[v.Interface()]; ok {
+			return res
+		}
+		res := reflect.New(v.Type().Elem())
+		memo[v.Interface()] = res
+		res.Elem().Set(tagColors(v.Elem(), memo))
+		return res
+	case reflect.Struct:
+		res := reflect.New(v.Type()).Elem()
+		res.Set(v)
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				res.Field(i).Set(tagColors(v.Field(i), memo))
+			}
+		}
+		return res
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() || !holdsValues(v.Type().Elem()) {
+			// Pixel data and the like needn't be copied
+			return v
+		}
+		var res reflect.Value
+		if v.Kind() == reflect.Slice {
+			res = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		} else {
+			res = reflect.New(v.Type()).Elem()
+		}
+		for i := range v.Len() {
+			res.Index(i).Set(tagColors(v.Index(i), memo))
+		}
+		return res
+	case reflect.Map:
+		if v.IsNil() || !holdsValues(v.Type().Elem()) {
+			return v
+		}
+		res := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			res.SetMapIndex(it.Key(), tagColors(it.Value(), memo))
+		}
+		return res
+	}
+	return v
+}
+
+// holdsValues returns true if values of type t may contain an interface value.
+func holdsValues(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Pointer, reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// decodeColor restores a color written by WriteJSON.
+func decodeColor(data []byte) (color.Color, error) {
+	var hdr struct{ Type string }
+	if err := json.Unmarshal(data, &hdr); err != nil {
+		return nil, err
+	}
+	if hdr.Type != "" {
+		t, ok := jsonColors[hdr.Type]
+		if !ok {
+			return nil, fmt.Errorf("unknown color type %q", hdr.Type)
+		}
+		c := reflect.New(t)
+		if err := json.Unmarshal(data, c.Interface()); err != nil {
+			return nil, err
+		}
+		return c.Elem().Interface().(color.Color), nil
+	}
+
+	var c map[string]float64
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if y, ok := c["Y"]; ok {
+		if y > 0xff {
+			return color.Gray16{uint16(y)}, nil
+		}
+		return color.Gray{uint8(y)}, nil
+	}
+	if h, ok := c["H"]; ok {
+		if v, ok := c["V"]; ok {
+			return tcol.HSV{h, c["S"], v, c["A"]}, nil
+		}
+		return g2dcol.HSL{h, c["S"], c["L"], c["A"]}, nil
+	}
+	r, rok := c["R"]
+	g, gok := c["G"]
+	b, bok := c["B"]
+	a, aok := c["A"]
+	if !rok || !gok || !bok || !aok {
+		return nil, fmt.Errorf("unrecognized color %s", data)
+	}
+	if max(r, g, b, a) <= 1 {
+		return tcol.FRGBA{R: r, G: g, B: b, A: a}, nil
+	}
+	if max(r, g, b, a) > 0xff {
+		if max(r, g, b) > a {
+			return color.NRGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}, nil
+		}
+		return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}, nil
+	}
+	if max(r, g, b) > a {
+		return color.NRGBA{uint8(r), uint8(g), uint8(b), uint8(a)}, nil
+	}
+	return color.RGBA{uint8(r), uint8(g), uint8(b), uint8(a)}, nil
+}
+
+// restore rebuilds the internal state of a node after its exported fields have been read.
+func restore(v reflect.Value) error {
+	switch n := v.Addr().Interface().(type) {
+	case *Image, *Shape, *BlinnField, *WorleyField:
+		return fmt.Errorf("%s can't be restored from its fields", v.Type().Name())
+	case *Binary:
+		*n = *NewBinary(n.Width, n.Height, n.Seed, n.Perc)
+	case *Perlin:
+		*n = *NewPerlin(n.Seed)
+	case *BlockNoise:
+		n.cache = make(map[int][][]float64)
+	case *Cache:
+		*n = *NewCache(n.Src, n.Resolution, n.Limit)
+	case *StochasticTiler:
+		n.rmap = make(map[int]int)
+	case *StochasticTilerCF:
+		n.rmap = make(map[int]int)
+	case *StochasticTilerVF:
+		n.rmap = make(map[int]int)
+	case *ColorQuantize:
+		*n = *NewColorQuantize(n.Src, n.Colors, n.Dither, n.Size)
+	}
+	return nil
 }
@@ -0,0 +1,121 @@
+package texture_test
+
+import (
+	"bytes"
+	"github.com/jphsd/texture"
+	tcol "github.com/jphsd/texture/color"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// roundTrip writes v as JSON and reads it back.
+func roundTrip(t *testing.T, v any) any {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := texture.WriteJSON(&buf, v); err != nil {
+		t.Fatal(err)
+	}
+	res, err := texture.ReadJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// grayish is a color type unknown to the package.
+type grayish struct{ v uint16 }
+
+func (c grayish) RGBA() (uint32, uint32, uint32, uint32) {
+	v := uint32(c.v)
+	return v, v, v, 0xffff
+}
+
+func TestJSONColors(t *testing.T) {
+	cols := []color.Color{
+		color.RGBA{0, 0, 0, 1},
+		color.NRGBA{1, 1, 1, 1},
+		color.RGBA64{300, 200, 100, 400},
+		color.NRGBA64{0xffff, 0, 0x8000, 0x100},
+		color.Gray{0x80},
+		color.Gray16{1},
+		color.Alpha{0x40},
+		tcol.FRGBA{0.25, 0.5, 0.75, 1},
+		tcol.FRGBA{1, 1, 1, 1},
+		tcol.LinearRGBA{0.1, 0.2, 0.3, 0.5},
+		tcol.HSL{0.5, 0.25, 0.75, 1},
+		tcol.HSV{0.5, 0.25, 0.75, 1},
+		tcol.Lab{50, 20, -30, 1},
+		tcol.LCh{50, 40, 120, 0.5},
+		tcol.OKLab{0.5, 0.1, -0.1, 1},
+		tcol.OKLCh{0.5, 0.2, 240, 0.5},
+	}
+	for _, c := range cols {
+		v := roundTrip(t, texture.NewUniformCF(c))
+		if got := v.(*texture.UniformCF).Value; !reflect.DeepEqual(got, c) {
+			t.Errorf("%T %v reloaded as %T %v", c, c, got, got)
+		}
+	}
+
+	// Unknown types are restored as their RGBA64 value
+	c := grayish{0x1234}
+	v := roundTrip(t, texture.NewUniformCF(c))
+	if got, want := v.(*texture.UniformCF).Value, color.RGBA64Model.Convert(c); got != want {
+		t.Errorf("%T reloaded as %T %v, expected %v", c, got, got, want)
+	}
+}
+
+func TestJSONUntaggedColors(t *testing.T) {
+	tests := []struct {
+		data string
+		want color.Color
+	}{
+		{`{"R":255,"G":0,"B":0,"A":255}`, color.RGBA{255, 0, 0, 255}},
+		{`{"R":255,"G":0,"B":0,"A":128}`, color.NRGBA{255, 0, 0, 128}},
+		{`{"R":0.5,"G":0,"B":0,"A":1}`, tcol.FRGBA{0.5, 0, 0, 1}},
+		{`{"H":0.5,"S":1,"L":0.5,"A":1}`, tcol.HSL{0.5, 1, 0.5, 1}},
+	}
+	for _, test := range tests {
+		data := `{"Name":"UniformCF","Value":` + test.data + `}`
+		v, err := texture.ReadJSON(strings.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: %v", test.data, err)
+			continue
+		}
+		if got := v.(*texture.UniformCF).Value; got != test.want {
+			t.Errorf("%s reloaded as %T %v", test.data, got, got)
+		}
+	}
+
+	_, err := texture.ReadJSON(strings.NewReader(`{"Name":"UniformCF","Value":{"Type":"CIE","X":1}}`))
+	if err == nil || !strings.Contains(err.Error(), "$.Value") {
+		t.Errorf("unknown color type gave %v", err)
+	}
+}
+
+func TestJSONTree(t *testing.T) {
+	cf := testColorField()
+	v := roundTrip(t, cf)
+	rcf, ok := v.(texture.ColorField)
+	if !ok {
+		t.Fatalf("reloaded %T", v)
+	}
+	for y := range 20 {
+		for x := range 20 {
+			fx, fy := float64(x)*7.5, float64(y)*4.25
+			if c1, c2 := cf.Eval2(fx, fy), rcf.Eval2(fx, fy); c1 != c2 {
+				t.Fatalf("(%g,%g) is %v, reloaded %v", fx, fy, c1, c2)
+			}
+		}
+	}
+}
+
+func TestJSONNonLinearError(t *testing.T) {
+	data := `{"Name":"LinearGradient","WF":{"Name":"NLWave","Lambdas":[10],"CumLambda":[10],` +
+		`"NLFs":[{"Name":"NLExponential","NLF":[2]}]}}`
+	_, err := texture.ReadJSON(strings.NewReader(data))
+	if err == nil || !strings.Contains(err.Error(), "$.WF.NLFs[0].NLF") {
+		t.Errorf("malformed parameters gave %v", err)
+	}
+}
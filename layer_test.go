@@ -0,0 +1,73 @@
+package texture_test
+
+import (
+	"github.com/jphsd/texture"
+	tcol "github.com/jphsd/texture/color"
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func testLayerStack() *texture.LayerStack {
+	w := texture.NewNLWave([]float64{40}, []*texture.NonLinear{texture.NewNLLinear()}, true, false)
+	red := texture.NewUniformCF(color.RGBA{255, 0, 0, 255})
+	blue := texture.NewUniformCF(color.RGBA{0, 0, 255, 255})
+	return texture.NewLayerStack(
+		texture.NewLayer(red, nil, tcol.NormalBlend, 1),
+		texture.NewLayer(blue, texture.NewLinearGradient(w), tcol.MultiplyBlend, 0.5))
+}
+
+func TestLayerStackEdit(t *testing.T) {
+	s := testLayerStack()
+	bottom, top := s.Layers[0], s.Layers[1]
+	if err := s.Move(0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if s.Layers[0] != top || s.Layers[1] != bottom {
+		t.Error("Move(0, 1) didn't swap the layers")
+	}
+	if err := s.Insert(3, bottom); err == nil {
+		t.Error("Insert(3) succeeded on a stack of 2")
+	}
+	if err := s.Insert(-1, bottom); err == nil {
+		t.Error("Insert(-1) succeeded")
+	}
+	if _, err := s.Remove(2); err == nil {
+		t.Error("Remove(2) succeeded on a stack of 2")
+	}
+	if err := s.Move(0, 2); err == nil {
+		t.Error("Move(0, 2) succeeded on a stack of 2")
+	}
+	if len(s.Layers) != 2 {
+		t.Errorf("failed edits changed the stack to %d layers", len(s.Layers))
+	}
+	if l, err := s.Remove(1); err != nil || l != bottom || len(s.Layers) != 1 {
+		t.Errorf("Remove(1) = %v, %v", l, err)
+	}
+}
+
+func TestLayerStackJSON(t *testing.T) {
+	s := testLayerStack()
+	name := filepath.Join(t.TempDir(), "stack")
+	if err := texture.SaveJSON(s, name); err != nil {
+		t.Fatal(err)
+	}
+	v, err := texture.LoadJSON(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls, ok := v.(*texture.LayerStack)
+	if !ok {
+		t.Fatalf("loaded %T, not *texture.LayerStack", v)
+	}
+	if len(ls.Layers) != 2 || ls.Layers[1].Mode != tcol.MultiplyBlend || ls.Layers[1].Opacity != 0.5 {
+		t.Fatalf("loaded layers differ: %+v", ls.Layers)
+	}
+	for _, pt := range [][2]float64{{0, 0}, {10, 5}, {33, 7}, {75, 1}} {
+		r1, g1, b1, a1 := s.Eval2(pt[0], pt[1]).RGBA()
+		r2, g2, b2, a2 := ls.Eval2(pt[0], pt[1]).RGBA()
+		if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+			t.Errorf("at %v got %v, want %v", pt, []uint32{r2, g2, b2, a2}, []uint32{r1, g1, b1, a1})
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package texture
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+)
+
+// Node is implemented by all the nodes in the package and gives generic access to their inputs and
+// parameters.
+//
+// Children returns a node's inputs, the fields holding fields, vector fields, color fields, waves and
+// other nodes, in field order with a slot per element of list fields. Unset inputs are nil so that the
+// slots don't move. SetChild replaces the input in slot i. Params describes the other exported fields.
+type Node interface {
+	Children() []any
+	SetChild(i int, c any) error
+	Params() []Param
+}
+
+// Param describes a node parameter. Min and Max give the range of numeric parameters, and of the elements
+// of lists of numbers, and are infinite if it's unbounded. They're zero for other parameters.
+type Param struct {
+	Name     string
+	Type     string
+	Min, Max float64
+	Value    any
+}
+
+// paramRanges holds the ranges of the bounded parameters, keyed by type and field name. Thresholds
+// compared with field values are in [-1,1].
+var paramRanges = map[string][2]float64{
+	"Binary.Perc":             {0, 1},
+	"JitterBlend.Perc":        {0, 1},
+	"ThresholdCombiner.A":     {-1, 1},
+	"SubstituteCombiner.A":    {-1, 1},
+	"SubstituteCombiner.B":    {-1, 1},
+	"WindowedCombiner.A":      {-1, 1},
+	"WindowedCombiner.B":      {-1, 1},
+	"ColorSubstitute.A":       {-1, 1},
+	"ColorSubstitute.B":       {-1, 1},
+	"ColorLevels.InBlack":     {0, 1},
+	"ColorLevels.InWhite":     {0, 1},
+	"ColorLevels.OutBlack":    {0, 1},
+	"ColorLevels.OutWhite":    {0, 1},
+	"ColorHSLAdjust.Hue":      {-1, 1},
+	"ColorHSLAdjust.Sat":      {-1, 1},
+	"ColorHSLAdjust.Light":    {-1, 1},
+	"ColorBalance.Shadows":    {-1, 1},
+	"ColorBalance.Midtones":   {-1, 1},
+	"ColorBalance.Highlights": {-1, 1},
+	"Layer.Opacity":           {0, 1},
+	"Uniform.Value":           {-1, 1},
+	"UniformVF.Value":         {-1, 1},
+	"ColorConv.TVals":         {0, 1},
+}
+
+// Walk calls fn for each node in the tree rooted at n, parents before children. Shared nodes are visited
+// once. If fn returns false, the node's children are skipped.
+func Walk(n any, fn func(n any) bool) {
+	walk(n, fn, map[any]bool{})
+}
+
+func walk(n any, fn func(n any) bool, seen map[any]bool) {
+	if n == nil || seen[n] {
+		return
+	}
+	seen[n] = true
+	if !fn(n) {
+		return
+	}
+	if nd, ok := n.(Node); ok {
+		for _, c := range nd.Children() {
+			walk(c, fn, seen)
+		}
+	}
+}
+
+// Clone returns a deep copy of the tree rooted at n. Shared nodes remain shared in the copy, and nodes with
+// caches get their own. Images, shapes and the point sets of [BlinnField] and [WorleyField] aren't copied.
+func Clone(n any) any {
+	if n == nil {
+		return nil
+	}
+	return cloneValue(reflect.ValueOf(n), map[any]reflect.Value{}).Interface()
+}
+
+func cloneValue(v reflect.Value, memo map[any]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.New(v.Type()).Elem()
+		res.Set(cloneValue(v.Elem(), memo))
+		return res
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		if res, ok := memo[v.Interface()]; ok {
+			return res
+		}
+		res := reflect.New(v.Type().Elem())
+		memo[v.Interface()] = res
+		res.Elem().Set(cloneValue(v.Elem(), memo))
+		if dslIsNode(v) {
+			// Unrestorable nodes keep their shared state
+			_ = restore(res.Elem())
+		}
+		return res
+	case reflect.Struct:
+		// Copy the unexported state and then the exported fields
+		res := reflect.New(v.Type()).Elem()
+		res.Set(v)
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				res.Field(i).Set(cloneValue(v.Field(i), memo))
+			}
+		}
+		return res
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			res.Index(i).Set(cloneValue(v.Index(i), memo))
+		}
+		return res
+	case reflect.Array:
+		res := reflect.New(v.Type()).Elem()
+		for i := range v.Len() {
+			res.Index(i).Set(cloneValue(v.Index(i), memo))
+		}
+		return res
+	}
+	return v
+}
+
+// Map returns a copy of the tree rooted at n in which every node, children first, has been replaced by
+// the result of fn. Shared nodes are mapped once.
+func Map(n any, fn func(n any) any) (any, error) {
+	return mapNodes(Clone(n), fn, map[any]any{})
+}
+
+func mapNodes(n any, fn func(n any) any, memo map[any]any) (any, error) {
+	if n == nil {
+		return nil, nil
+	}
+	if res, ok := memo[n]; ok {
+		return res, nil
+	}
+	if nd, ok := n.(Node); ok {
+		for i, c := range nd.Children() {
+			if c == nil {
+				continue
+			}
+			mc, err := mapNodes(c, fn, memo)
+			if err != nil {
+				return nil, err
+			}
+			if err := nd.SetChild(i, mc); err != nil {
+				return nil, err
+			}
+		}
+	}
+	res := fn(n)
+	memo[n] = res
+	return res, nil
+}
+
+// Replace replaces, in place, every reference to old in the tree rooted at n with new, and returns the
+// root, which is new if n is old.
+func Replace(n, old, new any) (any, error) {
+	if n == old {
+		return new, nil
+	}
+	var err error
+	Walk(n, func(c any) bool {
+		if err != nil || c == new {
+			// Don't replace old within new
+			return false
+		}
+		nd, ok := c.(Node)
+		if !ok {
+			return true
+		}
+		for i, cc := range nd.Children() {
+			if cc != nil && cc == old {
+				if err = nd.SetChild(i, new); err != nil {
+					return false
+				}
+			}
+		}
+		return true
+	})
+	return n, err
+}
+
+// childTypes caches whether fields of a type hold nodes.
+var childTypes sync.Map
+
+// isChild returns true if fields of type t hold nodes, that is t is a pointer to a node type or an
+// interface, other than color.Color, that a node type implements.
+func isChild(t reflect.Type) bool {
+	if r, ok := childTypes.Load(t); ok {
+		return r.(bool)
+	}
+	res := false
+	switch t.Kind() {
+	case reflect.Interface:
+		if t != colorType {
+			for _, nt := range jsonTypes {
+				if reflect.PointerTo(nt).Implements(t) {
+					res = true
+					break
+				}
+			}
+		}
+	case reflect.Pointer:
+		res = jsonTypes[t.Elem().Name()] == t.Elem()
+	}
+	childTypes.Store(t, res)
+	return res
+}
+
+// childSlots returns the values holding the children of node n.
+func childSlots(n any) []reflect.Value {
+	v := reflect.ValueOf(n).Elem()
+	var res []reflect.Value
+	for i := range v.NumField() {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		if isChild(sf.Type) {
+			res = append(res, v.Field(i))
+		} else if sf.Type.Kind() == reflect.Slice && isChild(sf.Type.Elem()) {
+			for j := range v.Field(i).Len() {
+				res = append(res, v.Field(i).Index(j))
+			}
+		}
+	}
+	return res
+}
+
+// childNames returns the names of the slots of node n, with the index appended for elements of list
+// fields.
+func childNames(n any) []string {
+	v := reflect.ValueOf(n).Elem()
+	var res []string
+	for i := range v.NumField() {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		if isChild(sf.Type) {
+			res = append(res, sf.Name)
+		} else if sf.Type.Kind() == reflect.Slice && isChild(sf.Type.Elem()) {
+			for j := range v.Field(i).Len() {
+				res = append(res, fmt.Sprintf("%s[%d]", sf.Name, j))
+			}
+		}
+	}
+	return res
+}
+
+func children(n any) []any {
+	slots := childSlots(n)
+	res := make([]any, len(slots))
+	for i, s := range slots {
+		if !s.IsNil() {
+			res[i] = s.Interface()
+		}
+	}
+	return res
+}
+
+func setChild(n any, i int, c any) error {
+	slots := childSlots(n)
+	name := reflect.TypeOf(n).Elem().Name()
+	if i < 0 || i >= len(slots) {
+		return fmt.Errorf("%s has no child %d", name, i)
+	}
+	s := slots[i]
+	if c == nil {
+		s.SetZero()
+	} else {
+		cv := reflect.ValueOf(c)
+		if !cv.Type().AssignableTo(s.Type()) {
+			return fmt.Errorf("%T can't be child %d of %s, which is a %s", c, i, name, s.Type())
+		}
+		s.Set(cv)
+	}
+	// Rebuild any state derived from the children
+	return restore(reflect.ValueOf(n).Elem())
+}
+
+func params(n any) []Param {
+	v := reflect.ValueOf(n).Elem()
+	t := v.Type()
+	var res []Param
+	for i := range v.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() || sf.Name == "Name" || isChild(sf.Type) ||
+			(sf.Type.Kind() == reflect.Slice && isChild(sf.Type.Elem())) {
+			continue
+		}
+		p := Param{Name: sf.Name, Type: sf.Type.String(), Value: v.Field(i).Interface()}
+		p.Min, p.Max = paramRange(t.Name()+"."+sf.Name, sf.Type)
+		res = append(res, p)
+	}
+	return res
+}
+
+// paramRange returns the range of a parameter from paramRanges, the named constants of its type or
+// its kind.
+func paramRange(key string, t reflect.Type) (float64, float64) {
+	if r, ok := paramRanges[key]; ok {
+		return r[0], r[1]
+	}
+	for t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, c := range dslConsts {
+		if reflect.TypeOf(c) == t {
+			f := float64(reflect.ValueOf(c).Int())
+			lo, hi = min(lo, f), max(hi, f)
+		}
+	}
+	if lo <= hi {
+		return lo, hi
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		return math.Inf(-1), math.Inf(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return 0, math.Inf(1)
+	}
+	return 0, 0
+}
@@ -0,0 +1,117 @@
+package texture_test
+
+import (
+	"github.com/jphsd/graphics2d"
+	"github.com/jphsd/texture"
+	"image/color"
+	"testing"
+)
+
+// testShared returns a tree in which p is used twice.
+func testShared() (*texture.MulCombiner, *texture.Perlin) {
+	p := texture.NewPerlin(7)
+	tf := texture.NewTransform(texture.NewInvertFilter(p), graphics2d.Rotate(0.5))
+	return texture.NewMulCombiner(p, tf), p
+}
+
+// sharedPerlin returns the two references to the shared node of a tree made by testShared.
+func sharedPerlin(m *texture.MulCombiner) (texture.Field, texture.Field) {
+	return m.Src1, m.Src2.(*texture.Transform).Src.(*texture.InvertFilter).Src
+}
+
+func TestClone(t *testing.T) {
+	tree, p := testShared()
+	v := texture.Clone(tree)
+	c, ok := v.(*texture.MulCombiner)
+	if !ok {
+		t.Fatalf("Clone returned %T", v)
+	}
+	if c == tree {
+		t.Fatal("Clone returned the original")
+	}
+	p1, p2 := sharedPerlin(c)
+	if p1 != p2 {
+		t.Error("shared node isn't shared in the copy")
+	}
+	if p1 == texture.Field(p) {
+		t.Error("shared node wasn't copied")
+	}
+	if !sameField(tree, c) {
+		t.Error("copy evaluates differently")
+	}
+
+	// The copy is independent of the original
+	*c.Src2.(*texture.Transform).Xfm = *graphics2d.Rotate(1)
+	orig, _ := testShared()
+	if !sameField(tree, orig) {
+		t.Error("changing the copy changed the original")
+	}
+}
+
+func TestMap(t *testing.T) {
+	tree, p := testShared()
+	calls := map[any]int{}
+	u := texture.NewUniform(0.25)
+	res, err := texture.Map(tree, func(n any) any {
+		calls[n]++
+		if _, ok := n.(*texture.Perlin); ok {
+			return u
+		}
+		return n
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, c := range calls {
+		if c != 1 {
+			t.Errorf("%T mapped %d times", n, c)
+		}
+	}
+	if len(calls) != 4 {
+		t.Errorf("mapped %d nodes, expected 4", len(calls))
+	}
+	m := res.(*texture.MulCombiner)
+	if p1, p2 := sharedPerlin(m); p1 != u || p2 != u {
+		t.Errorf("Perlins mapped to %T and %T", p1, p2)
+	}
+	if p1, p2 := sharedPerlin(tree); p1 != texture.Field(p) || p2 != texture.Field(p) {
+		t.Error("Map changed the original")
+	}
+
+	// Results must fit the slots they replace
+	_, err = texture.Map(tree, func(n any) any {
+		if _, ok := n.(*texture.Perlin); ok {
+			return texture.NewUniformCF(color.White)
+		}
+		return n
+	})
+	if err == nil {
+		t.Error("mapping a field to a color field succeeded")
+	}
+}
+
+func TestReplace(t *testing.T) {
+	tree, p := testShared()
+	inv := texture.NewInvertFilter(p)
+	res, err := texture.Replace(tree, p, inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != any(tree) {
+		t.Errorf("Replace returned %T, expected the root", res)
+	}
+	if p1, p2 := sharedPerlin(tree); p1 != texture.Field(inv) || p2 != texture.Field(inv) {
+		t.Errorf("references replaced by %T and %T", p1, p2)
+	}
+	if inv.Src != texture.Field(p) {
+		t.Error("old node replaced within the new one")
+	}
+
+	u := texture.NewUniform(0)
+	if res, err := texture.Replace(tree, tree, u); err != nil || res != any(u) {
+		t.Errorf("replacing the root returned %v, %v", res, err)
+	}
+	if _, err := texture.Replace(tree, inv, texture.NewUniformCF(color.White)); err == nil {
+		t.Error("replacing a field with a color field succeeded")
+	}
+}
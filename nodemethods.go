@@ -0,0 +1,575 @@
+package texture
+
+// The Node implementations of the package's nodes.
+
+func (n *Binary) Children() []any             { return children(n) }
+func (n *Binary) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Binary) Params() []Param             { return params(n) }
+
+func (n *BlockNoise) Children() []any             { return children(n) }
+func (n *BlockNoise) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *BlockNoise) Params() []Param             { return params(n) }
+
+func (n *Cache) Children() []any             { return children(n) }
+func (n *Cache) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Cache) Params() []Param             { return params(n) }
+
+func (n *Triangles) Children() []any             { return children(n) }
+func (n *Triangles) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Triangles) Params() []Param             { return params(n) }
+
+func (n *Squares) Children() []any             { return children(n) }
+func (n *Squares) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Squares) Params() []Param             { return params(n) }
+
+func (n *Hexagons) Children() []any             { return children(n) }
+func (n *Hexagons) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Hexagons) Params() []Param             { return params(n) }
+
+func (n *ColorGray) Children() []any             { return children(n) }
+func (n *ColorGray) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorGray) Params() []Param             { return params(n) }
+
+func (n *ColorSinCos) Children() []any             { return children(n) }
+func (n *ColorSinCos) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorSinCos) Params() []Param             { return params(n) }
+
+func (n *ColorConv) Children() []any             { return children(n) }
+func (n *ColorConv) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorConv) Params() []Param             { return params(n) }
+
+func (n *ColorFields) Children() []any             { return children(n) }
+func (n *ColorFields) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorFields) Params() []Param             { return params(n) }
+
+func (n *ColorVector) Children() []any             { return children(n) }
+func (n *ColorVector) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorVector) Params() []Param             { return params(n) }
+
+func (n *ColorBlend) Children() []any             { return children(n) }
+func (n *ColorBlend) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorBlend) Params() []Param             { return params(n) }
+
+func (n *ColorModeBlend) Children() []any             { return children(n) }
+func (n *ColorModeBlend) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorModeBlend) Params() []Param             { return params(n) }
+
+func (n *ColorComposite) Children() []any             { return children(n) }
+func (n *ColorComposite) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorComposite) Params() []Param             { return params(n) }
+
+func (n *ColorAlpha) Children() []any             { return children(n) }
+func (n *ColorAlpha) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorAlpha) Params() []Param             { return params(n) }
+
+func (n *ColorSubstitute) Children() []any             { return children(n) }
+func (n *ColorSubstitute) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorSubstitute) Params() []Param             { return params(n) }
+
+func (n *ColorLevels) Children() []any             { return children(n) }
+func (n *ColorLevels) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorLevels) Params() []Param             { return params(n) }
+
+func (n *ColorCurves) Children() []any             { return children(n) }
+func (n *ColorCurves) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorCurves) Params() []Param             { return params(n) }
+
+func (n *ColorHSLAdjust) Children() []any             { return children(n) }
+func (n *ColorHSLAdjust) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorHSLAdjust) Params() []Param             { return params(n) }
+
+func (n *ColorBalance) Children() []any             { return children(n) }
+func (n *ColorBalance) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorBalance) Params() []Param             { return params(n) }
+
+func (n *ColorMixer) Children() []any             { return children(n) }
+func (n *ColorMixer) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorMixer) Params() []Param             { return params(n) }
+
+func (n *ColorInvert) Children() []any             { return children(n) }
+func (n *ColorInvert) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorInvert) Params() []Param             { return params(n) }
+
+func (n *ColorTint) Children() []any             { return children(n) }
+func (n *ColorTint) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorTint) Params() []Param             { return params(n) }
+
+func (n *MulCombiner) Children() []any             { return children(n) }
+func (n *MulCombiner) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *MulCombiner) Params() []Param             { return params(n) }
+
+func (n *AddCombiner) Children() []any             { return children(n) }
+func (n *AddCombiner) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *AddCombiner) Params() []Param             { return params(n) }
+
+func (n *SubCombiner) Children() []any             { return children(n) }
+func (n *SubCombiner) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *SubCombiner) Params() []Param             { return params(n) }
+
+func (n *MinCombiner) Children() []any             { return children(n) }
+func (n *MinCombiner) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *MinCombiner) Params() []Param             { return params(n) }
+
+func (n *MaxCombiner) Children() []any             { return children(n) }
+func (n *MaxCombiner) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *MaxCombiner) Params() []Param             { return params(n) }
+
+func (n *AvgCombiner) Children() []any             { return children(n) }
+func (n *AvgCombiner) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *AvgCombiner) Params() []Param             { return params(n) }
+
+func (n *DiffCombiner) Children() []any             { return children(n) }
+func (n *DiffCombiner) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *DiffCombiner) Params() []Param             { return params(n) }
+
+func (n *WindowedCombiner) Children() []any             { return children(n) }
+func (n *WindowedCombiner) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *WindowedCombiner) Params() []Param             { return params(n) }
+
+func (n *WeightedCombiner) Children() []any             { return children(n) }
+func (n *WeightedCombiner) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *WeightedCombiner) Params() []Param             { return params(n) }
+
+func (n *Blend) Children() []any             { return children(n) }
+func (n *Blend) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Blend) Params() []Param             { return params(n) }
+
+func (n *StochasticBlend) Children() []any             { return children(n) }
+func (n *StochasticBlend) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *StochasticBlend) Params() []Param             { return params(n) }
+
+func (n *JitterBlend) Children() []any             { return children(n) }
+func (n *JitterBlend) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *JitterBlend) Params() []Param             { return params(n) }
+
+func (n *SubstituteCombiner) Children() []any             { return children(n) }
+func (n *SubstituteCombiner) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *SubstituteCombiner) Params() []Param             { return params(n) }
+
+func (n *ThresholdCombiner) Children() []any             { return children(n) }
+func (n *ThresholdCombiner) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ThresholdCombiner) Params() []Param             { return params(n) }
+
+func (n *Component) Children() []any             { return children(n) }
+func (n *Component) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Component) Params() []Param             { return params(n) }
+
+func (n *Convolution) Children() []any             { return children(n) }
+func (n *Convolution) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Convolution) Params() []Param             { return params(n) }
+
+func (n *Displace) Children() []any             { return children(n) }
+func (n *Displace) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Displace) Params() []Param             { return params(n) }
+
+func (n *Displace2) Children() []any             { return children(n) }
+func (n *Displace2) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Displace2) Params() []Param             { return params(n) }
+
+func (n *DisplaceVF) Children() []any             { return children(n) }
+func (n *DisplaceVF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *DisplaceVF) Params() []Param             { return params(n) }
+
+func (n *Displace2VF) Children() []any             { return children(n) }
+func (n *Displace2VF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Displace2VF) Params() []Param             { return params(n) }
+
+func (n *DisplaceCF) Children() []any             { return children(n) }
+func (n *DisplaceCF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *DisplaceCF) Params() []Param             { return params(n) }
+
+func (n *Displace2CF) Children() []any             { return children(n) }
+func (n *Displace2CF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Displace2CF) Params() []Param             { return params(n) }
+
+func (n *Distort) Children() []any             { return children(n) }
+func (n *Distort) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Distort) Params() []Param             { return params(n) }
+
+func (n *NLFilter) Children() []any             { return children(n) }
+func (n *NLFilter) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *NLFilter) Params() []Param             { return params(n) }
+
+func (n *InvertFilter) Children() []any             { return children(n) }
+func (n *InvertFilter) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *InvertFilter) Params() []Param             { return params(n) }
+
+func (n *QuantizeFilter) Children() []any             { return children(n) }
+func (n *QuantizeFilter) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *QuantizeFilter) Params() []Param             { return params(n) }
+
+func (n *RandQuantFilter) Children() []any             { return children(n) }
+func (n *RandQuantFilter) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *RandQuantFilter) Params() []Param             { return params(n) }
+
+func (n *ClipFilter) Children() []any             { return children(n) }
+func (n *ClipFilter) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ClipFilter) Params() []Param             { return params(n) }
+
+func (n *OffsScaleFilter) Children() []any             { return children(n) }
+func (n *OffsScaleFilter) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *OffsScaleFilter) Params() []Param             { return params(n) }
+
+func (n *AbsFilter) Children() []any             { return children(n) }
+func (n *AbsFilter) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *AbsFilter) Params() []Param             { return params(n) }
+
+func (n *FoldFilter) Children() []any             { return children(n) }
+func (n *FoldFilter) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *FoldFilter) Params() []Param             { return params(n) }
+
+func (n *RemapFilter) Children() []any             { return children(n) }
+func (n *RemapFilter) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *RemapFilter) Params() []Param             { return params(n) }
+
+func (n *FloorFilter) Children() []any             { return children(n) }
+func (n *FloorFilter) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *FloorFilter) Params() []Param             { return params(n) }
+
+func (n *CeilFilter) Children() []any             { return children(n) }
+func (n *CeilFilter) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *CeilFilter) Params() []Param             { return params(n) }
+
+func (n *Fractal) Children() []any             { return children(n) }
+func (n *Fractal) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Fractal) Params() []Param             { return params(n) }
+
+func (n *VariableFractal) Children() []any             { return children(n) }
+func (n *VariableFractal) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *VariableFractal) Params() []Param             { return params(n) }
+
+func (n *FBM) Children() []any             { return children(n) }
+func (n *FBM) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *FBM) Params() []Param             { return params(n) }
+
+func (n *MF) Children() []any             { return children(n) }
+func (n *MF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *MF) Params() []Param             { return params(n) }
+
+func (n *LinearGradient) Children() []any             { return children(n) }
+func (n *LinearGradient) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *LinearGradient) Params() []Param             { return params(n) }
+
+func (n *RadialGradient) Children() []any             { return children(n) }
+func (n *RadialGradient) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *RadialGradient) Params() []Param             { return params(n) }
+
+func (n *ConicGradient) Children() []any             { return children(n) }
+func (n *ConicGradient) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ConicGradient) Params() []Param             { return params(n) }
+
+func (n *Gradient) Children() []any             { return children(n) }
+func (n *Gradient) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Gradient) Params() []Param             { return params(n) }
+
+func (n *IFS) Children() []any             { return children(n) }
+func (n *IFS) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *IFS) Params() []Param             { return params(n) }
+
+func (n *IFSCombiner) Children() []any             { return children(n) }
+func (n *IFSCombiner) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *IFSCombiner) Params() []Param             { return params(n) }
+
+func (n *Image) Children() []any             { return children(n) }
+func (n *Image) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Image) Params() []Param             { return params(n) }
+
+func (n *Layer) Children() []any             { return children(n) }
+func (n *Layer) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Layer) Params() []Param             { return params(n) }
+
+func (n *LayerStack) Children() []any             { return children(n) }
+func (n *LayerStack) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *LayerStack) Params() []Param             { return params(n) }
+
+func (n *Erode) Children() []any             { return children(n) }
+func (n *Erode) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Erode) Params() []Param             { return params(n) }
+
+func (n *Dilate) Children() []any             { return children(n) }
+func (n *Dilate) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Dilate) Params() []Param             { return params(n) }
+
+func (n *EdgeIn) Children() []any             { return children(n) }
+func (n *EdgeIn) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *EdgeIn) Params() []Param             { return params(n) }
+
+func (n *EdgeOut) Children() []any             { return children(n) }
+func (n *EdgeOut) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *EdgeOut) Params() []Param             { return params(n) }
+
+func (n *Edge) Children() []any             { return children(n) }
+func (n *Edge) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Edge) Params() []Param             { return params(n) }
+
+func (n *Close) Children() []any             { return children(n) }
+func (n *Close) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Close) Params() []Param             { return params(n) }
+
+func (n *Open) Children() []any             { return children(n) }
+func (n *Open) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Open) Params() []Param             { return params(n) }
+
+func (n *TopHat) Children() []any             { return children(n) }
+func (n *TopHat) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *TopHat) Params() []Param             { return params(n) }
+
+func (n *BottomHat) Children() []any             { return children(n) }
+func (n *BottomHat) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *BottomHat) Params() []Param             { return params(n) }
+
+func (n *ColorNormalMap) Children() []any             { return children(n) }
+func (n *ColorNormalMap) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorNormalMap) Params() []Param             { return params(n) }
+
+func (n *NormalMap) Children() []any             { return children(n) }
+func (n *NormalMap) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *NormalMap) Params() []Param             { return params(n) }
+
+func (n *NormalBlend) Children() []any             { return children(n) }
+func (n *NormalBlend) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *NormalBlend) Params() []Param             { return params(n) }
+
+func (n *Palette) Children() []any             { return children(n) }
+func (n *Palette) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Palette) Params() []Param             { return params(n) }
+
+func (n *PBRComponent) Children() []any             { return children(n) }
+func (n *PBRComponent) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *PBRComponent) Params() []Param             { return params(n) }
+
+func (n *Perlin) Children() []any             { return children(n) }
+func (n *Perlin) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Perlin) Params() []Param             { return params(n) }
+
+func (n *Pixelate) Children() []any             { return children(n) }
+func (n *Pixelate) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Pixelate) Params() []Param             { return params(n) }
+
+func (n *PixelateVF) Children() []any             { return children(n) }
+func (n *PixelateVF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *PixelateVF) Params() []Param             { return params(n) }
+
+func (n *PixelateCF) Children() []any             { return children(n) }
+func (n *PixelateCF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *PixelateCF) Params() []Param             { return params(n) }
+
+func (n *BlinnField) Children() []any             { return children(n) }
+func (n *BlinnField) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *BlinnField) Params() []Param             { return params(n) }
+
+func (n *WorleyField) Children() []any             { return children(n) }
+func (n *WorleyField) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *WorleyField) Params() []Param             { return params(n) }
+
+func (n *ColorQuantize) Children() []any             { return children(n) }
+func (n *ColorQuantize) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorQuantize) Params() []Param             { return params(n) }
+
+func (n *Reflect) Children() []any             { return children(n) }
+func (n *Reflect) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Reflect) Params() []Param             { return params(n) }
+
+func (n *ReflectVF) Children() []any             { return children(n) }
+func (n *ReflectVF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ReflectVF) Params() []Param             { return params(n) }
+
+func (n *ReflectCF) Children() []any             { return children(n) }
+func (n *ReflectCF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ReflectCF) Params() []Param             { return params(n) }
+
+func (n *Shape) Children() []any             { return children(n) }
+func (n *Shape) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Shape) Params() []Param             { return params(n) }
+
+func (n *ShapeCombiner) Children() []any             { return children(n) }
+func (n *ShapeCombiner) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ShapeCombiner) Params() []Param             { return params(n) }
+
+func (n *ShapeCombinerCF) Children() []any             { return children(n) }
+func (n *ShapeCombinerCF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ShapeCombinerCF) Params() []Param             { return params(n) }
+
+func (n *ShapeCombinerVF) Children() []any             { return children(n) }
+func (n *ShapeCombinerVF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ShapeCombinerVF) Params() []Param             { return params(n) }
+
+func (n *Strip) Children() []any             { return children(n) }
+func (n *Strip) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Strip) Params() []Param             { return params(n) }
+
+func (n *StripCF) Children() []any             { return children(n) }
+func (n *StripCF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *StripCF) Params() []Param             { return params(n) }
+
+func (n *StripVF) Children() []any             { return children(n) }
+func (n *StripVF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *StripVF) Params() []Param             { return params(n) }
+
+func (n *ThresholdFilter) Children() []any             { return children(n) }
+func (n *ThresholdFilter) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ThresholdFilter) Params() []Param             { return params(n) }
+
+func (n *Tiler) Children() []any             { return children(n) }
+func (n *Tiler) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Tiler) Params() []Param             { return params(n) }
+
+func (n *TilerCF) Children() []any             { return children(n) }
+func (n *TilerCF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *TilerCF) Params() []Param             { return params(n) }
+
+func (n *TilerVF) Children() []any             { return children(n) }
+func (n *TilerVF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *TilerVF) Params() []Param             { return params(n) }
+
+func (n *StochasticTiler) Children() []any             { return children(n) }
+func (n *StochasticTiler) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *StochasticTiler) Params() []Param             { return params(n) }
+
+func (n *StochasticTilerCF) Children() []any             { return children(n) }
+func (n *StochasticTilerCF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *StochasticTilerCF) Params() []Param             { return params(n) }
+
+func (n *StochasticTilerVF) Children() []any             { return children(n) }
+func (n *StochasticTilerVF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *StochasticTilerVF) Params() []Param             { return params(n) }
+
+func (n *ToneMap) Children() []any             { return children(n) }
+func (n *ToneMap) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ToneMap) Params() []Param             { return params(n) }
+
+func (n *ColorHighlights) Children() []any             { return children(n) }
+func (n *ColorHighlights) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorHighlights) Params() []Param             { return params(n) }
+
+func (n *ColorBloom) Children() []any             { return children(n) }
+func (n *ColorBloom) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorBloom) Params() []Param             { return params(n) }
+
+func (n *Transform) Children() []any             { return children(n) }
+func (n *Transform) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Transform) Params() []Param             { return params(n) }
+
+func (n *TransformVF) Children() []any             { return children(n) }
+func (n *TransformVF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *TransformVF) Params() []Param             { return params(n) }
+
+func (n *TransformCF) Children() []any             { return children(n) }
+func (n *TransformCF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *TransformCF) Params() []Param             { return params(n) }
+
+func (n *Uniform) Children() []any             { return children(n) }
+func (n *Uniform) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Uniform) Params() []Param             { return params(n) }
+
+func (n *UniformCF) Children() []any             { return children(n) }
+func (n *UniformCF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *UniformCF) Params() []Param             { return params(n) }
+
+func (n *UniformVF) Children() []any             { return children(n) }
+func (n *UniformVF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *UniformVF) Params() []Param             { return params(n) }
+
+func (n *ColorToGray) Children() []any             { return children(n) }
+func (n *ColorToGray) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorToGray) Params() []Param             { return params(n) }
+
+func (n *ColorSelect) Children() []any             { return children(n) }
+func (n *ColorSelect) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ColorSelect) Params() []Param             { return params(n) }
+
+func (n *Direction) Children() []any             { return children(n) }
+func (n *Direction) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Direction) Params() []Param             { return params(n) }
+
+func (n *Magnitude) Children() []any             { return children(n) }
+func (n *Magnitude) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Magnitude) Params() []Param             { return params(n) }
+
+func (n *Select) Children() []any             { return children(n) }
+func (n *Select) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Select) Params() []Param             { return params(n) }
+
+func (n *Weighted) Children() []any             { return children(n) }
+func (n *Weighted) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Weighted) Params() []Param             { return params(n) }
+
+func (n *VectorFields) Children() []any             { return children(n) }
+func (n *VectorFields) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *VectorFields) Params() []Param             { return params(n) }
+
+func (n *VectorColor) Children() []any             { return children(n) }
+func (n *VectorColor) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *VectorColor) Params() []Param             { return params(n) }
+
+func (n *Normal) Children() []any             { return children(n) }
+func (n *Normal) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Normal) Params() []Param             { return params(n) }
+
+func (n *UnitVector) Children() []any             { return children(n) }
+func (n *UnitVector) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *UnitVector) Params() []Param             { return params(n) }
+
+func (n *Warp) Children() []any             { return children(n) }
+func (n *Warp) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *Warp) Params() []Param             { return params(n) }
+
+func (n *WarpVF) Children() []any             { return children(n) }
+func (n *WarpVF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *WarpVF) Params() []Param             { return params(n) }
+
+func (n *WarpCF) Children() []any             { return children(n) }
+func (n *WarpCF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *WarpCF) Params() []Param             { return params(n) }
+
+func (n *RadialWF) Children() []any             { return children(n) }
+func (n *RadialWF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *RadialWF) Params() []Param             { return params(n) }
+
+func (n *SwirlWF) Children() []any             { return children(n) }
+func (n *SwirlWF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *SwirlWF) Params() []Param             { return params(n) }
+
+func (n *DrainWF) Children() []any             { return children(n) }
+func (n *DrainWF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *DrainWF) Params() []Param             { return params(n) }
+
+func (n *RadialNLWF) Children() []any             { return children(n) }
+func (n *RadialNLWF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *RadialNLWF) Params() []Param             { return params(n) }
+
+func (n *PinchXWF) Children() []any             { return children(n) }
+func (n *PinchXWF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *PinchXWF) Params() []Param             { return params(n) }
+
+func (n *RippleXWF) Children() []any             { return children(n) }
+func (n *RippleXWF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *RippleXWF) Params() []Param             { return params(n) }
+
+func (n *RadialRippleWF) Children() []any             { return children(n) }
+func (n *RadialRippleWF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *RadialRippleWF) Params() []Param             { return params(n) }
+
+func (n *RadialWiggleWF) Children() []any             { return children(n) }
+func (n *RadialWiggleWF) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *RadialWiggleWF) Params() []Param             { return params(n) }
+
+func (n *NLWave) Children() []any             { return children(n) }
+func (n *NLWave) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *NLWave) Params() []Param             { return params(n) }
+
+func (n *DCWave) Children() []any             { return children(n) }
+func (n *DCWave) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *DCWave) Params() []Param             { return params(n) }
+
+func (n *ACWave) Children() []any             { return children(n) }
+func (n *ACWave) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *ACWave) Params() []Param             { return params(n) }
+
+func (n *PatternWave) Children() []any             { return children(n) }
+func (n *PatternWave) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *PatternWave) Params() []Param             { return params(n) }
+
+func (n *InvertWave) Children() []any             { return children(n) }
+func (n *InvertWave) SetChild(i int, c any) error { return setChild(n, i, c) }
+func (n *InvertWave) Params() []Param             { return params(n) }
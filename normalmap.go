@@ -0,0 +1,123 @@
+package texture
+
+import (
+	tcol "github.com/jphsd/texture/color"
+	"image"
+	"image/color"
+	"math"
+)
+
+// NormalMapType defines the convention used to encode the Y component of a normal in a normal map.
+// OpenGL normal maps have +Y pointing up the image (green up), DirectX normal maps have +Y pointing down
+// the image (green down). Normals produced by [Normal] have +Y pointing down.
+type NormalMapType int
+
+// Constants for normal map conventions.
+const (
+	OpenGLNormalMap NormalMapType = iota
+	DirectXNormalMap
+)
+
+// ColorNormalMap encodes a vector field of unit normals as normal map colors, mapping X, Y and Z from [-1,1]
+// to R, G and B in [0,1].
+type ColorNormalMap struct {
+	Name string
+	Src  VectorField
+	Type NormalMapType
+}
+
+func NewColorNormalMap(src VectorField, typ NormalMapType) *ColorNormalMap {
+	return &ColorNormalMap{"ColorNormalMap", src, typ}
+}
+
+// Eval2 implements the ColorField interface.
+func (c *ColorNormalMap) Eval2(x, y float64) color.Color {
+	v := c.Src.Eval2(x, y)
+	ny := v[1]
+	if c.Type == OpenGLNormalMap {
+		ny = -ny
+	}
+	return tcol.FRGBA{bcclamp((v[0] + 1) / 2), bcclamp((ny + 1) / 2), bcclamp((v[2] + 1) / 2), 1}
+}
+
+// NormalMap decodes the colors of a normal map, typically loaded through [Image], to unit normals.
+type NormalMap struct {
+	Name string
+	Src  ColorField
+	Type NormalMapType
+}
+
+func NewNormalMap(src ColorField, typ NormalMapType) *NormalMap {
+	return &NormalMap{"NormalMap", src, typ}
+}
+
+// NewNormalMapImage returns a new NormalMap for the image using bilinear interpolation.
+func NewNormalMapImage(img image.Image, typ NormalMapType) *NormalMap {
+	return NewNormalMap(NewImage(img, LinearInterp), typ)
+}
+
+// Eval2 implements the VectorField interface.
+func (n *NormalMap) Eval2(x, y float64) []float64 {
+	c := tcol.NewFRGBA(n.Src.Eval2(x, y))
+	nx, ny, nz := c.R*2-1, c.G*2-1, c.B*2-1
+	if n.Type == OpenGLNormalMap {
+		ny = -ny
+	}
+	return unitNormal(nx, ny, nz)
+}
+
+// NormalBlendType defines the method used to blend two normal maps.
+type NormalBlendType int
+
+// Constants for normal blend types.
+const (
+	WhiteoutBlend NormalBlendType = iota
+	RNMBlend
+	UDNBlend
+)
+
+// NormalBlend layers the detail normals from Src2 onto the base normals from Src1 using one of the
+// whiteout, reoriented normal mapping (RNM) or unreal developer network (UDN) methods.
+// See [Barre-Brisebois12].
+type NormalBlend struct {
+	Name string
+	Src1 VectorField
+	Src2 VectorField
+	Type NormalBlendType
+}
+
+func NewNormalBlend(src1, src2 VectorField, typ NormalBlendType) *NormalBlend {
+	return &NormalBlend{"NormalBlend", src1, src2, typ}
+}
+
+// Eval2 implements the VectorField interface.
+func (b *NormalBlend) Eval2(x, y float64) []float64 {
+	n1, n2 := b.Src1.Eval2(x, y), b.Src2.Eval2(x, y)
+	switch b.Type {
+	default:
+		fallthrough
+	case WhiteoutBlend:
+		return unitNormal(n1[0]+n2[0], n1[1]+n2[1], n1[2]*n2[2])
+	case RNMBlend:
+		// t = n1 + {0, 0, 1}, u = n2 * {-1, -1, 1}, r = t * t.u / t.z - u
+		tx, ty, tz := n1[0], n1[1], n1[2]+1
+		if tz < 0.000001 {
+			// Base normal points straight down
+			return unitNormal(n2[0], n2[1], n2[2])
+		}
+		ux, uy, uz := -n2[0], -n2[1], n2[2]
+		s := (tx*ux + ty*uy + tz*uz) / tz
+		return unitNormal(tx*s-ux, ty*s-uy, tz*s-uz)
+	case UDNBlend:
+		return unitNormal(n1[0]+n2[0], n1[1]+n2[1], n1[2])
+	}
+}
+
+func unitNormal(x, y, z float64) []float64 {
+	s := math.Sqrt(x*x + y*y + z*z)
+	if s < 0.000001 {
+		return []float64{0, 0, 1}
+	}
+	s = 1 / s
+	return []float64{x * s, y * s, z * s}
+}
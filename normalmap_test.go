@@ -0,0 +1,33 @@
+package texture_test
+
+import (
+	"github.com/jphsd/texture"
+	"math"
+	"testing"
+)
+
+func TestNormalBlendRNM(t *testing.T) {
+	s := 1 / math.Sqrt(3)
+	detail := texture.NewUniformVF([]float64{s, -s, s})
+	tests := []struct {
+		name string
+		base []float64
+		want []float64
+	}{
+		// A flat base leaves the detail unchanged
+		{"flat", []float64{0, 0, 1}, []float64{s, -s, s}},
+		// As does a base pointing straight down, rather than dividing by zero
+		{"down", []float64{0, 0, -1}, []float64{s, -s, s}},
+		{"nearly down", []float64{0, 0, -1 + 1e-9}, []float64{s, -s, s}},
+	}
+	for _, test := range tests {
+		b := texture.NewNormalBlend(texture.NewUniformVF(test.base), detail, texture.RNMBlend)
+		n := b.Eval2(1, 2)
+		for i := range n {
+			if math.IsNaN(n[i]) || math.Abs(n[i]-test.want[i]) > 1e-9 {
+				t.Errorf("%s: %v, expected %v", test.name, n, test.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package texture
+
+import (
+	"fmt"
+	g2d "github.com/jphsd/graphics2d"
+	"reflect"
+	"slices"
+)
+
+// OptimizeStats reports the changes made by [Optimize]. Evals estimates the number of Eval2 calls made
+// per sample, assuming each node evaluates each of its inputs once.
+type OptimizeStats struct {
+	Nodes, OptNodes int     // Distinct nodes before and after
+	Evals, OptEvals float64 // Estimated evaluations per sample before and after
+	Folded          int     // Nodes replaced by uniforms
+	Merged          int     // Transform and InvertFilter pairs merged
+	Pruned          int     // Nodes replaced by one of their inputs
+	Shared          int     // Subtrees replaced by an equal one
+}
+
+// Speedup returns the estimated speedup of the optimized tree.
+func (s *OptimizeStats) Speedup() float64 {
+	if s.OptEvals == 0 {
+		return 1
+	}
+	return s.Evals / s.OptEvals
+}
+
+func (s *OptimizeStats) String() string {
+	return fmt.Sprintf("nodes %d -> %d, evaluations %g -> %g per sample (%.2fx), %d folded, %d merged, %d pruned, %d shared",
+		s.Nodes, s.OptNodes, s.Evals, s.OptEvals, s.Speedup(), s.Folded, s.Merged, s.Pruned, s.Shared)
+}
+
+// optPointwise lists the nodes whose value at a point depends only on the values of their inputs, at
+// that or another point, so that they're constant if their inputs are.
+var optPointwise = []string{
+	"MulCombiner", "AddCombiner", "SubCombiner", "MinCombiner", "MaxCombiner", "AvgCombiner",
+	"DiffCombiner", "WindowedCombiner", "WeightedCombiner", "Blend", "SubstituteCombiner",
+	"ThresholdCombiner", "NLFilter", "InvertFilter", "QuantizeFilter", "ClipFilter",
+	"OffsScaleFilter", "AbsFilter", "FoldFilter", "RandQuantFilter", "RemapFilter", "FloorFilter",
+	"CeilFilter", "ColorGray", "ColorSinCos", "ColorConv", "ColorFields", "ColorVector", "ColorBlend",
+	"ColorModeBlend", "ColorComposite", "ColorAlpha", "ColorSubstitute", "ColorLevels", "ColorCurves",
+	"ColorHSLAdjust", "ColorBalance", "ColorMixer", "ColorInvert", "ColorTint", "Transform",
+	"TransformVF", "TransformCF",
+}
+
+// Optimize returns an optimized copy of the tree rooted at n and what was changed. Nodes whose inputs
+// are all uniform are replaced by uniforms, as are products with a zero uniform, and sums with a zero
+// uniform and products with a unit one by their other input. Consecutive transforms are merged into
+// one and InvertFilter pairs removed. ThresholdCombiners and SubstituteCombiners whose selection is
+// fixed are replaced by the selected input. Finally, structurally equal subtrees, other than those
+// holding functions, are replaced by a single shared one, which reduces the size of the tree, and lets
+// caches be shared, but not the number of evaluations.
+//
+// Field values are assumed to be in [-1,1], so that clamping them has no effect.
+func Optimize(n any) (any, *OptimizeStats) {
+	o := &optimizer{memo: map[any]any{}, canon: map[string][]any{}}
+	o.stats.Nodes, o.stats.Evals = optCount(n)
+	res := o.optimize(Clone(n))
+	o.stats.OptNodes, o.stats.OptEvals = optCount(res)
+	return res, &o.stats
+}
+
+type optimizer struct {
+	memo  map[any]any      // Optimized nodes
+	canon map[string][]any // Canonical nodes by type and inputs
+	stats OptimizeStats
+}
+
+// optimize optimizes the inputs of n and then n itself.
+func (o *optimizer) optimize(n any) any {
+	if n == nil {
+		return nil
+	}
+	if res, ok := o.memo[n]; ok {
+		return res
+	}
+	res := n
+	if nd, ok := n.(Node); ok {
+		for i, c := range nd.Children() {
+			if c == nil {
+				continue
+			}
+			// Replacements that don't fit the slot are dropped
+			if oc := o.optimize(c); oc != c {
+				_ = nd.SetChild(i, oc)
+			}
+		}
+		for i := 0; i < 100; i++ {
+			r := o.rewrite(res)
+			if r == nil {
+				break
+			}
+			res = r
+		}
+		res = o.share(res)
+	}
+	o.memo[n] = res
+	return res
+}
+
+// rewrite returns the simplified form of n, or nil if there isn't one.
+func (o *optimizer) rewrite(n any) any {
+	switch n := n.(type) {
+	case *MulCombiner:
+		if n.Offs != 0 || n.Scal != 1 {
+			break
+		}
+		if optUniform(n.Src1, 0) || optUniform(n.Src2, 0) {
+			o.stats.Folded++
+			return NewUniform(0)
+		}
+		if r := o.other(n.Src1, n.Src2, 1); r != nil {
+			return r
+		}
+	case *AddCombiner:
+		if n.Offs != 0 || n.Scal != 1 {
+			break
+		}
+		if r := o.other(n.Src1, n.Src2, 0); r != nil {
+			return r
+		}
+	case *SubCombiner:
+		if n.Offs == 0 && n.Scal == 1 && n.Src1 != nil && optUniform(n.Src2, 0) {
+			o.stats.Pruned++
+			return n.Src1
+		}
+	case *InvertFilter:
+		if in, ok := n.Src.(*InvertFilter); ok && in.Src != nil {
+			o.stats.Merged++
+			return in.Src
+		}
+	case *ThresholdCombiner:
+		if n.Src1 == nil || n.Src2 == nil {
+			break
+		}
+		u, ok := n.Src3.(*Uniform)
+		switch {
+		case n.Src1 == n.Src2, n.A > 1, ok && u.Value < n.A:
+			o.stats.Pruned++
+			return n.Src1
+		case n.A <= -1, ok:
+			o.stats.Pruned++
+			return n.Src2
+		}
+	case *SubstituteCombiner:
+		if n.Src1 == nil || n.Src2 == nil {
+			break
+		}
+		u, ok := n.Src3.(*Uniform)
+		switch {
+		case n.Src1 == n.Src2, n.A > n.B, ok && (u.Value < n.A || u.Value > n.B):
+			o.stats.Pruned++
+			return n.Src1
+		case ok:
+			o.stats.Pruned++
+			return n.Src2
+		}
+	case *Transform:
+		if in, ok := n.Src.(*Transform); ok && o.merge(&n.Xfm, in.Xfm) {
+			n.Src = in.Src
+			return n
+		}
+		if optIdentity(n.Xfm) && n.Src != nil {
+			o.stats.Pruned++
+			return n.Src
+		}
+	case *TransformVF:
+		if in, ok := n.Src.(*TransformVF); ok && o.merge(&n.Xfm, in.Xfm) {
+			n.Src = in.Src
+			return n
+		}
+		if optIdentity(n.Xfm) && n.Src != nil {
+			o.stats.Pruned++
+			return n.Src
+		}
+	case *TransformCF:
+		if in, ok := n.Src.(*TransformCF); ok && o.merge(&n.Xfm, in.Xfm) {
+			n.Src = in.Src
+			return n
+		}
+		if optIdentity(n.Xfm) && n.Src != nil {
+			o.stats.Pruned++
+			return n.Src
+		}
+	}
+	if r := optFold(n); r != nil {
+		o.stats.Folded++
+		return r
+	}
+	return nil
+}
+
+// other returns the input of a pair that isn't a uniform with value v, if the other is.
+func (o *optimizer) other(src1, src2 Field, v float64) Field {
+	res := Field(nil)
+	switch {
+	case optUniform(src1, v):
+		res = src2
+	case optUniform(src2, v):
+		res = src1
+	}
+	if res != nil {
+		o.stats.Pruned++
+	}
+	return res
+}
+
+// merge sets the outer transform to one that applies it and then the inner one, unless either is nil.
+func (o *optimizer) merge(outer **g2d.Aff3, inner *g2d.Aff3) bool {
+	if *outer == nil || inner == nil {
+		return false
+	}
+	*outer = inner.Copy().Concatenate(**outer)
+	o.stats.Merged++
+	return true
+}
+
+// share returns the first node seen that's equal to n, with the same type, inputs, parameters and state.
+func (o *optimizer) share(n any) any {
+	nd, ok := n.(Node)
+	if !ok {
+		return n
+	}
+	key := reflect.TypeOf(n).String()
+	for _, c := range nd.Children() {
+		key += fmt.Sprintf(" %p", c)
+	}
+	for _, c := range o.canon[key] {
+		if c == n {
+			return n
+		}
+		// Compare the whole nodes, unexported state included. Functions are never equal, so nodes
+		// holding them aren't shared.
+		if reflect.DeepEqual(c, n) {
+			o.stats.Shared++
+			return c
+		}
+	}
+	o.canon[key] = append(o.canon[key], n)
+	return n
+}
+
+// optFold returns a uniform with the value of n if n is pointwise and all its inputs are uniform.
+func optFold(n any) (res any) {
+	nd, ok := n.(Node)
+	if !ok || !slices.Contains(optPointwise, reflect.TypeOf(n).Elem().Name()) {
+		return nil
+	}
+	inputs := 0
+	for _, c := range nd.Children() {
+		switch c.(type) {
+		case nil:
+			continue
+		case *Uniform, *UniformVF, *UniformCF:
+			inputs++
+		default:
+			return nil
+		}
+	}
+	if inputs == 0 {
+		return nil
+	}
+
+	// Leave nodes that can't be evaluated alone
+	defer func() {
+		if recover() != nil {
+			res = nil
+		}
+	}()
+	switch f := n.(type) {
+	case Field:
+		return NewUniform(f.Eval2(0, 0))
+	case VectorField:
+		return NewUniformVF(slices.Clone(f.Eval2(0, 0)))
+	case ColorField:
+		return NewUniformCF(f.Eval2(0, 0))
+	}
+	return nil
+}
+
+func optUniform(f Field, v float64) bool {
+	u, ok := f.(*Uniform)
+	return ok && u.Value == v
+}
+
+func optIdentity(xfm *g2d.Aff3) bool {
+	return xfm != nil && *xfm == g2d.Aff3{1, 0, 0, 0, 1, 0}
+}
+
+// optCount returns the number of distinct nodes in the tree rooted at n and the number of evaluations per
+// sample.
+func optCount(n any) (int, float64) {
+	nodes := 0
+	Walk(n, func(any) bool {
+		nodes++
+		return true
+	})
+	return nodes, optEvals(n, map[any]float64{})
+}
+
+func optEvals(n any, memo map[any]float64) float64 {
+	if n == nil {
+		return 0
+	}
+	if res, ok := memo[n]; ok {
+		return res
+	}
+	res := 1.0
+	if nd, ok := n.(Node); ok {
+		for _, c := range nd.Children() {
+			res += optEvals(c, memo)
+		}
+	}
+	memo[n] = res
+	return res
+}
@@ -0,0 +1,81 @@
+package texture_test
+
+import (
+	"github.com/jphsd/graphics2d"
+	"github.com/jphsd/texture"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// testOptTree returns a tree with something for each of Optimize's rewrites.
+func testOptTree() texture.Field {
+	w := texture.NewNLWave([]float64{31}, []*texture.NonLinear{texture.NewNLSin()}, true, false)
+	grad := texture.NewLinearGradient(w)
+
+	// Equal subtrees built separately
+	p1 := texture.NewTransform(texture.NewPerlin(5), graphics2d.Scale(0.05, 0.05))
+	p2 := texture.NewTransform(texture.NewPerlin(5), graphics2d.Scale(0.05, 0.05))
+
+	// Worley fields differing only in their distance function
+	pts := [][]float64{{10, 10}, {60, 25}, {30, 70}, {90, 90}}
+	b := []float64{1}
+	ident := func(v float64) float64 { return v }
+	we := texture.NewWorleyField(pts, nil, b, func(a, b []float64) float64 {
+		return math.Hypot(a[0]-b[0], a[1]-b[1])
+	}, ident, 0.02, -1)
+	wm := texture.NewWorleyField(pts, nil, b, func(a, b []float64) float64 {
+		return math.Abs(a[0]-b[0]) + math.Abs(a[1]-b[1])
+	}, ident, 0.02, -1)
+
+	// Folds, prunes and merges
+	half := texture.NewAddCombiner(texture.NewUniform(0.25), texture.NewUniform(0.25))
+	tf := texture.NewTransform(texture.NewTransform(grad, graphics2d.Rotate(0.3)), graphics2d.Translate(5, 7))
+	inv := texture.NewInvertFilter(texture.NewInvertFilter(tf))
+	unit := texture.NewMulCombiner(inv, texture.NewUniform(1))
+	zero := texture.NewAddCombiner(texture.NewUniform(0), p1)
+
+	return texture.NewAvgCombiner(
+		texture.NewMulCombiner(unit, texture.NewAddCombiner(zero, half)),
+		texture.NewAvgCombiner(texture.NewMulCombiner(p2, we), wm))
+}
+
+func TestOptimize(t *testing.T) {
+	tree := testOptTree()
+	v, stats := texture.Optimize(tree)
+	opt, ok := v.(texture.Field)
+	if !ok {
+		t.Fatalf("Optimize returned %T", v)
+	}
+	if stats.Folded == 0 || stats.Pruned == 0 || stats.Merged == 0 || stats.Shared == 0 {
+		t.Errorf("rewrites missing from %s", stats)
+	}
+	if stats.OptNodes >= stats.Nodes {
+		t.Errorf("node count not reduced: %s", stats)
+	}
+
+	// The Worley fields mustn't be shared
+	worley := 0
+	texture.Walk(opt, func(n any) bool {
+		if _, ok := n.(*texture.WorleyField); ok {
+			worley++
+		}
+		return true
+	})
+	if worley != 2 {
+		t.Errorf("%d Worley fields after Optimize, expected 2", worley)
+	}
+
+	// Renders match but for rounding in the merged transforms
+	img1 := texture.NewTextureGray16(100, 100, tree, 0, 0, 1, 1, false)
+	img2 := texture.NewTextureGray16(100, 100, opt, 0, 0, 1, 1, false)
+	for y := range 100 {
+		for x := range 100 {
+			v1 := int(color.Gray16Model.Convert(img1.At(x, y)).(color.Gray16).Y)
+			v2 := int(color.Gray16Model.Convert(img2.At(x, y)).(color.Gray16).Y)
+			if v1-v2 > 1 || v2-v1 > 1 {
+				t.Fatalf("(%d,%d) is %d before and %d after Optimize", x, y, v1, v2)
+			}
+		}
+	}
+}
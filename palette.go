@@ -0,0 +1,101 @@
+package texture
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Palette is a named list of colors, such as is loaded from a GIMP .gpl or Paint.NET palette file.
+type Palette struct {
+	Name   string
+	Colors []color.Color
+}
+
+// ColorConv returns a [ColorConv] that maps the values from src to the palette colors, spaced evenly
+// over [-1,1] and interpolated with lerp.
+func (p *Palette) ColorConv(src Field, lerp LerpType) *ColorConv {
+	n := len(p.Colors)
+	if n == 0 {
+		return NewColorConv(src, color.Transparent, color.Transparent, nil, nil, lerp)
+	}
+	if n == 1 {
+		return NewColorConv(src, p.Colors[0], p.Colors[0], nil, nil, lerp)
+	}
+	tvals := make([]float64, n-2)
+	for i := range tvals {
+		tvals[i] = float64(i+1) / float64(n-1)
+	}
+	return NewColorConv(src, p.Colors[0], p.Colors[n-1], p.Colors[1:n-1], tvals, lerp)
+}
+
+// ReadGPL reads a palette in GIMP's .gpl format.
+func ReadGPL(r io.Reader) (*Palette, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "GIMP Palette" {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("not a GIMP palette")
+	}
+
+	p := &Palette{}
+	for ln := 2; scanner.Scan(); ln++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if name, ok := strings.CutPrefix(line, "Name:"); ok {
+			p.Name = strings.TrimSpace(name)
+			continue
+		}
+		if strings.HasPrefix(line, "Columns:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("line %d: expected r g b values", ln)
+		}
+		var v [3]uint8
+		for i := range v {
+			c, err := strconv.ParseUint(fields[i], 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", ln, err)
+			}
+			v[i] = uint8(c)
+		}
+		p.Colors = append(p.Colors, color.NRGBA{v[0], v[1], v[2], 0xff})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ReadPDNPalette reads a Paint.NET palette, which contains one AARRGGBB hex color per line and comments
+// starting with ';'.
+func ReadPDNPalette(r io.Reader) (*Palette, error) {
+	scanner := bufio.NewScanner(r)
+	p := &Palette{}
+	for ln := 1; scanner.Scan(); ln++ {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, ";"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+		v, err := strconv.ParseUint(line, 16, 32)
+		if err != nil || len(line) != 8 {
+			return nil, fmt.Errorf("line %d: expected AARRGGBB hex color", ln)
+		}
+		p.Colors = append(p.Colors, color.NRGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), uint8(v >> 24)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
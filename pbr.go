@@ -0,0 +1,177 @@
+package texture
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jphsd/graphics2d/image"
+	"image/color"
+	"os"
+	"path/filepath"
+)
+
+// PBRComponent extends a [Component] with the roughness, metallic and ambient occlusion fields needed to
+// describe a physically based material. Field values in [-1,1] are mapped to [0,1] when realized.
+// If Roughness, Metallic or AO are nil, then 0.5, 0 and 1 are used respectively. If Color is nil the albedo
+// is white, and if Value or Vector are nil the surface is flat.
+type PBRComponent struct {
+	Name      string
+	Value     Field
+	Vector    VectorField
+	Color     ColorField
+	Roughness Field
+	Metallic  Field
+	AO        Field
+}
+
+// NewPBRComponent returns a new PBRComponent from the component and the additional fields.
+func NewPBRComponent(c *Component, rough, metal, ao Field) *PBRComponent {
+	return &PBRComponent{"PBRComponent", c.Value, c.Vector, c.Color, rough, metal, ao}
+}
+
+// PBR map suffixes used by SavePBR.
+const (
+	AlbedoSuffix    = "albedo"
+	NormalSuffix    = "normal"
+	HeightSuffix    = "height"
+	RoughnessSuffix = "roughness"
+	MetallicSuffix  = "metallic"
+	AOSuffix        = "ao"
+	ORMSuffix       = "orm"
+)
+
+// SavePBR realizes the component's maps over the region defined by the width, height, origin and step
+// values, and saves them as aligned PNG files named name_albedo, name_normal, name_height, name_roughness,
+// name_metallic, name_ao and name_orm. The ORM map packs AO, roughness and metallic into R, G and B.
+// Normals are encoded using the OpenGL convention. A glTF 2.0 document, name.gltf, containing a material
+// that references the maps is also written.
+func SavePBR(c *PBRComponent, name string, width, height int, ox, oy, dx, dy float64) error {
+	rough, metal, ao := c.Roughness, c.Metallic, c.AO
+	if rough == nil {
+		rough = NewUniform(0)
+	}
+	if metal == nil {
+		metal = NewUniform(-1)
+	}
+	if ao == nil {
+		ao = NewUniform(1)
+	}
+	vector := c.Vector
+	if vector == nil {
+		vector = DefaultNormal
+	}
+	value, col := c.Value, c.Color
+	if value == nil {
+		value = NewUniform(-1)
+	}
+	if col == nil {
+		col = NewUniformCF(color.White)
+	}
+
+	maps := []struct {
+		sfx string
+		img image.Image
+	}{
+		{AlbedoSuffix, NewTextureRGBA(width, height, col, ox, oy, dx, dy, false)},
+		{NormalSuffix, NewTextureRGBA(width, height, NewColorNormalMap(vector, OpenGLNormalMap), ox, oy, dx, dy, false)},
+		{HeightSuffix, NewTextureGray16(width, height, value, ox, oy, dx, dy, false)},
+		{RoughnessSuffix, NewTextureGray16(width, height, rough, ox, oy, dx, dy, false)},
+		{MetallicSuffix, NewTextureGray16(width, height, metal, ox, oy, dx, dy, false)},
+		{AOSuffix, NewTextureGray16(width, height, ao, ox, oy, dx, dy, false)},
+		{ORMSuffix, NewTextureRGBA(width, height, NewColorFields(ao, rough, metal, nil, false), ox, oy, dx, dy, false)},
+	}
+	for _, m := range maps {
+		if err := image.SaveImage(m.img, fmt.Sprintf("%s_%s", name, m.sfx)); err != nil {
+			return err
+		}
+	}
+
+	return saveGLTFMaterial(name)
+}
+
+// Minimal glTF 2.0 document structure for a single material.
+type gltfDoc struct {
+	Asset     gltfAsset      `json:"asset"`
+	Images    []gltfImage    `json:"images"`
+	Samplers  []gltfSampler  `json:"samplers"`
+	Textures  []gltfTexture  `json:"textures"`
+	Materials []gltfMaterial `json:"materials"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator"`
+}
+
+type gltfImage struct {
+	URI string `json:"uri"`
+}
+
+type gltfSampler struct {
+	MagFilter int `json:"magFilter"`
+	MinFilter int `json:"minFilter"`
+	WrapS     int `json:"wrapS"`
+	WrapT     int `json:"wrapT"`
+}
+
+type gltfTexture struct {
+	Sampler int `json:"sampler"`
+	Source  int `json:"source"`
+}
+
+type gltfTextureInfo struct {
+	Index int `json:"index"`
+}
+
+type gltfPBR struct {
+	BaseColorTexture         gltfTextureInfo `json:"baseColorTexture"`
+	MetallicRoughnessTexture gltfTextureInfo `json:"metallicRoughnessTexture"`
+	MetallicFactor           float64         `json:"metallicFactor"`
+	RoughnessFactor          float64         `json:"roughnessFactor"`
+}
+
+type gltfMaterial struct {
+	Name                 string            `json:"name"`
+	PBRMetallicRoughness gltfPBR           `json:"pbrMetallicRoughness"`
+	NormalTexture        gltfTextureInfo   `json:"normalTexture"`
+	OcclusionTexture     gltfTextureInfo   `json:"occlusionTexture"`
+	Extras               map[string]string `json:"extras,omitempty"`
+}
+
+// saveGLTFMaterial writes name.gltf referencing the maps written by SavePBR. The height, roughness,
+// metallic and AO maps are referenced by file name in the material's extras since glTF has no slots for
+// them outside of the packed ORM map.
+func saveGLTFMaterial(name string) error {
+	base := filepath.Base(name)
+	uri := func(sfx string) string {
+		return fmt.Sprintf("%s_%s.png", base, sfx)
+	}
+
+	// Linear filtering, mipmapped minification and repeat wrapping
+	doc := gltfDoc{
+		Asset:    gltfAsset{"2.0", "github.com/jphsd/texture"},
+		Images:   []gltfImage{{uri(AlbedoSuffix)}, {uri(ORMSuffix)}, {uri(NormalSuffix)}},
+		Samplers: []gltfSampler{{9729, 9987, 10497, 10497}},
+		Textures: []gltfTexture{{0, 0}, {0, 1}, {0, 2}},
+		Materials: []gltfMaterial{{
+			Name:                 base,
+			PBRMetallicRoughness: gltfPBR{gltfTextureInfo{0}, gltfTextureInfo{1}, 1, 1},
+			NormalTexture:        gltfTextureInfo{2},
+			OcclusionTexture:     gltfTextureInfo{1},
+			Extras: map[string]string{
+				HeightSuffix:    uri(HeightSuffix),
+				RoughnessSuffix: uri(RoughnessSuffix),
+				MetallicSuffix:  uri(MetallicSuffix),
+				AOSuffix:        uri(AOSuffix),
+			},
+		}},
+	}
+
+	fDst, err := os.Create(fmt.Sprintf("%s.gltf", name))
+	if err != nil {
+		return err
+	}
+	defer fDst.Close()
+	enc := json.NewEncoder(fDst)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
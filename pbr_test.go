@@ -0,0 +1,21 @@
+package texture_test
+
+import (
+	"github.com/jphsd/texture"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSavePBRDefaults(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "flat")
+	c := &texture.PBRComponent{Name: "PBRComponent"}
+	if err := texture.SavePBR(c, name, 8, 8, 0, 0, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	for _, sfx := range []string{texture.AlbedoSuffix, texture.HeightSuffix, texture.ORMSuffix} {
+		if _, err := os.Stat(name + "_" + sfx + ".png"); err != nil {
+			t.Error(err)
+		}
+	}
+}
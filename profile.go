@@ -0,0 +1,247 @@
+package texture
+
+import (
+	"cmp"
+	"fmt"
+	"image/color"
+	"io"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Profile is an instrumented copy of a tree that counts the Eval2 calls made to each of its fields, vector
+// fields and color fields, and the time spent in them. Render Root in place of the tree and then write
+// the results with [Profile.WriteReport] or [Profile.WriteFolded]. The counters are safe for concurrent
+// renders.
+//
+// Only inputs held in Field, VectorField or ColorField slots are instrumented. The time spent in other
+// inputs, such as waves and warp functions, counts as their parent's own time, as does the overhead of
+// timing its inputs.
+type Profile struct {
+	Root  any            // The instrumented tree
+	Nodes []*ProfileNode // The nodes of the tree, parents first
+}
+
+// ProfileNode holds the counts for a node of a profiled tree. The IDs are those of the [Graph] of the
+// tree.
+type ProfileNode struct {
+	ID   string
+	Type string
+	Node any
+	in   []*profEdge // Instrumented references to the node
+	out  []*profEdge // Instrumented references to its inputs
+}
+
+// profEdge counts the calls made through a reference to a node.
+type profEdge struct {
+	to    *ProfileNode
+	calls atomic.Int64
+	ns    atomic.Int64
+}
+
+func (e *profEdge) record(start time.Time) {
+	e.calls.Add(1)
+	e.ns.Add(int64(time.Since(start)))
+}
+
+// NewProfile returns an instrumented copy of the tree rooted at tree.
+func NewProfile(tree any) *Profile {
+	root := Clone(tree)
+	p := &Profile{Root: root}
+	nodes := map[any]*ProfileNode{}
+	Walk(root, func(n any) bool {
+		pn := &ProfileNode{ID: fmt.Sprintf("n%d", len(p.Nodes)+1), Type: graphType(n), Node: n}
+		nodes[n] = pn
+		p.Nodes = append(p.Nodes, pn)
+		return true
+	})
+
+	// Replace the inputs with counting wrappers once all the nodes are known
+	for _, pn := range p.Nodes {
+		nd, ok := pn.Node.(Node)
+		if !ok {
+			continue
+		}
+		for i, c := range nd.Children() {
+			if c == nil {
+				continue
+			}
+			e := &profEdge{to: nodes[c]}
+			w := profWrap(c, e)
+			if w == nil {
+				continue
+			}
+			// Nodes that can't be restored still take the wrapper
+			_ = nd.SetChild(i, w)
+			if nd.Children()[i] == w {
+				pn.out = append(pn.out, e)
+				e.to.in = append(e.to.in, e)
+			}
+		}
+	}
+	if len(p.Nodes) > 0 {
+		e := &profEdge{to: p.Nodes[0]}
+		if w := profWrap(root, e); w != nil {
+			p.Root = w
+			e.to.in = append(e.to.in, e)
+		}
+	}
+	return p
+}
+
+// Calls returns the number of calls made to the node.
+func (n *ProfileNode) Calls() int64 {
+	var res int64
+	for _, e := range n.in {
+		res += e.calls.Load()
+	}
+	return res
+}
+
+// Time returns the total time spent in the node, including its inputs.
+func (n *ProfileNode) Time() time.Duration {
+	var res int64
+	for _, e := range n.in {
+		res += e.ns.Load()
+	}
+	return time.Duration(res)
+}
+
+// Self returns the time spent in the node, excluding its instrumented inputs.
+func (n *ProfileNode) Self() time.Duration {
+	res := n.Time()
+	for _, e := range n.out {
+		res -= time.Duration(e.ns.Load())
+	}
+	return max(res, 0)
+}
+
+// Reset zeroes the counts.
+func (p *Profile) Reset() {
+	for _, n := range p.Nodes {
+		for _, e := range n.in {
+			e.calls.Store(0)
+			e.ns.Store(0)
+		}
+	}
+}
+
+// WriteReport writes a table of the nodes' calls and times, in decreasing order of their own time.
+func (p *Profile) WriteReport(w io.Writer) error {
+	nodes := slices.Clone(p.Nodes)
+	slices.SortStableFunc(nodes, func(a, b *ProfileNode) int {
+		return cmp.Compare(b.Self(), a.Self())
+	})
+	var total time.Duration
+	if len(p.Nodes) > 0 {
+		total = p.Nodes[0].Time()
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%12s %6s %12s %12s %8s  %s\n", "self", "self%", "total", "calls", "ns/call", "node")
+	for _, n := range nodes {
+		calls := n.Calls()
+		if calls == 0 {
+			continue
+		}
+		pc := 0.0
+		if total > 0 {
+			pc = 100 * float64(n.Self()) / float64(total)
+		}
+		fmt.Fprintf(&sb, "%12v %5.1f%% %12v %12d %8d  %s(%s)\n", n.Self().Round(time.Microsecond), pc,
+			n.Time().Round(time.Microsecond), calls, int64(n.Time())/calls, n.Type, n.ID)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteFolded writes the nodes' own times in nanoseconds in the folded stack format read by flame graph
+// tools such as flamegraph.pl, inferno and speedscope. Each stack is a path from the root, with the frames
+// named by type and ID. A subtree shared by several parents appears under each of them, with its times
+// split in proportion to the time spent in it by each.
+func (p *Profile) WriteFolded(w io.Writer) error {
+	if len(p.Nodes) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	root := p.Nodes[0]
+	profFold(&sb, root, root.frame(), 1)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// profFold writes the folded stacks of n, reached by stack, where frac is the fraction of n's time spent
+// along that path.
+func profFold(sb *strings.Builder, n *ProfileNode, stack string, frac float64) {
+	if self := int64(frac * float64(n.Self())); self > 0 {
+		fmt.Fprintf(sb, "%s %d\n", stack, self)
+	}
+	total := float64(n.Time())
+	if total == 0 {
+		return
+	}
+	for _, e := range n.out {
+		ct := float64(e.to.Time())
+		if ct == 0 {
+			continue
+		}
+		// The fraction of the child's time spent below n along this path
+		cf := frac * float64(e.ns.Load()) / ct
+		profFold(sb, e.to, stack+";"+e.to.frame(), cf)
+	}
+}
+
+func (n *ProfileNode) frame() string {
+	return n.Type + "(" + n.ID + ")"
+}
+
+// profWrap returns a counting wrapper for a field, vector field or color field, or nil.
+func profWrap(n any, e *profEdge) any {
+	switch f := n.(type) {
+	case Field:
+		return &profField{f, e}
+	case VectorField:
+		return &profVectorField{f, e}
+	case ColorField:
+		return &profColorField{f, e}
+	}
+	return nil
+}
+
+type profField struct {
+	src Field
+	e   *profEdge
+}
+
+func (f *profField) Eval2(x, y float64) float64 {
+	t := time.Now()
+	v := f.src.Eval2(x, y)
+	f.e.record(t)
+	return v
+}
+
+type profVectorField struct {
+	src VectorField
+	e   *profEdge
+}
+
+func (f *profVectorField) Eval2(x, y float64) []float64 {
+	t := time.Now()
+	v := f.src.Eval2(x, y)
+	f.e.record(t)
+	return v
+}
+
+type profColorField struct {
+	src ColorField
+	e   *profEdge
+}
+
+func (f *profColorField) Eval2(x, y float64) color.Color {
+	t := time.Now()
+	v := f.src.Eval2(x, y)
+	f.e.record(t)
+	return v
+}
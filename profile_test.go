@@ -0,0 +1,100 @@
+package texture_test
+
+import (
+	"github.com/jphsd/graphics2d"
+	"github.com/jphsd/texture"
+	"strings"
+	"testing"
+)
+
+// profCalls evaluates the profiled tree over a 10 by 10 grid and returns the calls made to each node type.
+func profCalls(t *testing.T, p *texture.Profile, tree texture.Field) map[string]int64 {
+	t.Helper()
+	root, ok := p.Root.(texture.Field)
+	if !ok {
+		t.Fatalf("profiled root is %T", p.Root)
+	}
+	for y := range 10 {
+		for x := range 10 {
+			fx, fy := float64(x)*7.5, float64(y)*3.25
+			if v1, v2 := tree.Eval2(fx, fy), root.Eval2(fx, fy); v1 != v2 {
+				t.Fatalf("(%g,%g) is %g, profiled %g", fx, fy, v1, v2)
+			}
+		}
+	}
+	res := map[string]int64{}
+	for _, n := range p.Nodes {
+		res[n.Type] += n.Calls()
+	}
+	return res
+}
+
+func TestProfileCalls(t *testing.T) {
+	tree, _ := testShared()
+	p := texture.NewProfile(tree)
+	if len(p.Nodes) != 4 {
+		t.Fatalf("%d nodes, expected 4", len(p.Nodes))
+	}
+	want := map[string]int64{"MulCombiner": 100, "Perlin": 200, "Transform": 100, "InvertFilter": 100}
+	got := profCalls(t, p, tree)
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s called %d times, expected %d", k, got[k], v)
+		}
+	}
+	if root := p.Nodes[0]; root.Self() > root.Time() {
+		t.Errorf("root's own time %v exceeds its total %v", root.Self(), root.Time())
+	}
+
+	p.Reset()
+	for _, n := range p.Nodes {
+		if n.Calls() != 0 || n.Time() != 0 {
+			t.Errorf("%s(%s) has %d calls after Reset", n.Type, n.ID, n.Calls())
+		}
+	}
+}
+
+func TestProfileFractal(t *testing.T) {
+	// A fractal of 3 octaves evaluates its source 4 times
+	tree := texture.NewFractal(texture.NewPerlin(1), graphics2d.Scale(2, 2), texture.NewFBM(1, 2, 4), 3)
+	p := texture.NewProfile(tree)
+	got := profCalls(t, p, tree)
+	if got["Fractal"] != 100 || got["Perlin"] != 400 {
+		t.Errorf("Fractal called %d times and Perlin %d, expected 100 and 400", got["Fractal"], got["Perlin"])
+	}
+}
+
+func TestProfileOutput(t *testing.T) {
+	tree, _ := testShared()
+	p := texture.NewProfile(tree)
+	profCalls(t, p, tree)
+
+	var sb strings.Builder
+	if err := p.WriteReport(&sb); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("report has %d lines, expected a header and 4 nodes\n%s", len(lines), sb.String())
+	}
+	for _, l := range lines[1:] {
+		f := strings.Fields(l)
+		if node := f[len(f)-1]; node == "Perlin(n2)" && f[len(f)-3] != "200" {
+			t.Errorf("report line %q doesn't show 200 calls", l)
+		}
+	}
+
+	sb.Reset()
+	if err := p.WriteFolded(&sb); err != nil {
+		t.Fatal(err)
+	}
+	// The shared Perlin appears under both of its parents
+	for _, stack := range []string{
+		"MulCombiner(n1);Perlin(n2) ",
+		"MulCombiner(n1);Transform(n3);InvertFilter(n4);Perlin(n2) ",
+	} {
+		if !strings.Contains(sb.String(), stack) {
+			t.Errorf("folded stacks lack %q\n%s", stack, sb.String())
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package texture
+
+import (
+	tcol "github.com/jphsd/texture/color"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// PaletteMethod defines the algorithm used to extract a palette from an image.
+type PaletteMethod int
+
+// Constants for palette extraction methods.
+const (
+	MedianCutPalette PaletteMethod = iota
+	KMeansPalette
+)
+
+// ExtractPalette returns a palette of up to n colors that represent the image. The colors are found in
+// OKLab space using either median cut, or k-means clustering seeded by median cut, and are sorted by
+// lightness. Large images are subsampled and mostly transparent pixels are ignored.
+func ExtractPalette(img image.Image, n int, method PaletteMethod) *Palette {
+	// Subsample to at most 64k pixels
+	r := img.Bounds()
+	step := int(math.Ceil(math.Sqrt(float64(r.Dx()*r.Dy()) / 65536)))
+	if step < 1 {
+		step = 1
+	}
+	samples := [][]float64{}
+	for y := r.Min.Y; y < r.Max.Y; y += step {
+		for x := r.Min.X; x < r.Max.X; x += step {
+			lab := tcol.NewOKLab(img.At(x, y))
+			if lab.Alpha < 0.5 {
+				continue
+			}
+			samples = append(samples, []float64{lab.L, lab.A, lab.B})
+		}
+	}
+	p := &Palette{}
+	if len(samples) == 0 || n < 1 {
+		return p
+	}
+
+	centers := medianCut(samples, n)
+	if method == KMeansPalette {
+		centers = kMeans(samples, centers, 16)
+	}
+	sort.Slice(centers, func(i, j int) bool { return centers[i][0] < centers[j][0] })
+	for _, c := range centers {
+		p.Colors = append(p.Colors, color.NRGBAModel.Convert(tcol.OKLab{c[0], c[1], c[2], 1}))
+	}
+	return p
+}
+
+// medianCut splits the samples into at most n boxes, always splitting the box with the largest extent
+// at its median, and returns the mean of each box.
+func medianCut(samples [][]float64, n int) [][]float64 {
+	boxes := [][][]float64{samples}
+	for len(boxes) < n {
+		bi, bc, be := -1, 0, 0.0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			c, e := widestChannel(box)
+			if e > be {
+				bi, bc, be = i, c, e
+			}
+		}
+		if bi < 0 {
+			break
+		}
+		box := boxes[bi]
+		sort.Slice(box, func(i, j int) bool { return box[i][bc] < box[j][bc] })
+		m := len(box) / 2
+		boxes[bi] = box[:m]
+		boxes = append(boxes, box[m:])
+	}
+	centers := make([][]float64, len(boxes))
+	for i, box := range boxes {
+		centers[i] = mean(box)
+	}
+	return centers
+}
+
+func widestChannel(box [][]float64) (int, float64) {
+	bc, be := 0, 0.0
+	for c := 0; c < 3; c++ {
+		min, max := box[0][c], box[0][c]
+		for _, s := range box {
+			min, max = math.Min(min, s[c]), math.Max(max, s[c])
+		}
+		if max-min > be {
+			bc, be = c, max-min
+		}
+	}
+	return bc, be
+}
+
+func mean(samples [][]float64) []float64 {
+	res := []float64{0, 0, 0}
+	for _, s := range samples {
+		res[0] += s[0]
+		res[1] += s[1]
+		res[2] += s[2]
+	}
+	n := float64(len(samples))
+	return []float64{res[0] / n, res[1] / n, res[2] / n}
+}
+
+// kMeans refines the centers using Lloyd's algorithm for up to iter iterations.
+func kMeans(samples, centers [][]float64, iter int) [][]float64 {
+	k := len(centers)
+	for it := 0; it < iter; it++ {
+		sums := make([][]float64, k)
+		counts := make([]int, k)
+		for i := range sums {
+			sums[i] = []float64{0, 0, 0}
+		}
+		for _, s := range samples {
+			i, _ := nearest(centers, s)
+			sums[i][0] += s[0]
+			sums[i][1] += s[1]
+			sums[i][2] += s[2]
+			counts[i]++
+		}
+		moved := false
+		for i := range centers {
+			if counts[i] == 0 {
+				continue
+			}
+			n := float64(counts[i])
+			c := []float64{sums[i][0] / n, sums[i][1] / n, sums[i][2] / n}
+			if dist2(c, centers[i]) > 1e-10 {
+				moved = true
+			}
+			centers[i] = c
+		}
+		if !moved {
+			break
+		}
+	}
+	return centers
+}
+
+// nearest returns the index of, and squared distance to, the closest center to v.
+func nearest(centers [][]float64, v []float64) (int, float64) {
+	bi, bd := 0, math.MaxFloat64
+	for i, c := range centers {
+		if d := dist2(c, v); d < bd {
+			bi, bd = i, d
+		}
+	}
+	return bi, bd
+}
+
+func dist2(a, b []float64) float64 {
+	d0, d1, d2 := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return d0*d0 + d1*d1 + d2*d2
+}
+
+// DitherType defines the dithering used when quantizing colors.
+type DitherType int
+
+// Constants for dither types.
+const (
+	NoDither DitherType = iota
+	OrderedDither
+	DiffusionDither
+)
+
+// ColorQuantize maps the colors from the source to the nearest palette color in OKLab space. Dithering
+// operates on cells of Size by Size, which is typically set to the realization step. OrderedDither uses
+// an 8x8 Bayer matrix to choose between the two nearest palette colors. DiffusionDither applies
+// Floyd-Steinberg error diffusion within 8x8 blocks of cells, and so evaluates the source up to 64 times
+// per location.
+type ColorQuantize struct {
+	Name   string
+	Src    ColorField
+	Colors []color.Color
+	Dither DitherType
+	Size   float64
+	labs   [][]float64
+}
+
+func NewColorQuantize(src ColorField, colors []color.Color, dither DitherType, size float64) *ColorQuantize {
+	labs := make([][]float64, len(colors))
+	for i, c := range colors {
+		lab := tcol.NewOKLab(c)
+		labs[i] = []float64{lab.L, lab.A, lab.B}
+	}
+	if size <= 0 {
+		size = 1
+	}
+	return &ColorQuantize{"ColorQuantize", src, colors, dither, size, labs}
+}
+
+// Eval2 implements the ColorField interface.
+func (q *ColorQuantize) Eval2(x, y float64) color.Color {
+	if len(q.Colors) == 0 {
+		return q.Src.Eval2(x, y)
+	}
+	switch q.Dither {
+	default:
+		fallthrough
+	case NoDither:
+		lab, a := q.lab(x, y)
+		i, _ := nearest(q.labs, lab)
+		return q.color(i, a)
+	case OrderedDither:
+		lab, a := q.lab(x, y)
+		i1, _ := nearest(q.labs, lab)
+		i2 := q.second(i1, lab)
+		if i2 < 0 {
+			return q.color(i1, a)
+		}
+		// Fraction of the second color in the best mix of the two
+		c1, c2 := q.labs[i1], q.labs[i2]
+		d := []float64{c2[0] - c1[0], c2[1] - c1[1], c2[2] - c1[2]}
+		m := ((lab[0]-c1[0])*d[0] + (lab[1]-c1[1])*d[1] + (lab[2]-c1[2])*d[2]) / dist2(c1, c2)
+		ci, cj := int(math.Floor(x/q.Size)), int(math.Floor(y/q.Size))
+		if (float64(bayer8[cj&7][ci&7])+0.5)/64 < m {
+			return q.color(i2, a)
+		}
+		return q.color(i1, a)
+	case DiffusionDither:
+		return q.diffuse(x, y)
+	}
+}
+
+// lab returns the source color at x, y in OKLab and its alpha.
+func (q *ColorQuantize) lab(x, y float64) ([]float64, float64) {
+	c := tcol.NewOKLab(q.Src.Eval2(x, y))
+	return []float64{c.L, c.A, c.B}, c.Alpha
+}
+
+// color returns palette color i with alpha a.
+func (q *ColorQuantize) color(i int, a float64) color.Color {
+	c := tcol.NewFRGBA(q.Colors[i])
+	c.A *= a
+	return c
+}
+
+// second returns the index of the nearest palette color to v other than i, or -1.
+func (q *ColorQuantize) second(i int, v []float64) int {
+	bi, bd := -1, math.MaxFloat64
+	for j, c := range q.labs {
+		if j == i {
+			continue
+		}
+		if d := dist2(c, v); d < bd {
+			bi, bd = j, d
+		}
+	}
+	return bi
+}
+
+// diffuse runs Floyd-Steinberg error diffusion over the cells of the 8x8 block containing x, y up to
+// and including the cell at x, y.
+func (q *ColorQuantize) diffuse(x, y float64) color.Color {
+	const bs = 8
+	ci, cj := int(math.Floor(x/q.Size)), int(math.Floor(y/q.Size))
+	bi, bj := ci-mod(ci, bs), cj-mod(cj, bs)
+	ti, tj := ci-bi, cj-bj
+
+	var errs [bs + 1][bs + 2][3]float64
+	for j := 0; j <= tj; j++ {
+		for i := 0; i < bs; i++ {
+			cx, cy := (float64(bi+i)+0.5)*q.Size, (float64(bj+j)+0.5)*q.Size
+			if i == ti && j == tj {
+				// Use the actual location for the final cell
+				cx, cy = x, y
+			}
+			lab, a := q.lab(cx, cy)
+			e := errs[j][i+1]
+			v := []float64{lab[0] + e[0], lab[1] + e[1], lab[2] + e[2]}
+			k, _ := nearest(q.labs, v)
+			if i == ti && j == tj {
+				return q.color(k, a)
+			}
+			p := q.labs[k]
+			d := [3]float64{v[0] - p[0], v[1] - p[1], v[2] - p[2]}
+			for c := 0; c < 3; c++ {
+				errs[j][i+2][c] += d[c] * 7 / 16
+				errs[j+1][i][c] += d[c] * 3 / 16
+				errs[j+1][i+1][c] += d[c] * 5 / 16
+				errs[j+1][i+2][c] += d[c] * 1 / 16
+			}
+		}
+	}
+	return q.Src.Eval2(x, y)
+}
+
+func mod(a, b int) int {
+	m := a % b
+	if m < 0 {
+		m += b
+	}
+	return m
+}
+
+// 8x8 Bayer ordered dither matrix.
+var bayer8 = [8][8]int{
+	{0, 32, 8, 40, 2, 34, 10, 42},
+	{48, 16, 56, 24, 50, 18, 58, 26},
+	{12, 44, 4, 36, 14, 46, 6, 38},
+	{60, 28, 52, 20, 62, 30, 54, 22},
+	{3, 35, 11, 43, 1, 33, 9, 41},
+	{51, 19, 59, 27, 49, 17, 57, 25},
+	{15, 47, 7, 39, 13, 45, 5, 37},
+	{63, 31, 55, 23, 61, 29, 53, 21},
+}
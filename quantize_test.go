@@ -0,0 +1,122 @@
+package texture_test
+
+import (
+	"github.com/jphsd/texture"
+	tcol "github.com/jphsd/texture/color"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// testPaletteImage returns an image of four quadrants, each two shades of a color, and a transparent row
+// of a fifth color.
+func testPaletteImage() (image.Image, []color.NRGBA) {
+	cols := []color.NRGBA{{40, 40, 200, 255}, {200, 40, 40, 255}, {220, 220, 60, 255}, {235, 235, 235, 255}}
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 65))
+	for y := range 64 {
+		for x := range 64 {
+			c := cols[x/32+2*(y/32)]
+			d := uint8(10)
+			if (x+y)%2 == 0 {
+				c.R, c.G, c.B = c.R-d, c.G-d, c.B-d
+			} else {
+				c.R, c.G, c.B = c.R+d, c.G+d, c.B+d
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	for x := range 64 {
+		img.SetNRGBA(x, 64, color.NRGBA{255, 0, 255, 0})
+	}
+	return img, cols
+}
+
+func TestExtractPalette(t *testing.T) {
+	img, cols := testPaletteImage()
+	for _, method := range []texture.PaletteMethod{texture.MedianCutPalette, texture.KMeansPalette} {
+		p := texture.ExtractPalette(img, 4, method)
+		if len(p.Colors) != 4 {
+			t.Fatalf("method %d: %d colors, expected 4", method, len(p.Colors))
+		}
+		// Sorted by lightness, which is the order of cols
+		for i, c := range p.Colors {
+			got, want := color.NRGBAModel.Convert(c).(color.NRGBA), cols[i]
+			if !near8(got.R, want.R) || !near8(got.G, want.G) || !near8(got.B, want.B) || got.A != 255 {
+				t.Errorf("method %d: color %d is %v, expected %v", method, i, got, want)
+			}
+		}
+	}
+
+	// The transparent row is ignored
+	for _, c := range texture.ExtractPalette(img, 5, texture.KMeansPalette).Colors {
+		if got := color.NRGBAModel.Convert(c).(color.NRGBA); got.R > 150 && got.B > 150 && got.G < 100 {
+			t.Errorf("palette includes %v from the transparent row", got)
+		}
+	}
+
+	if p := texture.ExtractPalette(img, 0, texture.MedianCutPalette); len(p.Colors) != 0 {
+		t.Errorf("%d colors for n = 0", len(p.Colors))
+	}
+}
+
+// near8 returns true if a and b differ by less than 4.
+func near8(a, b uint8) bool {
+	d := int(a) - int(b)
+	return d > -4 && d < 4
+}
+
+func TestColorQuantize(t *testing.T) {
+	src := texture.NewColorConv(testField(), color.NRGBA{255, 0, 0, 255}, color.NRGBA{0, 0, 255, 255},
+		[]color.Color{color.NRGBA{0, 255, 0, 255}}, []float64{0.4}, texture.LerpOKLab)
+	pal := []color.Color{
+		color.NRGBA{0, 0, 0, 255}, color.NRGBA{255, 0, 0, 255}, color.NRGBA{0, 200, 0, 255},
+		color.NRGBA{0, 0, 255, 255}, color.NRGBA{255, 255, 255, 255},
+	}
+	inPal := map[color.NRGBA]bool{}
+	for _, c := range pal {
+		inPal[c.(color.NRGBA)] = true
+	}
+	for _, dither := range []texture.DitherType{texture.NoDither, texture.OrderedDither, texture.DiffusionDither} {
+		q := texture.NewColorQuantize(src, pal, dither, 1)
+		for y := range 24 {
+			for x := range 24 {
+				fx, fy := float64(x)*4.5, float64(y)*3.5
+				c := color.NRGBAModel.Convert(q.Eval2(fx, fy)).(color.NRGBA)
+				if !inPal[c] {
+					t.Fatalf("dither %d: (%g,%g) is %v, which isn't in the palette", dither, fx, fy, c)
+				}
+			}
+		}
+	}
+
+	// Undithered output is the nearest palette color
+	q := texture.NewColorQuantize(texture.NewUniformCF(color.NRGBA{230, 20, 30, 255}), pal, texture.NoDither, 1)
+	if c := color.NRGBAModel.Convert(q.Eval2(3, 4)); c != pal[1] {
+		t.Errorf("undithered color is %v, expected %v", c, pal[1])
+	}
+
+	// Gray 40% of the way from black to white dithers to 40% white
+	gray := texture.NewUniformCF(tcol.OKLab{0.4, 0, 0, 1})
+	bw := []color.Color{color.NRGBA{0, 0, 0, 255}, color.NRGBA{255, 255, 255, 255}}
+	for _, test := range []struct {
+		dither   texture.DitherType
+		min, max int
+	}{
+		{texture.NoDither, 0, 0},
+		{texture.OrderedDither, 25, 27},
+		{texture.DiffusionDither, 18, 34},
+	} {
+		q := texture.NewColorQuantize(gray, bw, test.dither, 1)
+		white := 0
+		for y := range 8 {
+			for x := range 8 {
+				if c := color.NRGBAModel.Convert(q.Eval2(float64(x)+0.5, float64(y)+0.5)); c == bw[1] {
+					white++
+				}
+			}
+		}
+		if white < test.min || white > test.max {
+			t.Errorf("dither %d: %d white of 64, expected %d to %d", test.dither, white, test.min, test.max)
+		}
+	}
+}
@@ -1,9 +1,10 @@
 package random
 
 import (
-	"github.com/jphsd/graphics2d/color"
+	g2dcol "github.com/jphsd/graphics2d/color"
 	"github.com/jphsd/texture"
-	"math/rand"
+	"image/color"
+	"sort"
 )
 
 // ColorFieldOpts describes the available ColorField functions.
@@ -34,16 +35,40 @@ func GetColorFields() []ColorFieldOpts {
 // MakeColorField creates a new color field.
 func MakeColorField(md, d int) texture.ColorField {
 	cf := GetColorFields()
-	return cf[rand.Intn(len(cf))].Make(md, d+1)
+	return cf[Rand.Intn(len(cf))].Make(md, d+1)
 }
 
-// MakeColorConv creates a new color field from a field.
+// Palette, if not empty, restricts the colors returned by MakeColor to those it contains.
+var Palette []color.Color
+
+// Gradients, if not empty, are used by MakeColorConv in place of random start and end colors.
+var Gradients []*texture.Gradient
+
+// MakeColorConv creates a new color field from a field. If Palette is set, then up to three additional
+// stops are drawn from it.
 func MakeColorConv(md, d int) texture.ColorField {
-	return texture.NewColorConv(MakeField(md, d+1), MakeColor(), MakeColor(), nil, nil, texture.LerpType(rand.Intn(3)))
+	if len(Gradients) > 0 {
+		return Gradients[Rand.Intn(len(Gradients))].ColorConv(MakeField(md, d+1), 16)
+	}
+	if len(Palette) > 0 {
+		n := Rand.Intn(4)
+		cols, tvals := make([]color.Color, n), make([]float64, n)
+		for i := range cols {
+			cols[i] = MakeColor()
+			tvals[i] = Rand.Float64()
+		}
+		sort.Float64s(tvals)
+		return texture.NewColorConv(MakeField(md, d+1), MakeColor(), MakeColor(), cols, tvals, texture.LerpType(Rand.Intn(3)))
+	}
+	return texture.NewColorConv(MakeField(md, d+1), MakeColor(), MakeColor(), nil, nil, texture.LerpType(Rand.Intn(3)))
 }
 
+// MakeColor returns a random color, either from Palette or a random hue.
 func MakeColor() color.Color {
-	return color.HSL{rand.Float64(), 0.5, 1, 1}
+	if len(Palette) > 0 {
+		return Palette[Rand.Intn(len(Palette))]
+	}
+	return g2dcol.HSL{Rand.Float64(), 0.5, 1, 1}
 }
 
 // MakeColorGray creates a new color field from a field.
@@ -53,23 +78,23 @@ func MakeColorGray(md, d int) texture.ColorField {
 
 // MakeColorSinCos creates a new color field from a field.
 func MakeColorSinCos(md, d int) texture.ColorField {
-	return texture.NewColorSinCos(MakeField(md, d+1), rand.Intn(6), rand.Intn(2) == 0)
+	return texture.NewColorSinCos(MakeField(md, d+1), Rand.Intn(6), Rand.Intn(2) == 0)
 }
 
 // MakeColorFields creates a color field from three fields.
 func MakeColorFields(md, d int) texture.ColorField {
-	return texture.NewColorFields(MakeField(md, d+1), MakeField(md, d+1), MakeField(md, d+1), nil, rand.Intn(2) == 0)
+	return texture.NewColorFields(MakeField(md, d+1), MakeField(md, d+1), MakeField(md, d+1), nil, Rand.Intn(2) == 0)
 }
 
 // MakeColorBlend creates a color field from two input color fields and a field.
 func MakeColorBlend(md, d int) texture.ColorField {
-	return texture.NewColorBlend(MakeColorField(md, d+1), MakeColorField(md, d+1), MakeField(md, d+1), texture.LerpType(rand.Intn(3)))
+	return texture.NewColorBlend(MakeColorField(md, d+1), MakeColorField(md, d+1), MakeField(md, d+1), texture.LerpType(Rand.Intn(3)))
 }
 
 // MakeColorSubstitute creates a color field from two input color fields and a field.
 func MakeColorSubstitute(md, d int) texture.ColorField {
-	s := rand.Float64()
-	t := rand.Float64()
+	s := Rand.Float64()
+	t := Rand.Float64()
 	e := t - s*(1+t)
 	return texture.NewColorSubstitute(MakeColorField(md, d+1), MakeColorField(md, d+1), MakeField(md, d+1), s, e)
 }
@@ -17,5 +17,10 @@ which is then saved as "example.png"
 		img := texture.NewRGBA(800, 800, cf, 0, 0, 1, 1)
 		image.SaveImage(img, "example")
 	}
+
+Setting [Rand] to a seeded source, such as rand.New(rand.NewSource(42)), makes the trees reproducible,
+although renders of trees containing StochasticBlend or JitterBlend are not, since those draw a new random
+number for every evaluation.
+Setting [Palette] or [Gradients] restricts the colors chosen to those from an imported palette or gradient.
 */
 package random
@@ -17,5 +17,5 @@ func MakeQuantizeFilter(md, d int) texture.Field {
 
 // MakeRandQuantFilter creates a non-linear filter.
 func MakeRandQuantFilter(md, d int) texture.Field {
-	return texture.NewRandQuantFilter(MakeField(md, d+1), 1, 0, int(PickLambda()))
+	return texture.NewRandQuantFilterRand(MakeField(md, d+1), 1, 0, int(PickLambda()), Rand)
 }
@@ -6,7 +6,6 @@ import (
 	"github.com/jphsd/texture"
 	"image"
 	"math"
-	"math/rand"
 )
 
 // Leaf describes a Field that has no predecessors.
@@ -30,7 +29,7 @@ var LeafOptions = []Leaf{
 
 // MakeLeaf creates a new leaf.
 func MakeLeaf() texture.Field {
-	res := LeafOptions[rand.Intn(len(LeafOptions))]
+	res := LeafOptions[Rand.Intn(len(LeafOptions))]
 	return res.Make()
 }
 
@@ -38,7 +37,7 @@ func MakeLeaf() texture.Field {
 
 // MakeUniform creates a new Uniform
 func MakeUniform() texture.Field {
-	return texture.NewUniform(rand.Float64()*2 - 1)
+	return texture.NewUniform(Rand.Float64()*2 - 1)
 }
 
 func MakeLinearGradient() texture.Field {
@@ -46,8 +45,8 @@ func MakeLinearGradient() texture.Field {
 
 	// Wrap it in a Transform
 	xfm := g2d.NewAff3()
-	offs := rand.Float64()*200 - 100
-	rot := rand.Float64() * math.Pi * 2
+	offs := Rand.Float64()*200 - 100
+	rot := Rand.Float64() * math.Pi * 2
 	xfm.Rotate(rot)
 	xfm.Translate(offs, 0)
 	return texture.NewTransform(f, xfm)
@@ -60,7 +59,7 @@ func MakeRadialGradient() texture.Field {
 	// Wrap it in a Transform
 	xfm := g2d.NewAff3()
 	offs := -400.0 // Hack alert - assumes 800x800 image
-	rot := rand.Float64() * math.Pi * 2
+	rot := Rand.Float64() * math.Pi * 2
 	xfm.Rotate(rot)
 	xfm.Translate(offs, offs)
 	return texture.NewTransform(f, xfm)
@@ -73,7 +72,7 @@ func MakeConicGradient() texture.Field {
 	// Wrap it in a Transform
 	xfm := g2d.NewAff3()
 	offs := -400.0 // Hack alert - assumes 800x800 image
-	rot := rand.Float64() * math.Pi * 2
+	rot := Rand.Float64() * math.Pi * 2
 	xfm.Rotate(rot)
 	xfm.Translate(offs, offs)
 	return texture.NewTransform(f, xfm)
@@ -83,7 +82,7 @@ func MakeTiledGradient() texture.Field {
 	var wave texture.Wave
 
 	//Select wave type
-	if rand.Intn(2) > 0 {
+	if Rand.Intn(2) > 0 {
 		w := MakePatternWave().(*texture.PatternWave)
 		w.Once = true
 		wave = w
@@ -95,7 +94,7 @@ func MakeTiledGradient() texture.Field {
 
 	var field texture.Field
 	// Select gradient type
-	if rand.Intn(2) > 0 {
+	if Rand.Intn(2) > 0 {
 		field = texture.NewRadialGradient(wave)
 	} else {
 		field = texture.NewConicGradient(wave)
@@ -113,8 +112,8 @@ func MakeTiledGradient() texture.Field {
 
 	// Wrap it in a Transform
 	xfm = g2d.NewAff3()
-	offs := rand.Float64()*200 - 100
-	rot := rand.Float64() * math.Pi * 2
+	offs := Rand.Float64()*200 - 100
+	rot := Rand.Float64() * math.Pi * 2
 	xfm.Rotate(rot)
 	xfm.Translate(offs, 0)
 	return texture.NewTransform(field, xfm)
@@ -124,8 +123,8 @@ func MakeTiledGradient() texture.Field {
 func MakeBinary() texture.Field {
 	xfm := g2d.NewAff3()
 	xfm.Scale(0.01, 0.01)
-	xfm.Rotate(rand.Float64() * math.Pi * 2)
-	f := texture.NewBinary(16, 16, rand.Int63(), rand.Float64())
+	xfm.Rotate(Rand.Float64() * math.Pi * 2)
+	f := texture.NewBinary(16, 16, Rand.Int63(), Rand.Float64())
 	return texture.NewTransform(f, xfm)
 }
 
@@ -133,8 +132,8 @@ func MakeBinary() texture.Field {
 func MakePerlin() texture.Field {
 	xfm := g2d.NewAff3()
 	xfm.Scale(0.01, 0.01)
-	xfm.Rotate(rand.Float64() * math.Pi * 2)
-	f := texture.NewPerlin(rand.Int63())
+	xfm.Rotate(Rand.Float64() * math.Pi * 2)
+	f := texture.NewPerlin(Rand.Int63())
 	return texture.NewTransform(f, xfm)
 }
 
@@ -157,6 +156,6 @@ func MakeImage() texture.Field {
 	f2 := texture.NewColorToGray(f1)
 	f3 := texture.NewTiler(f2, []float64{float64(iw), float64(ih)})
 	xfm := g2d.NewAff3()
-	xfm.Rotate(rand.Float64() * math.Pi * 2)
+	xfm.Rotate(Rand.Float64() * math.Pi * 2)
 	return texture.NewTransform(f3, xfm)
 }
@@ -2,11 +2,10 @@ package random
 
 import (
 	"github.com/jphsd/texture"
-	"math/rand"
 )
 
 func MakeSupport() [][]float64 {
-	switch rand.Intn(3) {
+	switch Rand.Intn(3) {
 	default:
 		fallthrough
 	case 0:
@@ -19,7 +18,7 @@ func MakeSupport() [][]float64 {
 }
 
 func MakeMorphological(md, d int) texture.Field {
-	switch rand.Intn(4) {
+	switch Rand.Intn(4) {
 	default:
 		fallthrough
 	case 0:
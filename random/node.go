@@ -4,8 +4,14 @@ import (
 	//"fmt"
 	"github.com/jphsd/texture"
 	"math/rand"
+	"time"
 )
 
+// Rand is the source of the random choices made by the MakeXXX functions. Replace it with a seeded
+// source to make the trees reproducible. StochasticBlend and JitterBlend still draw from the global
+// source as they're evaluated, so their renders differ each time.
+var Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+
 // Node describes a Field that has predecessors.
 type Node struct {
 	Name string
@@ -55,18 +61,18 @@ func GetNodes() []Node {
 // MakeNode creates a new node.
 func MakeNode(md, d int) texture.Field {
 	n := GetNodes()
-	return n[rand.Intn(len(n))].Make(md, d+1)
+	return n[Rand.Intn(len(n))].Make(md, d+1)
 }
 
 // MakeField creates either a new leaf or node.
 func MakeField(md, d int) texture.Field {
 	l := LeafOptions
 	if d >= md {
-		return l[rand.Intn(len(l))].Make()
+		return l[Rand.Intn(len(l))].Make()
 	}
 	n := GetNodes()
 	s := len(l) + len(n)
-	s = rand.Intn(s)
+	s = Rand.Intn(s)
 	if s < len(l) {
 		return l[s].Make()
 	}
@@ -83,7 +89,7 @@ func MakeVectorField(md, d int) texture.VectorField {
 
 // MakeSelect creates a new field from a vector field.
 func MakeSelect(md, d int) texture.Field {
-	return texture.NewSelect(MakeVectorField(md, d+1), rand.Intn(3), 1)
+	return texture.NewSelect(MakeVectorField(md, d+1), Rand.Intn(3), 1)
 }
 
 // MakeDirection creates a new field from a vector field.
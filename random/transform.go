@@ -5,16 +5,15 @@ import (
 	g2d "github.com/jphsd/graphics2d"
 	"github.com/jphsd/texture"
 	"math"
-	"math/rand"
 )
 
 // MakeTransform creates a new transform processor
 func MakeTransform(md, d int) texture.Field {
 	xfm := g2d.NewAff3()
-	offs := rand.Float64()*200 - 100
-	sx := rand.Float64()*4 - 2
-	sy := rand.Float64()*4 - 2
-	rot := rand.Float64() * math.Pi * 2
+	offs := Rand.Float64()*200 - 100
+	sx := Rand.Float64()*4 - 2
+	sy := Rand.Float64()*4 - 2
+	rot := Rand.Float64() * math.Pi * 2
 	xfm.Scale(sx, sy)
 	xfm.Rotate(rot)
 	xfm.Translate(offs, 0)
@@ -24,8 +23,8 @@ func MakeTransform(md, d int) texture.Field {
 // MakeStrip creates a new strip processor
 func MakeStrip(md, d int) texture.Field {
 	xfm := g2d.NewAff3()
-	xfm.Rotate(rand.Float64() * math.Pi * 2)
-	y := rand.Float64()*2000 - 1000
+	xfm.Rotate(Rand.Float64() * math.Pi * 2)
+	y := Rand.Float64()*2000 - 1000
 	return texture.NewTransform(texture.NewStrip(MakeField(md, d+1), y), xfm)
 }
 
@@ -33,12 +32,12 @@ func MakeStrip(md, d int) texture.Field {
 func MakeFractal(md, d int) texture.Field {
 	lac := 2.0
 	hurst := 1.0
-	nocts := rand.Intn(3)
+	nocts := Rand.Intn(3)
 	octs := float64(nocts)
 	xfm := g2d.NewAff3()
 	xfm.Scale(lac, lac)
-	xfm.Rotate(rand.Float64() * math.Pi)
-	if rand.Intn(2) == 0 {
+	xfm.Rotate(Rand.Float64() * math.Pi)
+	if Rand.Intn(2) == 0 {
 		fbm := texture.NewFBM(hurst, lac, nocts)
 		res := texture.NewFractal(MakeField(md, d+1), xfm, fbm, octs)
 		return res
@@ -52,12 +51,12 @@ func MakeFractal(md, d int) texture.Field {
 func MakeVariableFractal(md, d int) texture.Field {
 	lac := 2.0
 	hurst := 1.0
-	nocts := rand.Intn(5)
+	nocts := Rand.Intn(5)
 	octs := float64(nocts)
 	xfm := g2d.NewAff3()
 	xfm.Scale(lac, lac)
-	xfm.Rotate(rand.Float64() * math.Pi)
-	if rand.Intn(2) == 0 {
+	xfm.Rotate(Rand.Float64() * math.Pi)
+	if Rand.Intn(2) == 0 {
 		fbm := texture.NewFBM(hurst, lac, nocts)
 		res := texture.NewFractal(MakeField(md, d+1), xfm, fbm, octs)
 		return res
@@ -74,7 +73,7 @@ func MakeDistort(md, d int) texture.Field {
 
 // MakeReflect creates a mirror plane in the field.
 func MakeReflect(md, d int) texture.Field {
-	a := rand.Float64() * math.Pi * 2
+	a := Rand.Float64() * math.Pi * 2
 	dx, dy := math.Cos(a)*400, math.Sin(a)*400 // Hack alert - assumes 800x800
 	return texture.NewReflect(MakeField(md, d+1), []float64{400, 400}, []float64{400 + dx, 400 + dy})
 }
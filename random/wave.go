@@ -3,11 +3,10 @@ package random
 import (
 	//"fmt"
 	"github.com/jphsd/texture"
-	"math/rand"
 )
 
 func MakeWave() texture.Wave {
-	if rand.Intn(2) > 0 {
+	if Rand.Intn(2) > 0 {
 		return MakePatternWave()
 	}
 	return MakeNLWave()
@@ -15,34 +14,34 @@ func MakeWave() texture.Wave {
 
 // MakePatternWave
 func MakePatternWave() texture.Wave {
-	nl := rand.Intn(5) + 1
+	nl := Rand.Intn(5) + 1
 	lambdas := make([]float64, nl)
 	patterns := make([][]float64, nl)
 	for i := 0; i < nl; i++ {
 		lambdas[i] = PickLambda()
 		patterns[i] = MakePattern(5)
 	}
-	return texture.NewPatternWave(lambdas, patterns, rand.Intn(2) > 0, false)
+	return texture.NewPatternWave(lambdas, patterns, Rand.Intn(2) > 0, false)
 }
 
 func MakePattern(n int) []float64 {
 	pat := make([]float64, n)
 	for i := 0; i < n; i++ {
-		pat[i] = rand.Float64()*2 - 1
+		pat[i] = Rand.Float64()*2 - 1
 	}
 	return pat
 }
 
 // MakeNLWave creates a new NLWave
 func MakeNLWave() texture.Wave {
-	nl := rand.Intn(5) + 1
+	nl := Rand.Intn(5) + 1
 	lambdas := make([]float64, nl)
 	nlfs := make([]*texture.NonLinear, nl)
 	for i := 0; i < nl; i++ {
 		lambdas[i] = PickLambda()
 		nlfs[i] = MakeNL()
 	}
-	return texture.NewNLWave(lambdas, nlfs, rand.Intn(2) > 0, false)
+	return texture.NewNLWave(lambdas, nlfs, Rand.Intn(2) > 0, false)
 }
 
 type NLFunc struct {
@@ -69,7 +68,7 @@ var NLFOptions = []NLFunc{
 }
 
 func MakeNL() *texture.NonLinear {
-	res := NLFOptions[rand.Intn(len(NLFOptions))]
+	res := NLFOptions[Rand.Intn(len(NLFOptions))]
 	return res.Make()
 }
 
@@ -138,5 +137,5 @@ var Lambdas = []float64{
 }
 
 func PickLambda() float64 {
-	return Lambdas[rand.Intn(len(Lambdas))]
+	return Lambdas[Rand.Intn(len(Lambdas))]
 }
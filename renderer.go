@@ -0,0 +1,340 @@
+package texture
+
+import (
+	"bufio"
+	"compress/zlib"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// BandRenderer realizes a color field too large to hold in memory. The image is evaluated in horizontal
+// bands of BandHeight rows, each written to its own file in Dir as soon as it is complete. A render that
+// fails, or is interrupted, can be resumed by calling Render again with the same parameters and Dir - only
+// the missing bands are evaluated. Bands left by a render with other parameters or a different source are
+// rejected. The completed bands are then streamed into a PNG or TIFF encoder.
+// Pixels are stored as 8 bit non-premultiplied RGBA.
+type BandRenderer struct {
+	Src        ColorField
+	Width      int
+	Height     int
+	Ox, Oy     float64
+	Dx, Dy     float64
+	BandHeight int
+	Dir        string
+	Progress   func(done, total int) // Called after each band is completed, if set
+}
+
+// NewBandRenderer creates a new BandRenderer from the supplied parameters. Dir holds the band files and is
+// created if necessary.
+func NewBandRenderer(width, height int, src ColorField, ox, oy, dx, dy float64, bandHeight int, dir string) *BandRenderer {
+	if bandHeight < 1 {
+		bandHeight = 1
+	}
+	return &BandRenderer{src, width, height, ox, oy, dx, dy, bandHeight, dir, nil}
+}
+
+// bandManifest records the render parameters so that a resume with different ones can be detected.
+type bandManifest struct {
+	Width, Height int
+	Ox, Oy        float64
+	Dx, Dy        float64
+	BandHeight    int
+	Tree          string // Hash of the source
+}
+
+// Bands returns the number of bands in the image.
+func (r *BandRenderer) Bands() int {
+	return (r.Height + r.BandHeight - 1) / r.BandHeight
+}
+
+// Completed returns the number of bands that have already been rendered.
+func (r *BandRenderer) Completed() int {
+	n := 0
+	for b := 0; b < r.Bands(); b++ {
+		if _, err := os.Stat(r.bandName(b)); err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Render evaluates and saves any bands not already present in Dir.
+func (r *BandRenderer) Render() error {
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		return err
+	}
+	if err := r.checkManifest(); err != nil {
+		return err
+	}
+
+	nb := r.Bands()
+	done := r.Completed()
+	row := make([]byte, r.Width*4)
+	for b := 0; b < nb; b++ {
+		name := r.bandName(b)
+		if _, err := os.Stat(name); err == nil {
+			continue
+		}
+
+		// Write to a temporary file and rename it once complete
+		tmp := name + ".tmp"
+		f, err := os.Create(tmp)
+		if err != nil {
+			return err
+		}
+		bw := bufio.NewWriter(f)
+		y0, y1 := b*r.BandHeight, min((b+1)*r.BandHeight, r.Height)
+		for y := y0; y < y1; y++ {
+			fy := r.Oy + float64(y)*r.Dy
+			for x := 0; x < r.Width; x++ {
+				c := color.NRGBAModel.Convert(r.Src.Eval2(r.Ox+float64(x)*r.Dx, fy)).(color.NRGBA)
+				row[x*4], row[x*4+1], row[x*4+2], row[x*4+3] = c.R, c.G, c.B, c.A
+			}
+			if _, err := bw.Write(row); err != nil {
+				f.Close()
+				return err
+			}
+		}
+		if err := bw.Flush(); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, name); err != nil {
+			return err
+		}
+		done++
+		if r.Progress != nil {
+			r.Progress(done, nb)
+		}
+	}
+	return nil
+}
+
+// SavePNG renders any missing bands and then streams them into name.png.
+func (r *BandRenderer) SavePNG(name string) error {
+	if err := r.Render(); err != nil {
+		return err
+	}
+	f, err := os.Create(fmt.Sprintf("%s.png", name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	bw := bufio.NewWriter(f)
+	if err := r.writePNG(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// SaveTIFF renders any missing bands and then streams them into name.tiff.
+func (r *BandRenderer) SaveTIFF(name string) error {
+	if err := r.Render(); err != nil {
+		return err
+	}
+	f, err := os.Create(fmt.Sprintf("%s.tiff", name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	bw := bufio.NewWriter(f)
+	rows := r.rows()
+	defer rows.close()
+	if err := writeTIFF(bw, r.Width, r.Height, 4, 8, 1, func(_ int, out []byte) error {
+		return rows.next(out)
+	}); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Clean removes the band files and Dir.
+func (r *BandRenderer) Clean() error {
+	return os.RemoveAll(r.Dir)
+}
+
+func (r *BandRenderer) bandName(b int) string {
+	return filepath.Join(r.Dir, fmt.Sprintf("band_%06d.rgba", b))
+}
+
+func (r *BandRenderer) checkManifest() error {
+	m := bandManifest{r.Width, r.Height, r.Ox, r.Oy, r.Dx, r.Dy, r.BandHeight, treeHash(r.Src)}
+	name := filepath.Join(r.Dir, "manifest.json")
+	b, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		b, err = json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(name, b, 0o644)
+	}
+	if err != nil {
+		return err
+	}
+	var old bandManifest
+	if err := json.Unmarshal(b, &old); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(m, old) {
+		return fmt.Errorf("%s contains bands from a render with different parameters or source", r.Dir)
+	}
+	return nil
+}
+
+// treeHash returns a hash of the JSON of a tree. Trees that can't be marshaled are hashed by their
+// node types.
+func treeHash(tree any) string {
+	b, err := json.Marshal(jsonValue(tree))
+	if err != nil {
+		var sb strings.Builder
+		Walk(tree, func(n any) bool {
+			sb.WriteString(graphType(n) + ";")
+			return true
+		})
+		b = []byte(sb.String())
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b))
+}
+
+// bandRows reads the rows of the image sequentially from the band files.
+type bandRows struct {
+	r    *BandRenderer
+	band int
+	f    *os.File
+	br   *bufio.Reader
+}
+
+func (r *BandRenderer) rows() *bandRows {
+	return &bandRows{r, -1, nil, nil}
+}
+
+func (br *bandRows) next(out []byte) error {
+	for {
+		if br.br != nil {
+			_, err := io.ReadFull(br.br, out)
+			if err == nil {
+				return nil
+			}
+			if err != io.EOF {
+				return err
+			}
+		}
+		br.close()
+		br.band++
+		f, err := os.Open(br.r.bandName(br.band))
+		if err != nil {
+			return err
+		}
+		br.f, br.br = f, bufio.NewReader(f)
+	}
+}
+
+func (br *bandRows) close() {
+	if br.f != nil {
+		br.f.Close()
+		br.f, br.br = nil, nil
+	}
+}
+
+// writePNG streams the band files into an 8 bit RGBA PNG using the Sub filter on every row.
+func (r *BandRenderer) writePNG(w io.Writer) error {
+	if _, err := w.Write([]byte("\x89PNG\r\n\x1a\n")); err != nil {
+		return err
+	}
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(r.Width))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(r.Height))
+	ihdr[8], ihdr[9] = 8, 6 // 8 bit RGBA
+	if err := writePNGChunk(w, "IHDR", ihdr); err != nil {
+		return err
+	}
+
+	cw := &pngChunkWriter{w, make([]byte, 0, 1<<16)}
+	zw := zlib.NewWriter(cw)
+	rows := r.rows()
+	defer rows.close()
+	row := make([]byte, r.Width*4)
+	filt := make([]byte, r.Width*4+1)
+	filt[0] = 1 // Sub
+	for y := 0; y < r.Height; y++ {
+		if err := rows.next(row); err != nil {
+			return err
+		}
+		for i := range row {
+			if i < 4 {
+				filt[i+1] = row[i]
+			} else {
+				filt[i+1] = row[i] - row[i-4]
+			}
+		}
+		if _, err := zw.Write(filt); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := cw.flush(); err != nil {
+		return err
+	}
+	return writePNGChunk(w, "IEND", nil)
+}
+
+// pngChunkWriter buffers compressed data into IDAT chunks.
+type pngChunkWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+func (cw *pngChunkWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		m := min(cap(cw.buf)-len(cw.buf), len(p))
+		cw.buf = append(cw.buf, p[:m]...)
+		p = p[m:]
+		if len(cw.buf) == cap(cw.buf) {
+			if err := cw.flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (cw *pngChunkWriter) flush() error {
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	err := writePNGChunk(cw.w, "IDAT", cw.buf)
+	cw.buf = cw.buf[:0]
+	return err
+}
+
+func writePNGChunk(w io.Writer, typ string, data []byte) error {
+	hdr := make([]byte, 8)
+	binary.BigEndian.PutUint32(hdr, uint32(len(data)))
+	copy(hdr[4:], typ)
+	crc := crc32.NewIEEE()
+	crc.Write(hdr[4:])
+	crc.Write(data)
+	if _, err := w.Write(hdr); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, crc.Sum32())
+}
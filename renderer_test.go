@@ -0,0 +1,78 @@
+package texture_test
+
+import (
+	"github.com/jphsd/texture"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBandRendererResume(t *testing.T) {
+	dir := t.TempDir()
+	bands := filepath.Join(dir, "bands")
+	cf := testColorField()
+	r := texture.NewBandRenderer(30, 25, cf, 0, 0, 2, 2, 8, bands)
+	if r.Bands() != 4 {
+		t.Fatalf("got %d bands, want 4", r.Bands())
+	}
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Remove a band and check that only it is rendered again
+	if err := os.Remove(filepath.Join(bands, "band_000002.rgba")); err != nil {
+		t.Fatal(err)
+	}
+	if r.Completed() != 3 {
+		t.Fatalf("got %d completed bands, want 3", r.Completed())
+	}
+	calls := 0
+	r.Progress = func(done, total int) { calls++ }
+	name := filepath.Join(dir, "img")
+	if err := r.SavePNG(name); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("resume rendered %d bands, want 1", calls)
+	}
+
+	f, err := os.Open(name + ".png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 25; y++ {
+		for x := 0; x < 30; x++ {
+			want := color.NRGBAModel.Convert(cf.Eval2(float64(x)*2, float64(y)*2))
+			if got := color.NRGBAModel.Convert(img.At(x, y)); got != want {
+				t.Fatalf("%d,%d: got %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestBandRendererMismatch(t *testing.T) {
+	bands := t.TempDir()
+	r := texture.NewBandRenderer(10, 10, testColorField(), 0, 0, 1, 1, 4, bands)
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An equal tree can resume, a different one or region can't
+	if err := texture.NewBandRenderer(10, 10, testColorField(), 0, 0, 1, 1, 4, bands).Render(); err != nil {
+		t.Errorf("equal tree: %v", err)
+	}
+	other := texture.NewColorGray(testField())
+	if err := texture.NewBandRenderer(10, 10, other, 0, 0, 1, 1, 4, bands).Render(); err == nil {
+		t.Error("different tree resumed")
+	}
+	if err := texture.NewBandRenderer(10, 10, testColorField(), 5, 0, 1, 1, 4, bands).Render(); err == nil {
+		t.Error("different origin resumed")
+	}
+}
@@ -0,0 +1,507 @@
+package texture
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ShaderLang is a shading language that trees can be compiled to with [Shader].
+type ShaderLang int
+
+// Supported shading languages.
+const (
+	GLSL ShaderLang = iota
+	WGSL
+)
+
+// UnsupportedError lists the nodes of a tree that can't be compiled to shader code, by type and [Graph]
+// ID, with the reason if there's more to it than the type.
+type UnsupportedError struct {
+	Nodes []string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%d unsupported nodes: %s", len(e.Nodes), strings.Join(e.Nodes, ", "))
+}
+
+// Shader compiles a tree, a field or color field, into a self-contained shader function in the language,
+// vec4 tex(vec2 p) in GLSL and fn tex(p: vec2<f32>) -> vec4<f32> in WGSL, that returns the premultiplied,
+// gamma encoded color at p. Fields are rendered as gray, as by [NewColorGray].
+//
+// The supported nodes are the gradients and waves, Perlin, the value combiners and filters, other than the
+// random ones, Transform, Warp and its warp functions, Fractal with FBM or MF, and ColorGray, ColorConv with
+// LerpRGBA, UniformCF, TransformCF and WarpCF. If the tree holds any other nodes an [UnsupportedError] is
+// returned. NonLinear curves other than the polynomial ones are sampled into tables, clamped to [0,1].
+// [CheckShader] compares the compiled tree with the original.
+func Shader(tree any, lang ShaderLang) (string, error) {
+	p, out, err := compileShader(tree)
+	if err != nil {
+		return "", err
+	}
+	return p.code(lang, out)
+}
+
+// CheckShader compiles a tree as [Shader] does, to both languages, and interprets the code, in float64, over
+// the region width by height, starting at ox, oy with steps of dx and dy. It returns the largest difference
+// between its color components, in [0,1], and those of the tree, or an error if the code doesn't parse or
+// is mistyped. Differences come from the curve tables, the float32 literals and the rounding of colors to
+// 16 or 8 bits by the tree. The shaders themselves evaluate in float32.
+func CheckShader(tree any, width, height int, ox, oy, dx, dy float64) (float64, error) {
+	p, out, err := compileShader(tree)
+	if err != nil {
+		return 0, err
+	}
+	cf, err := ToColorField(tree)
+	if err != nil {
+		return 0, err
+	}
+	var fns []*shFunc
+	for _, lang := range []ShaderLang{GLSL, WGSL} {
+		code, err := p.code(lang, out)
+		if err != nil {
+			return 0, err
+		}
+		f, err := parseShader(code, lang)
+		if err != nil {
+			return 0, err
+		}
+		fns = append(fns, f)
+	}
+
+	res := 0.0
+	for _, f := range fns {
+		vals := make([]float64, len(f.stmts))
+		for j := range height {
+			y := oy + float64(j)*dy
+			for i := range width {
+				x := ox + float64(i)*dx
+				col := f.eval(x, y, vals)
+				r, g, b, a := cf.Eval2(x, y).RGBA()
+				for k, c := range []uint32{r, g, b, a} {
+					d := math.Abs(col[k] - float64(c)/0xffff)
+					if d > res || math.IsNaN(d) {
+						res = d
+					}
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+func compileShader(tree any) (*shProg, [4]*shExpr, error) {
+	p := newShProg(tree)
+	out := p.root(tree)
+	if len(p.errs) > 0 {
+		return nil, out, &UnsupportedError{p.errs}
+	}
+	return p, out, nil
+}
+
+// shExpr is an operation in the expression DAG that shader code is generated from. Booleans are 0 or 1
+// when folded.
+type shExpr struct {
+	op   string
+	args []*shExpr
+	val  float64 // Constant value or table number
+	id   int     // Index in shProg.exprs
+}
+
+// shProg builds the expression DAG for a tree. Expressions are shared and constant ones folded as
+// they're added, so that exprs is in evaluation order.
+type shProg struct {
+	exprs  []*shExpr
+	memo   map[string]*shExpr
+	tables [][]float64
+	tabs   map[string]int
+	fields map[[3]any]*shExpr // Fields by node and arguments
+	ids    map[any]string     // Graph IDs of the nodes
+	errs   []string
+	x, y   *shExpr
+}
+
+func newShProg(tree any) *shProg {
+	p := &shProg{memo: map[string]*shExpr{}, tabs: map[string]int{}, fields: map[[3]any]*shExpr{},
+		ids: map[any]string{}}
+	Walk(tree, func(n any) bool {
+		p.ids[n] = fmt.Sprintf("n%d", len(p.ids)+1)
+		return true
+	})
+	p.x, p.y = p.op("x"), p.op("y")
+	return p
+}
+
+// unsupported records that n can't be compiled and returns a zero in its place.
+func (p *shProg) unsupported(n any, why string) *shExpr {
+	s := "nil"
+	if n != nil {
+		s = graphType(n)
+	}
+	if id, ok := p.ids[n]; ok {
+		s += "(" + id + ")"
+	}
+	if why != "" {
+		s += " " + why
+	}
+	for _, e := range p.errs {
+		if e == s {
+			return p.c(0)
+		}
+	}
+	p.errs = append(p.errs, s)
+	return p.c(0)
+}
+
+// shBool lists the operators with boolean results.
+var shBool = map[string]bool{
+	"<": true, "<=": true, ">": true, ">=": true, "==": true, "!=": true, "&&": true, "||": true, "!": true,
+}
+
+func (p *shProg) c(v float64) *shExpr {
+	return p.add(&shExpr{op: "c", val: v})
+}
+
+func (p *shProg) op(op string, args ...*shExpr) *shExpr {
+	return p.add(&shExpr{op: op, args: args})
+}
+
+// table returns the number of a lookup table with the values.
+func (p *shProg) table(vals []float64) int {
+	key := fmt.Sprint(vals)
+	if i, ok := p.tabs[key]; ok {
+		return i
+	}
+	p.tables = append(p.tables, vals)
+	p.tabs[key] = len(p.tables) - 1
+	return len(p.tables) - 1
+}
+
+func (p *shProg) lookup(tab int, i *shExpr) *shExpr {
+	return p.add(&shExpr{op: "lookup", args: []*shExpr{i}, val: float64(tab)})
+}
+
+// add returns the expression for e, simplified and shared with an existing one if possible.
+func (p *shProg) add(e *shExpr) *shExpr {
+	if r := p.simplify(e); r != nil {
+		return r
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %x", e.op, math.Float64bits(e.val))
+	for _, a := range e.args {
+		fmt.Fprintf(&sb, " %d", a.id)
+	}
+	key := sb.String()
+	if r, ok := p.memo[key]; ok {
+		return r
+	}
+	e.id = len(p.exprs)
+	p.exprs = append(p.exprs, e)
+	p.memo[key] = e
+	return e
+}
+
+// simplify folds constant expressions and removes identity operations, or returns nil.
+func (p *shProg) simplify(e *shExpr) *shExpr {
+	if len(e.args) == 0 {
+		return nil
+	}
+	consts := true
+	for _, a := range e.args {
+		consts = consts && a.op == "c"
+	}
+	if consts {
+		vals := make([]float64, len(e.args))
+		for i, a := range e.args {
+			vals[i] = a.val
+		}
+		return p.c(p.apply(e, vals))
+	}
+	isc := func(a *shExpr, v float64) bool {
+		return a.op == "c" && a.val == v
+	}
+	a := e.args[0]
+	switch e.op {
+	case "+":
+		switch {
+		case isc(a, 0):
+			return e.args[1]
+		case isc(e.args[1], 0):
+			return a
+		}
+	case "-", "/":
+		if isc(e.args[1], 0) && e.op == "-" || isc(e.args[1], 1) && e.op == "/" {
+			return a
+		}
+	case "*":
+		// Field values are finite
+		switch {
+		case isc(a, 0), isc(e.args[1], 0):
+			return p.c(0)
+		case isc(a, 1):
+			return e.args[1]
+		case isc(e.args[1], 1):
+			return a
+		}
+	case "&&", "||":
+		for i, b := range e.args {
+			if b.op == "c" {
+				if (b.val != 0) == (e.op == "&&") {
+					return e.args[1-i]
+				}
+				return b
+			}
+		}
+	case "?":
+		if a.op == "c" {
+			if a.val != 0 {
+				return e.args[1]
+			}
+			return e.args[2]
+		}
+		if e.args[1] == e.args[2] {
+			return e.args[1]
+		}
+	}
+	return nil
+}
+
+// apply evaluates an operation on the values of its arguments.
+func (p *shProg) apply(e *shExpr, a []float64) float64 {
+	b2f := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	switch e.op {
+	case "+":
+		return a[0] + a[1]
+	case "-":
+		return a[0] - a[1]
+	case "*":
+		return a[0] * a[1]
+	case "/":
+		return a[0] / a[1]
+	case "neg":
+		return -a[0]
+	case "<":
+		return b2f(a[0] < a[1])
+	case "<=":
+		return b2f(a[0] <= a[1])
+	case ">":
+		return b2f(a[0] > a[1])
+	case ">=":
+		return b2f(a[0] >= a[1])
+	case "==":
+		return b2f(a[0] == a[1])
+	case "!=":
+		return b2f(a[0] != a[1])
+	case "&&":
+		return b2f(a[0] != 0 && a[1] != 0)
+	case "||":
+		return b2f(a[0] != 0 || a[1] != 0)
+	case "!":
+		return b2f(a[0] == 0)
+	case "?":
+		if a[0] != 0 {
+			return a[1]
+		}
+		return a[2]
+	case "floor":
+		return math.Floor(a[0])
+	case "trunc":
+		return math.Trunc(a[0])
+	case "abs":
+		return math.Abs(a[0])
+	case "sqrt":
+		return math.Sqrt(a[0])
+	case "sin":
+		return math.Sin(a[0])
+	case "cos":
+		return math.Cos(a[0])
+	case "pow":
+		return math.Pow(a[0], a[1])
+	case "atan2":
+		return math.Atan2(a[0], a[1])
+	case "hypot":
+		return math.Hypot(a[0], a[1])
+	case "mod":
+		return a[0] - a[1]*math.Floor(a[0]/a[1])
+	case "clamp":
+		return clamp(a[0])
+	case "lookup":
+		tab := p.tables[int(e.val)]
+		i := 0
+		if a[0] >= 0 {
+			i = int(min(a[0], float64(len(tab)-1)))
+		}
+		return tab[i]
+	}
+	panic("unknown shader operation " + e.op)
+}
+
+// code returns the shader function computing out, with an assignment per operation.
+func (p *shProg) code(lang ShaderLang, out [4]*shExpr) (string, error) {
+	// Find the expressions used
+	used := make([]bool, len(p.exprs))
+	for _, e := range out {
+		used[e.id] = true
+	}
+	for i := len(p.exprs) - 1; i >= 0; i-- {
+		if used[i] {
+			for _, a := range p.exprs[i].args {
+				used[a.id] = true
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("// Generated from a texture tree. tex returns the premultiplied, gamma encoded color at p.\n\n")
+	tabs := map[int]bool{}
+	for i, e := range p.exprs {
+		if used[i] && e.op == "lookup" && !tabs[int(e.val)] {
+			tabs[int(e.val)] = true
+			tab := p.tables[int(e.val)]
+			elts := make([]string, len(tab))
+			for j, v := range tab {
+				elts[j], _ = shLiteral(v)
+			}
+			n := len(tab)
+			open := fmt.Sprintf("float[%d](", n)
+			if lang == WGSL {
+				open = fmt.Sprintf("array<f32, %d>(", n)
+			}
+			// GLSL doesn't allow a trailing comma
+			list := dslJoin(open, ")", elts, 0, true)
+			if strings.HasSuffix(list, ",\n)") {
+				list = list[:len(list)-3] + "\n)"
+			}
+			if lang == WGSL {
+				fmt.Fprintf(&sb, "var<private> tab%d: array<f32, %d> = %s;\n\n", int(e.val), n, list)
+			} else {
+				fmt.Fprintf(&sb, "const float tab%d[%d] = %s;\n\n", int(e.val), n, list)
+			}
+		}
+	}
+
+	if lang == WGSL {
+		sb.WriteString("fn tex(p: vec2<f32>) -> vec4<f32> {\n")
+	} else {
+		sb.WriteString("vec4 tex(vec2 p) {\n")
+	}
+	for i, e := range p.exprs {
+		if !used[i] || len(e.args) == 0 {
+			continue
+		}
+		s, err := p.format(lang, e)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case lang == WGSL:
+			fmt.Fprintf(&sb, "\tlet t%d = %s;\n", i, s)
+		case shBool[e.op]:
+			fmt.Fprintf(&sb, "\tbool t%d = %s;\n", i, s)
+		default:
+			fmt.Fprintf(&sb, "\tfloat t%d = %s;\n", i, s)
+		}
+	}
+	res := make([]string, 4)
+	for i, e := range out {
+		s, err := p.operand(e)
+		if err != nil {
+			return "", err
+		}
+		res[i] = s
+	}
+	if lang == WGSL {
+		fmt.Fprintf(&sb, "\treturn vec4<f32>(%s);\n}\n", strings.Join(res, ", "))
+	} else {
+		fmt.Fprintf(&sb, "\treturn vec4(%s);\n}\n", strings.Join(res, ", "))
+	}
+	return sb.String(), nil
+}
+
+// format returns the text of an operation.
+func (p *shProg) format(lang ShaderLang, e *shExpr) (string, error) {
+	a := make([]string, len(e.args))
+	for i, arg := range e.args {
+		s, err := p.operand(arg)
+		if err != nil {
+			return "", err
+		}
+		a[i] = s
+	}
+	switch e.op {
+	case "+", "-", "*", "/", "<", "<=", ">", ">=", "==", "!=", "&&", "||":
+		return a[0] + " " + e.op + " " + a[1], nil
+	case "neg":
+		return "-" + a[0], nil
+	case "!":
+		return "!" + a[0], nil
+	case "?":
+		if lang == WGSL {
+			return fmt.Sprintf("select(%s, %s, %s)", a[2], a[1], a[0]), nil
+		}
+		return a[0] + " ? " + a[1] + " : " + a[2], nil
+	case "atan2":
+		if lang == WGSL {
+			return fmt.Sprintf("atan2(%s, %s)", a[0], a[1]), nil
+		}
+		return fmt.Sprintf("atan(%s, %s)", a[0], a[1]), nil
+	case "hypot":
+		if lang == WGSL {
+			return fmt.Sprintf("length(vec2<f32>(%s, %s))", a[0], a[1]), nil
+		}
+		return fmt.Sprintf("length(vec2(%s, %s))", a[0], a[1]), nil
+	case "mod":
+		if lang == WGSL {
+			// WGSL's % truncates
+			return fmt.Sprintf("%s - %s * floor(%s / %s)", a[0], a[1], a[0], a[1]), nil
+		}
+		return fmt.Sprintf("mod(%s, %s)", a[0], a[1]), nil
+	case "clamp":
+		return fmt.Sprintf("clamp(%s, -1.0, 1.0)", a[0]), nil
+	case "lookup":
+		last := len(p.tables[int(e.val)]) - 1
+		if lang == WGSL {
+			return fmt.Sprintf("tab%d[i32(clamp(%s, 0.0, %d.0))]", int(e.val), a[0], last), nil
+		}
+		return fmt.Sprintf("tab%d[int(clamp(%s, 0.0, %d.0))]", int(e.val), a[0], last), nil
+	}
+	return e.op + "(" + strings.Join(a, ", ") + ")", nil
+}
+
+// operand returns the text of an argument - a constant, coordinate or variable.
+func (p *shProg) operand(e *shExpr) (string, error) {
+	switch e.op {
+	case "c":
+		s, ok := shLiteral(e.val)
+		if !ok {
+			return "", fmt.Errorf("constant %v can't be written in a shader", e.val)
+		}
+		if e.val < 0 {
+			s = "(" + s + ")"
+		}
+		return s, nil
+	case "x":
+		return "p.x", nil
+	case "y":
+		return "p.y", nil
+	}
+	return fmt.Sprintf("t%d", e.id), nil
+}
+
+// shLiteral returns the shortest float32 literal for v, and false if v isn't finite.
+func shLiteral(v float64) (string, bool) {
+	if math.IsInf(v, 0) || math.IsNaN(v) || math.Abs(v) > math.MaxFloat32 {
+		return "0.0", false
+	}
+	s := strconv.FormatFloat(v, 'g', -1, 32)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s, true
+}
@@ -0,0 +1,156 @@
+package texture_test
+
+import (
+	"errors"
+	"github.com/jphsd/graphics2d"
+	"github.com/jphsd/texture"
+	"image/color"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// shaderWave returns a single wave with a polynomial curve, which compiles without a table.
+func shaderWave(lambda float64, mirror bool) *texture.NLWave {
+	return texture.NewNLWave([]float64{lambda}, []*texture.NonLinear{texture.NewNLP3()}, mirror, false)
+}
+
+func TestCheckShader(t *testing.T) {
+	lin := texture.NewLinearGradient(shaderWave(23, true))
+	rad := texture.NewRadialGradient(shaderWave(37, false))
+	per := texture.NewTransform(texture.NewPerlin(3), graphics2d.Scale(0.05, 0.05))
+	nls := []*texture.NonLinear{texture.NewNLSquare(), texture.NewNLP5(), texture.NewNLCube()}
+	lambdas := []float64{11, 17, 29}
+	xfm := graphics2d.Rotate(0.4)
+	xfm.Translate(7, -3)
+	c := []float64{10, -5}
+
+	// The reference is read back through RGBA, so it's a 16 bit step out at most
+	const tol = 1.6e-5
+	tests := []struct {
+		name string
+		tree any
+		tol  float64
+	}{
+		{"LinearGradient", lin, tol},
+		{"RadialGradient", rad, tol},
+		{"ConicGradient", texture.NewConicGradient(texture.NewNLWave([]float64{1}, nls[:1], true, false)), tol},
+		{"NLWave", texture.NewLinearGradient(texture.NewNLWave(lambdas, nls, true, false)), tol},
+		{"NLWave once", texture.NewLinearGradient(texture.NewNLWave(lambdas, nls, false, true)), tol},
+		{"NLWave table", texture.NewLinearGradient(texture.NewNLWave([]float64{19},
+			[]*texture.NonLinear{texture.NewNLSin()}, true, false)), 1e-4},
+		{"DCWave", texture.NewRadialGradient(texture.NewDCWave(lambdas, nls, false)), tol},
+		{"ACWave", texture.NewRadialGradient(texture.NewACWave(lambdas, nls, false)), tol},
+		{"PatternWave", texture.NewLinearGradient(texture.NewPatternWave([]float64{9, 13},
+			[][]float64{{-1, 0.5, 1}, {0.25, -0.75}}, true, false)), tol},
+		{"InvertWave", texture.NewLinearGradient(texture.NewInvertWave(shaderWave(15, false))), tol},
+		{"Perlin", per, tol},
+
+		{"MulCombiner", texture.NewMulCombiner(lin, rad), tol},
+		{"AddCombiner", texture.NewAddCombiner(lin, per), tol},
+		{"SubCombiner", texture.NewSubCombiner(lin, per), tol},
+		{"MinCombiner", texture.NewMinCombiner(lin, rad), tol},
+		{"MaxCombiner", texture.NewMaxCombiner(lin, rad), tol},
+		{"AvgCombiner", texture.NewAvgCombiner(lin, per), tol},
+		{"DiffCombiner", texture.NewDiffCombiner(lin, rad), tol},
+		{"WindowedCombiner", texture.NewWindowedCombiner(lin, rad, -0.3, 0.4), tol},
+		{"WeightedCombiner", texture.NewWeightedCombiner(lin, rad, 0.3, 0.7), tol},
+		{"Blend", texture.NewBlend(lin, rad, per), tol},
+		{"SubstituteCombiner", texture.NewSubstituteCombiner(lin, rad, per, -0.2, 0.3), tol},
+		{"ThresholdCombiner", texture.NewThresholdCombiner(lin, rad, per, 0.1), tol},
+
+		{"NLFilter", texture.NewNLFilter(per, texture.NewNLP5(), 1, 0), tol},
+		{"InvertFilter", texture.NewInvertFilter(per), tol},
+		{"QuantizeFilter", texture.NewQuantizeFilter(rad, 1, 0, 5), tol},
+		{"ClipFilter", texture.NewClipFilter(per, 2, 0.1), tol},
+		{"OffsScaleFilter", texture.NewOffsScaleFilter(per, 1.5, -0.2), tol},
+		{"AbsFilter", texture.NewAbsFilter(per, 1, 0), tol},
+		{"FoldFilter", texture.NewFoldFilter(per, 3, 0.2), tol},
+		{"RandQuantFilter", texture.NewRandQuantFilter(rad, 1, 0, 6), tol},
+		{"RemapFilter", texture.NewRemapFilter(per, -0.5, 0.8), tol},
+		{"FloorFilter", texture.NewFloorFilter(per, 1, 0, -0.2), tol},
+		{"CeilFilter", texture.NewCeilFilter(per, 1, 0, 0.3), tol},
+
+		{"Transform", texture.NewTransform(lin, xfm), tol},
+		{"RadialWF", texture.NewWarp(lin, texture.NewRadialWF(c, 1.5, 0.5)), tol},
+		{"SwirlWF", texture.NewWarp(lin, texture.NewSwirlWF(c, 0.01)), tol},
+		{"DrainWF", texture.NewWarp(lin, texture.NewDrainWF(c, 0.5, 60)), tol},
+		{"RadialNLWF", texture.NewWarp(lin, texture.NewRadialNLWF(c, texture.NewNLP3(), 60)), tol},
+		{"PinchXWF", texture.NewWarp(lin, texture.NewPinchXWF(c, 0.5, 0.2, 0.1)), tol},
+		{"RippleXWF", texture.NewWarp(rad, texture.NewRippleXWF(20, 5, 0)), tol},
+		{"RadialRippleWF", texture.NewWarp(lin, texture.NewRadialRippleWF(c, 25, 4, 0.5)), tol},
+		{"RadialWiggleWF", texture.NewWarp(lin, texture.NewRadialWiggleWF(c, 25, 0.1, 0)), tol},
+
+		{"Fractal FBM", texture.NewFractal(per, graphics2d.Scale(2, 2), texture.NewFBM(1, 2, 4), 3), tol},
+		{"Fractal MF", texture.NewFractal(per, graphics2d.Scale(2, 2), texture.NewMF(0.5, 2, 0.5, 4), 3), tol},
+		{"Fractal partial", texture.NewFractal(per, graphics2d.Scale(2, 2), texture.NewFBM(1, 2, 4), 2.5), tol},
+
+		// The tree rounds its colors to 8 bits
+		{"ColorConv", texture.NewColorConv(per, color.NRGBA{255, 0, 0, 255}, color.NRGBA{0, 0, 255, 64},
+			[]color.Color{color.NRGBA{0, 255, 0, 160}}, []float64{0.4}, texture.LerpRGBA), 0.005},
+	}
+	for _, test := range tests {
+		d, err := texture.CheckShader(test.tree, 64, 64, -80, -60, 2.5, 2.5)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !(d <= test.tol) {
+			t.Errorf("%s: difference %g exceeds %g", test.name, d, test.tol)
+		}
+	}
+}
+
+func TestShaderUnsupported(t *testing.T) {
+	lin := texture.NewLinearGradient(shaderWave(23, true))
+	tree := texture.NewStochasticBlend(lin, texture.NewPerlin(1), lin)
+	for _, lang := range []texture.ShaderLang{texture.GLSL, texture.WGSL} {
+		_, err := texture.Shader(tree, lang)
+		var ue *texture.UnsupportedError
+		if !errors.As(err, &ue) {
+			t.Fatalf("Shader returned %v, expected an UnsupportedError", err)
+		}
+		if want := []string{"StochasticBlend(n1) is random"}; !reflect.DeepEqual(ue.Nodes, want) {
+			t.Errorf("unsupported nodes %q, expected %q", ue.Nodes, want)
+		}
+	}
+
+	// Each unsupported node is listed once
+	wf := texture.NewWorleyField([][]float64{{10, 10}, {60, 25}}, nil, []float64{1}, nil, nil, 0.02, -1)
+	_, err := texture.CheckShader(texture.NewMulCombiner(wf, texture.NewAddCombiner(wf, lin)), 1, 1, 0, 0, 1, 1)
+	var ue *texture.UnsupportedError
+	if !errors.As(err, &ue) || !reflect.DeepEqual(ue.Nodes, []string{"WorleyField(n2)"}) {
+		t.Errorf("CheckShader returned %v", err)
+	}
+}
+
+func TestShaderText(t *testing.T) {
+	tree := texture.NewAddCombiner(texture.NewLinearGradient(texture.NewNLWave([]float64{19},
+		[]*texture.NonLinear{texture.NewNLSin()}, true, false)), texture.NewPerlin(2))
+	glsl, err := texture.Shader(tree, texture.GLSL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wgsl, err := texture.Shader(tree, texture.WGSL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"vec4 tex(vec2 p) {", "const float tab0[257] = float[257](", "mod(", "? "} {
+		if !strings.Contains(glsl, s) {
+			t.Errorf("GLSL lacks %q", s)
+		}
+	}
+	for _, s := range []string{"fn tex(p: vec2<f32>) -> vec4<f32> {", "var<private> tab0: array<f32, 257>",
+		"select(", "let t"} {
+		if !strings.Contains(wgsl, s) {
+			t.Errorf("WGSL lacks %q", s)
+		}
+	}
+	if strings.Contains(glsl, ",\n)") {
+		t.Error("GLSL table has a trailing comma")
+	}
+	if d, err := texture.CheckShader(tree, 32, 32, 0, 0, 3, 3); err != nil || math.IsNaN(d) {
+		t.Errorf("CheckShader returned %g, %v", d, err)
+	}
+}
@@ -0,0 +1,582 @@
+package texture
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"text/scanner"
+)
+
+// shFunc is a shader function, parsed from the text written by shProg.code, for CheckShader to interpret.
+// Only the subset of GLSL and WGSL that code writes is understood. Expressions are held as shExprs in
+// which var is a variable, by slot, and index a table lookup. Booleans are 0 or 1.
+type shFunc struct {
+	lang   ShaderLang
+	toks   []shToken
+	pos    int
+	tables map[string][]float64
+	slots  map[string]int  // Variable slots by name
+	bools  []bool          // Whether each variable is a boolean
+	stmts  []*shExpr       // Statements setting the variables, in slot order
+	out    [4]*shExpr      // Returned color
+	prog   shProg          // For apply
+	types  map[*shExpr]int // Expression types
+}
+
+// Expression types.
+const (
+	shFloat = iota
+	shBoolean
+	shInt
+)
+
+var shTypeNames = []string{"float", "bool", "int"}
+
+type shToken struct {
+	tok  rune
+	text string
+	pos  scanner.Position
+}
+
+// parseShader parses the text of a shader function written by shProg.code in the language.
+func parseShader(src string, lang ShaderLang) (*shFunc, error) {
+	f := &shFunc{lang: lang, tables: map[string][]float64{}, slots: map[string]int{}, types: map[*shExpr]int{}}
+	var s scanner.Scanner
+	s.Init(strings.NewReader(src))
+	s.Mode = scanner.ScanIdents | scanner.ScanFloats | scanner.ScanInts | scanner.ScanComments |
+		scanner.SkipComments
+	var serr error
+	s.Error = func(s *scanner.Scanner, msg string) {
+		if serr == nil {
+			serr = fmt.Errorf("%s:%d:%d: %s", shLangNames[lang], s.Position.Line, s.Position.Column, msg)
+		}
+	}
+	for {
+		tok := s.Scan()
+		t := shToken{tok, s.TokenText(), s.Position}
+		// Join the two character operators
+		if n := len(f.toks); n > 0 {
+			prev := &f.toks[n-1]
+			if op := prev.text + t.text; prev.pos.Offset+1 == t.pos.Offset &&
+				strings.Contains(" <= >= == != && || -> ", " "+op+" ") {
+				prev.text = op
+				continue
+			}
+		}
+		f.toks = append(f.toks, t)
+		if tok == scanner.EOF {
+			break
+		}
+	}
+	if serr != nil {
+		return nil, serr
+	}
+	if err := f.function(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+var shLangNames = []string{"GLSL", "WGSL"}
+
+func (f *shFunc) errorf(t shToken, format string, args ...any) error {
+	return fmt.Errorf("%s:%d:%d: %s", shLangNames[f.lang], t.pos.Line, t.pos.Column, fmt.Sprintf(format, args...))
+}
+
+func (f *shFunc) peek() shToken {
+	return f.toks[f.pos]
+}
+
+func (f *shFunc) next() shToken {
+	t := f.toks[f.pos]
+	if t.tok != scanner.EOF {
+		f.pos++
+	}
+	return t
+}
+
+// expect reads the space separated tokens in seq.
+func (f *shFunc) expect(seq string) error {
+	for _, s := range strings.Fields(seq) {
+		if t := f.next(); t.text != s {
+			return f.errorf(t, "expected %q, found %q", s, t.text)
+		}
+	}
+	return nil
+}
+
+func (f *shFunc) ident() (shToken, error) {
+	t := f.next()
+	if t.tok != scanner.Ident {
+		return t, f.errorf(t, "expected a name, found %q", t.text)
+	}
+	return t, nil
+}
+
+// size reads an array size.
+func (f *shFunc) size() (int, error) {
+	t := f.next()
+	n, err := strconv.Atoi(t.text)
+	if t.tok != scanner.Int || err != nil || n <= 0 {
+		return 0, f.errorf(t, "bad array size %q", t.text)
+	}
+	return n, nil
+}
+
+// function parses the table declarations and the function.
+func (f *shFunc) function() error {
+	// Tables
+	for f.peek().text == "const" || f.peek().text == "var" {
+		var name shToken
+		var n, n2 int
+		var err error
+		if f.lang == WGSL {
+			if err = f.expect("var < private >"); err != nil {
+				return err
+			}
+			if name, err = f.ident(); err != nil {
+				return err
+			}
+			if err = f.expect(": array < f32 ,"); err != nil {
+				return err
+			}
+			if n, err = f.size(); err != nil {
+				return err
+			}
+			if err = f.expect("> = array < f32 ,"); err != nil {
+				return err
+			}
+			if n2, err = f.size(); err != nil {
+				return err
+			}
+			err = f.expect("> (")
+		} else {
+			if err = f.expect("const float"); err != nil {
+				return err
+			}
+			if name, err = f.ident(); err != nil {
+				return err
+			}
+			if err = f.expect("["); err != nil {
+				return err
+			}
+			if n, err = f.size(); err != nil {
+				return err
+			}
+			if err = f.expect("] = float ["); err != nil {
+				return err
+			}
+			if n2, err = f.size(); err != nil {
+				return err
+			}
+			err = f.expect("] (")
+		}
+		if err != nil {
+			return err
+		}
+		if n != n2 {
+			return f.errorf(name, "%s has sizes %d and %d", name.text, n, n2)
+		}
+		if _, ok := f.tables[name.text]; ok {
+			return f.errorf(name, "%s is already declared", name.text)
+		}
+		vals := make([]float64, 0, n)
+		for {
+			e, err := f.expr()
+			if err != nil {
+				return err
+			}
+			if e.op != "c" || f.types[e] != shFloat {
+				return f.errorf(name, "%s[%d] isn't a float constant", name.text, len(vals))
+			}
+			vals = append(vals, e.val)
+			if t := f.next(); t.text == ")" {
+				break
+			} else if t.text != "," {
+				return f.errorf(t, "expected ',' or ')', found %q", t.text)
+			}
+		}
+		if len(vals) != n {
+			return f.errorf(name, "%s has %d values, expected %d", name.text, len(vals), n)
+		}
+		if err := f.expect(";"); err != nil {
+			return err
+		}
+		f.tables[name.text] = vals
+	}
+
+	vec4 := "vec4"
+	if f.lang == WGSL {
+		vec4 = "vec4 < f32 >"
+		if err := f.expect("fn tex ( p : vec2 < f32 > ) -> vec4 < f32 > {"); err != nil {
+			return err
+		}
+	} else if err := f.expect("vec4 tex ( vec2 p ) {"); err != nil {
+		return err
+	}
+
+	// Assignments
+	for {
+		t := f.next()
+		typ := -1
+		switch {
+		case f.lang == WGSL && t.text == "let":
+		case f.lang == GLSL && t.text == "float":
+			typ = shFloat
+		case f.lang == GLSL && t.text == "bool":
+			typ = shBoolean
+		case t.text == "return":
+			f.pos--
+		default:
+			return f.errorf(t, "unexpected %q", t.text)
+		}
+		if t.text == "return" {
+			break
+		}
+		name, err := f.ident()
+		if err != nil {
+			return err
+		}
+		if _, ok := f.slots[name.text]; ok || f.tables[name.text] != nil || name.text == "p" {
+			return f.errorf(name, "%s is already declared", name.text)
+		}
+		if err := f.expect("="); err != nil {
+			return err
+		}
+		e, err := f.expr()
+		if err != nil {
+			return err
+		}
+		if typ < 0 {
+			typ = f.types[e]
+		}
+		if f.types[e] != typ || typ == shInt {
+			return f.errorf(name, "can't assign %s to %s %s", shTypeNames[f.types[e]], shTypeNames[typ],
+				name.text)
+		}
+		if err := f.expect(";"); err != nil {
+			return err
+		}
+		f.slots[name.text] = len(f.stmts)
+		f.stmts = append(f.stmts, e)
+		f.bools = append(f.bools, typ == shBoolean)
+	}
+
+	if err := f.expect("return " + vec4 + " ("); err != nil {
+		return err
+	}
+	for i := range f.out {
+		if i > 0 {
+			if err := f.expect(","); err != nil {
+				return err
+			}
+		}
+		t := f.peek()
+		e, err := f.expr()
+		if err != nil {
+			return err
+		}
+		if f.types[e] != shFloat {
+			return f.errorf(t, "returned component %d is a %s", i, shTypeNames[f.types[e]])
+		}
+		f.out[i] = e
+	}
+	if err := f.expect(") ; }"); err != nil {
+		return err
+	}
+	if t := f.next(); t.tok != scanner.EOF {
+		return f.errorf(t, "unexpected %q after the function", t.text)
+	}
+	return nil
+}
+
+// node returns a new expression of type typ.
+func (f *shFunc) node(typ int, op string, val float64, args ...*shExpr) *shExpr {
+	e := &shExpr{op: op, args: args, val: val}
+	f.types[e] = typ
+	return e
+}
+
+// check returns an error if the arguments of an operation aren't of type typ.
+func (f *shFunc) check(t shToken, typ int, args ...*shExpr) error {
+	for _, a := range args {
+		if f.types[a] != typ {
+			return f.errorf(t, "%s needs a %s, found a %s", t.text, shTypeNames[typ], shTypeNames[f.types[a]])
+		}
+	}
+	return nil
+}
+
+// expr parses the conditional operator, which only GLSL has.
+func (f *shFunc) expr() (*shExpr, error) {
+	c, err := f.binary(0)
+	if err != nil || f.peek().text != "?" || f.lang != GLSL {
+		return c, err
+	}
+	t := f.next()
+	a, err := f.expr()
+	if err != nil {
+		return nil, err
+	}
+	if err := f.expect(":"); err != nil {
+		return nil, err
+	}
+	b, err := f.expr()
+	if err != nil {
+		return nil, err
+	}
+	if err := f.check(t, shBoolean, c); err != nil {
+		return nil, err
+	}
+	if err := f.check(t, shFloat, a, b); err != nil {
+		return nil, err
+	}
+	return f.node(shFloat, "?", 0, c, a, b), nil
+}
+
+// shPrec lists the binary operators by precedence, lowest first.
+var shPrec = [][]string{{"||"}, {"&&"}, {"==", "!="}, {"<", "<=", ">", ">="}, {"+", "-"}, {"*", "/"}}
+
+// binary parses the binary operators of precedence prec and above.
+func (f *shFunc) binary(prec int) (*shExpr, error) {
+	if prec == len(shPrec) {
+		return f.unary()
+	}
+	x, err := f.binary(prec + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := f.peek()
+		found := false
+		for _, op := range shPrec[prec] {
+			found = found || t.text == op
+		}
+		if !found {
+			return x, nil
+		}
+		f.next()
+		y, err := f.binary(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		in, out := shFloat, shBoolean
+		switch prec {
+		case 0, 1:
+			in = shBoolean
+		case 4, 5:
+			out = shFloat
+		}
+		if err := f.check(t, in, x, y); err != nil {
+			return nil, err
+		}
+		x = f.node(out, t.text, 0, x, y)
+	}
+}
+
+func (f *shFunc) unary() (*shExpr, error) {
+	switch t := f.peek(); t.text {
+	case "-", "!":
+		f.next()
+		x, err := f.unary()
+		if err != nil {
+			return nil, err
+		}
+		if x.op == "c" && t.text == "-" {
+			// Negative literal
+			return f.node(shFloat, "c", -x.val), f.check(t, shFloat, x)
+		}
+		if t.text == "-" {
+			return f.node(shFloat, "neg", 0, x), f.check(t, shFloat, x)
+		}
+		return f.node(shBoolean, "!", 0, x), f.check(t, shBoolean, x)
+	}
+	return f.primary()
+}
+
+// shFuncs lists the functions that can be called, by language, with their number of arguments and
+// the operation they perform.
+var shFuncs = map[string]struct {
+	n  int
+	op string
+}{
+	"floor": {1, "floor"}, "trunc": {1, "trunc"}, "abs": {1, "abs"}, "sqrt": {1, "sqrt"}, "sin": {1, "sin"},
+	"cos": {1, "cos"}, "pow": {2, "pow"}, "clamp": {3, "clamp3"},
+	"GLSL atan": {2, "atan2"}, "GLSL mod": {2, "mod"}, "WGSL atan2": {2, "atan2"}, "WGSL select": {3, "select"},
+}
+
+func (f *shFunc) primary() (*shExpr, error) {
+	t := f.next()
+	switch t.tok {
+	case scanner.Float:
+		v, err := strconv.ParseFloat(t.text, 32)
+		if err != nil {
+			return nil, f.errorf(t, "bad float %q", t.text)
+		}
+		return f.node(shFloat, "c", v), nil
+	case scanner.Int:
+		v, err := strconv.Atoi(t.text)
+		if err != nil {
+			return nil, f.errorf(t, "bad int %q", t.text)
+		}
+		return f.node(shInt, "c", float64(v)), nil
+	case '(':
+		e, err := f.expr()
+		if err != nil {
+			return nil, err
+		}
+		return e, f.expect(")")
+	case scanner.Ident:
+	default:
+		return nil, f.errorf(t, "unexpected %q", t.text)
+	}
+
+	switch name := t.text; {
+	case name == "p":
+		if err := f.expect("."); err != nil {
+			return nil, err
+		}
+		c := f.next()
+		if c.text != "x" && c.text != "y" {
+			return nil, f.errorf(c, "p has no component %q", c.text)
+		}
+		return f.node(shFloat, c.text, 0), nil
+	case f.tables[name] != nil:
+		// tab[int(clamp(i, lo, hi))]
+		if err := f.expect("["); err != nil {
+			return nil, err
+		}
+		conv := "int"
+		if f.lang == WGSL {
+			conv = "i32"
+		}
+		if err := f.expect(conv + " ("); err != nil {
+			return nil, err
+		}
+		i, err := f.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := f.check(t, shFloat, i); err != nil {
+			return nil, err
+		}
+		if err := f.expect(") ]"); err != nil {
+			return nil, err
+		}
+		tab := f.tables[name]
+		f.prog.tables = append(f.prog.tables, tab)
+		return f.node(shFloat, "index", float64(len(f.prog.tables)-1), f.node(shInt, "trunc", 0, i)), nil
+	case name == "length":
+		// length(vec2(x, y))
+		vec2 := "vec2 ("
+		if f.lang == WGSL {
+			vec2 = "vec2 < f32 > ("
+		}
+		if err := f.expect("( " + vec2); err != nil {
+			return nil, err
+		}
+		args, err := f.args(t, 2)
+		if err != nil {
+			return nil, err
+		}
+		return f.node(shFloat, "hypot", 0, args...), f.expect(")")
+	}
+	if slot, ok := f.slots[t.text]; ok {
+		typ := shFloat
+		if f.bools[slot] {
+			typ = shBoolean
+		}
+		return f.node(typ, "var", float64(slot)), nil
+	}
+
+	fn, ok := shFuncs[t.text]
+	if !ok {
+		fn, ok = shFuncs[shLangNames[f.lang]+" "+t.text]
+	}
+	if !ok || f.peek().text != "(" {
+		return nil, f.errorf(t, "undefined: %s", t.text)
+	}
+	f.next()
+	args, err := f.args(t, fn.n)
+	if err != nil {
+		return nil, err
+	}
+	if fn.op == "select" {
+		// select(false value, true value, condition)
+		if err := f.check(t, shBoolean, args[2]); err != nil {
+			return nil, err
+		}
+		if err := f.check(t, shFloat, args[:2]...); err != nil {
+			return nil, err
+		}
+		return f.node(shFloat, "?", 0, args[2], args[1], args[0]), nil
+	}
+	if err := f.check(t, shFloat, args...); err != nil {
+		return nil, err
+	}
+	return f.node(shFloat, fn.op, 0, args...), nil
+}
+
+// args parses the n arguments of a call and the closing bracket.
+func (f *shFunc) args(t shToken, n int) ([]*shExpr, error) {
+	args := make([]*shExpr, n)
+	for i := range args {
+		if i > 0 {
+			if err := f.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		a, err := f.expr()
+		if err != nil {
+			return nil, err
+		}
+		args[i] = a
+	}
+	if c := f.next(); c.text != ")" {
+		return nil, f.errorf(c, "%s takes %d arguments", t.text, n)
+	}
+	return args, nil
+}
+
+// eval evaluates the function at x, y, using vals for the variables, and returns the color.
+func (f *shFunc) eval(x, y float64, vals []float64) [4]float64 {
+	for i, e := range f.stmts {
+		vals[i] = f.value(e, x, y, vals)
+	}
+	var res [4]float64
+	for i, e := range f.out {
+		res[i] = f.value(e, x, y, vals)
+	}
+	return res
+}
+
+func (f *shFunc) value(e *shExpr, x, y float64, vals []float64) float64 {
+	switch e.op {
+	case "c":
+		return e.val
+	case "x":
+		return x
+	case "y":
+		return y
+	case "var":
+		return vals[int(e.val)]
+	}
+	var args [3]float64
+	for i, a := range e.args {
+		args[i] = f.value(a, x, y, vals)
+	}
+	switch e.op {
+	case "clamp3":
+		return min(max(args[0], args[1]), args[2])
+	case "index":
+		tab := f.prog.tables[int(e.val)]
+		i := args[0]
+		if i < 0 || i >= float64(len(tab)) || math.IsNaN(i) {
+			// Out of bounds
+			return math.NaN()
+		}
+		return tab[int(i)]
+	}
+	return f.prog.apply(e, args[:len(e.args)])
+}
@@ -0,0 +1,619 @@
+package texture
+
+import (
+	g2d "github.com/jphsd/graphics2d"
+	"math"
+	"slices"
+)
+
+// Arithmetic helpers for building shader expressions.
+
+func (p *shProg) add2(a, b *shExpr) *shExpr   { return p.op("+", a, b) }
+func (p *shProg) sub(a, b *shExpr) *shExpr    { return p.op("-", a, b) }
+func (p *shProg) mul(a, b *shExpr) *shExpr    { return p.op("*", a, b) }
+func (p *shProg) div(a, b *shExpr) *shExpr    { return p.op("/", a, b) }
+func (p *shProg) lt(a, b *shExpr) *shExpr     { return p.op("<", a, b) }
+func (p *shProg) gt(a, b *shExpr) *shExpr     { return p.op(">", a, b) }
+func (p *shProg) sel(c, a, b *shExpr) *shExpr { return p.op("?", c, a, b) }
+
+// abs returns |v| as the nodes compute it, by negating values less than zero.
+func (p *shProg) abs(v *shExpr) *shExpr {
+	return p.sel(p.lt(v, p.c(0)), p.op("neg", v), v)
+}
+
+// lerp returns (1-t)*s + t*e.
+func (p *shProg) lerp(t, s, e *shExpr) *shExpr {
+	return p.add2(p.mul(p.sub(p.c(1), t), s), p.mul(t, e))
+}
+
+// root returns the color components for a tree.
+func (p *shProg) root(tree any) [4]*shExpr {
+	switch t := tree.(type) {
+	case ColorField:
+		return p.color(t, p.x, p.y)
+	case Field:
+		v := p.div(p.add2(p.field(t, p.x, p.y), p.c(1)), p.c(2))
+		return [4]*shExpr{v, v, v, p.c(1)}
+	}
+	z := p.unsupported(tree, "at the root")
+	return [4]*shExpr{z, z, z, z}
+}
+
+// color returns the color components of a color field at x, y.
+func (p *shProg) color(f ColorField, x, y *shExpr) [4]*shExpr {
+	switch n := f.(type) {
+	case *UniformCF:
+		return p.rgba(n.Value)
+	case *ColorGray:
+		t := p.div(p.add2(p.field(n.Src, x, y), p.c(1)), p.c(2))
+		return [4]*shExpr{t, t, t, p.c(1)}
+	case *ColorConv:
+		return p.colorConv(n, x, y)
+	case *TransformCF:
+		if n.Xfm == nil {
+			break
+		}
+		x, y = p.affine(n.Xfm, x, y)
+		return p.color(n.Src, x, y)
+	case *WarpCF:
+		x, y = p.warp(n.Func, x, y)
+		return p.color(n.Src, x, y)
+	}
+	z := p.unsupported(f, "")
+	return [4]*shExpr{z, z, z, z}
+}
+
+// rgba returns the premultiplied components of c.
+func (p *shProg) rgba(c interface{ RGBA() (r, g, b, a uint32) }) [4]*shExpr {
+	if c == nil {
+		z := p.c(0)
+		return [4]*shExpr{z, z, z, z}
+	}
+	r, g, b, a := c.RGBA()
+	return [4]*shExpr{p.c(float64(r) / 0xffff), p.c(float64(g) / 0xffff), p.c(float64(b) / 0xffff),
+		p.c(float64(a) / 0xffff)}
+}
+
+func (p *shProg) colorConv(n *ColorConv, x, y *shExpr) [4]*shExpr {
+	nt := len(n.TVals)
+	if n.Lerp != LerpRGBA || nt == 0 || len(n.Colors) < nt {
+		z := p.unsupported(n, "without LerpRGBA and a color per TVal")
+		return [4]*shExpr{z, z, z, z}
+	}
+	t := p.div(p.add2(p.field(n.Src, x, y), p.c(1)), p.c(2))
+
+	// Select the first interval with t <= TVals[i]
+	res := p.rgba(n.Colors[nt-1])
+	for i := nt - 1; i > 0; i-- {
+		it := p.div(p.sub(t, p.c(n.TVals[i-1])), p.c(n.TVals[i]-n.TVals[i-1]))
+		c1, c2 := p.rgba(n.Colors[i-1]), p.rgba(n.Colors[i])
+		after := p.gt(t, p.c(n.TVals[i]))
+		for k := range res {
+			res[k] = p.sel(after, res[k], p.lerp(it, c1[k], c2[k]))
+		}
+	}
+	return res
+}
+
+// field returns the value of a field at x, y.
+func (p *shProg) field(f Field, x, y *shExpr) *shExpr {
+	if f == nil {
+		return p.unsupported(nil, "field input")
+	}
+	key := [3]any{f, x, y}
+	if r, ok := p.fields[key]; ok {
+		return r
+	}
+	r := p.field1(f, x, y)
+	p.fields[key] = r
+	return r
+}
+
+func (p *shProg) field1(f Field, x, y *shExpr) *shExpr {
+	switch n := f.(type) {
+	case *Uniform:
+		return p.c(n.Value)
+	case *LinearGradient:
+		return p.wave(n.WF, x)
+	case *RadialGradient:
+		return p.wave(n.WF, p.op("hypot", x, y))
+	case *ConicGradient:
+		if n.WF == nil {
+			break
+		}
+		v := p.add2(p.div(p.op("atan2", y, x), p.c(math.Pi)), p.c(1))
+		return p.wave(n.WF, p.div(p.mul(v, p.c(n.WF.Lambda())), p.c(2)))
+	case *Perlin:
+		return p.perlin(n, x, y)
+	case *Transform:
+		if n.Xfm == nil {
+			break
+		}
+		x, y = p.affine(n.Xfm, x, y)
+		return p.field(n.Src, x, y)
+	case *Warp:
+		x, y = p.warp(n.Func, x, y)
+		return p.field(n.Src, x, y)
+	case *Fractal:
+		return p.fractal(n, x, y)
+
+	// Combiners
+	case *MulCombiner:
+		v1, v2 := p.field(n.Src1, x, y), p.field(n.Src2, x, y)
+		o, s := p.c(n.Offs), p.c(n.Scal)
+		res := p.mul(p.mul(p.mul(p.add2(v1, o), s), p.add2(v2, o)), s)
+		return p.op("clamp", p.sub(p.div(res, s), o))
+	case *AddCombiner:
+		v1, v2 := p.field(n.Src1, x, y), p.field(n.Src2, x, y)
+		o, s := p.c(n.Offs), p.c(n.Scal)
+		res := p.add2(p.mul(p.add2(v1, o), s), p.mul(p.add2(v2, o), s))
+		return p.op("clamp", p.sub(p.div(res, s), o))
+	case *SubCombiner:
+		v1, v2 := p.field(n.Src1, x, y), p.field(n.Src2, x, y)
+		o, s := p.c(n.Offs), p.c(n.Scal)
+		res := p.sub(p.mul(p.add2(v1, o), s), p.mul(p.add2(v2, o), s))
+		return p.op("clamp", p.sub(p.div(res, s), o))
+	case *MinCombiner:
+		v1, v2 := p.field(n.Src1, x, y), p.field(n.Src2, x, y)
+		return p.sel(p.lt(v1, v2), v1, v2)
+	case *MaxCombiner:
+		v1, v2 := p.field(n.Src1, x, y), p.field(n.Src2, x, y)
+		return p.sel(p.lt(v1, v2), v2, v1)
+	case *AvgCombiner:
+		return p.div(p.add2(p.field(n.Src1, x, y), p.field(n.Src2, x, y)), p.c(2))
+	case *DiffCombiner:
+		v1, v2 := p.field(n.Src1, x, y), p.field(n.Src2, x, y)
+		t := p.div(p.add2(p.sub(v1, v2), p.c(2)), p.c(4))
+		return p.lerp(t, v1, v2)
+	case *WindowedCombiner:
+		v1 := p.field(n.Src1, x, y)
+		out := p.op("||", p.lt(v1, p.c(n.A)), p.gt(v1, p.c(n.B)))
+		return p.sel(out, v1, p.field(n.Src2, x, y))
+	case *WeightedCombiner:
+		v1, v2 := p.field(n.Src1, x, y), p.field(n.Src2, x, y)
+		return p.op("clamp", p.add2(p.mul(p.c(n.A), v1), p.mul(p.c(n.B), v2)))
+	case *Blend:
+		v1, v2, v3 := p.field(n.Src1, x, y), p.field(n.Src2, x, y), p.field(n.Src3, x, y)
+		return p.lerp(p.div(p.add2(v3, p.c(1)), p.c(2)), v1, v2)
+	case *SubstituteCombiner:
+		v1, v3 := p.field(n.Src1, x, y), p.field(n.Src3, x, y)
+		out := p.op("||", p.lt(v3, p.c(n.A)), p.gt(v3, p.c(n.B)))
+		return p.sel(out, v1, p.field(n.Src2, x, y))
+	case *ThresholdCombiner:
+		v3 := p.field(n.Src3, x, y)
+		return p.sel(p.lt(v3, p.c(n.A)), p.field(n.Src1, x, y), p.field(n.Src2, x, y))
+
+	// Filters
+	case *NLFilter:
+		if n.NLFunc == nil {
+			break
+		}
+		v := p.add2(p.mul(p.field(n.Src, x, y), p.c(n.A)), p.c(n.B))
+		neg := p.lt(v, p.c(0))
+		cv := p.curve(n.NLFunc, p.sel(neg, p.op("neg", v), v))
+		return p.sel(neg, p.op("neg", cv), cv)
+	case *InvertFilter:
+		return p.sub(p.c(0), p.field(n.Src, x, y))
+	case *QuantizeFilter:
+		v := p.op("clamp", p.add2(p.mul(p.field(n.Src, x, y), p.c(n.A)), p.c(n.B)))
+		c := max(float64(n.C), 2)
+		v = p.mul(p.div(p.add2(v, p.c(1)), p.c(2)), p.c(c))
+		return p.op("clamp", p.sub(p.div(p.mul(p.c(2), p.op("floor", v)), p.c(c-1)), p.c(1)))
+	case *ClipFilter:
+		return p.op("clamp", p.add2(p.mul(p.field(n.Src, x, y), p.c(n.A)), p.c(n.B)))
+	case *OffsScaleFilter:
+		return p.op("clamp", p.mul(p.add2(p.field(n.Src, x, y), p.c(n.B)), p.c(n.A)))
+	case *AbsFilter:
+		return p.op("clamp", p.abs(p.add2(p.mul(p.field(n.Src, x, y), p.c(n.A)), p.c(n.B))))
+	case *FoldFilter:
+		v := p.add2(p.mul(p.field(n.Src, x, y), p.c(n.A)), p.c(n.B))
+		v = p.abs(p.div(p.add2(v, p.c(1)), p.c(2)))
+		nv := p.op("floor", v)
+		w := p.sub(v, nv)
+		odd := p.op("!=", p.op("mod", nv, p.c(2)), p.c(0))
+		w = p.sel(odd, p.sub(p.c(1), w), w)
+		v = p.sel(p.gt(v, p.c(1)), w, v)
+		return p.sub(p.mul(v, p.c(2)), p.c(1))
+	case *RandQuantFilter:
+		if len(n.M) < n.C || n.C < 1 {
+			break
+		}
+		v := p.op("clamp", p.add2(p.mul(p.field(n.Src, x, y), p.c(n.A)), p.c(n.B)))
+		v = p.mul(p.div(p.add2(v, p.c(1)), p.c(2)), p.c(float64(n.C)))
+		k := p.op("floor", v)
+		k = p.sel(p.op("==", k, p.c(float64(n.C))), p.sub(k, p.c(1)), k)
+		return p.lookup(p.table(n.M), k)
+	case *RemapFilter:
+		t := p.div(p.add2(p.field(n.Src, x, y), p.c(1)), p.c(2))
+		return p.op("clamp", p.add2(p.mul(p.c(n.A), p.sub(p.c(1), t)), p.mul(p.c(n.B), t)))
+	case *FloorFilter:
+		v := p.add2(p.mul(p.field(n.Src, x, y), p.c(n.A)), p.c(n.B))
+		return p.sel(p.lt(v, p.c(n.C)), p.c(n.C), p.op("clamp", v))
+	case *CeilFilter:
+		v := p.add2(p.mul(p.field(n.Src, x, y), p.c(n.A)), p.c(n.B))
+		return p.sel(p.gt(v, p.c(n.C)), p.c(n.C), p.op("clamp", v))
+	case *StochasticBlend, *JitterBlend:
+		return p.unsupported(f, "is random")
+	}
+	return p.unsupported(f, "")
+}
+
+// affine applies a transform to x, y.
+func (p *shProg) affine(a *g2d.Aff3, x, y *shExpr) (*shExpr, *shExpr) {
+	nx := p.add2(p.add2(p.mul(p.c(a[0]), x), p.mul(p.c(a[1]), y)), p.c(a[2]))
+	ny := p.add2(p.add2(p.mul(p.c(a[3]), x), p.mul(p.c(a[4]), y)), p.c(a[5]))
+	return nx, ny
+}
+
+// perlin mirrors Perlin.Eval2, with the hash held in a table.
+func (p *shProg) perlin(n *Perlin, x, y *shExpr) *shExpr {
+	tab := make([]float64, len(n.ph))
+	for i, h := range n.ph {
+		tab[i] = float64(h)
+	}
+	ph := p.table(tab)
+	ix, iy := p.op("floor", x), p.op("floor", y)
+	rx, ry := p.sub(x, ix), p.sub(y, iy)
+	blend := func(t *shExpr) *shExpr {
+		poly := p.add2(p.mul(t, p.sub(p.mul(t, p.c(6)), p.c(15))), p.c(10))
+		return p.mul(p.mul(p.mul(t, t), t), poly)
+	}
+	u, v := blend(rx), blend(ry)
+	hx, hy := p.op("mod", ix, p.c(256)), p.op("mod", iy, p.c(256))
+	a := p.add2(p.lookup(ph, hx), hy)
+	b := p.add2(p.lookup(ph, p.add2(hx, p.c(1))), hy)
+	grad := func(i, x, y *shExpr) *shExpr {
+		h := p.lookup(ph, i)
+		gu := p.sel(p.lt(p.op("mod", h, p.c(4)), p.c(2)), x, p.op("neg", x))
+		gv := p.sel(p.op("==", p.op("mod", h, p.c(2)), p.c(0)), y, p.op("neg", y))
+		return p.add2(gu, gv)
+	}
+	one := p.c(1)
+	rx1, ry1 := p.sub(rx, one), p.sub(ry, one)
+	return p.lerp(v,
+		p.lerp(u, grad(a, rx, ry), grad(b, rx1, ry)),
+		p.lerp(u, grad(p.add2(a, one), rx, ry1), grad(p.add2(b, one), rx1, ry1)))
+}
+
+func (p *shProg) fractal(n *Fractal, x, y *shExpr) *shExpr {
+	oct := int(n.Octaves)
+	r := n.Octaves - float64(oct)
+	oct++
+	if n.Xfm == nil || len(n.Weights) < oct {
+		return p.unsupported(n, "without a transform and a weight per octave")
+	}
+	vals := make([]*shExpr, oct)
+	for i := range vals {
+		vals[i] = p.mul(p.field(n.Src, x, y), p.c(n.Weights[i]))
+		x, y = p.affine(n.Xfm, x, y)
+	}
+	vals[oct-1] = p.mul(vals[oct-1], p.c(r))
+
+	res := p.c(0)
+	switch c := n.Comb.(type) {
+	case *FBM:
+		if len(c.Weights) < oct {
+			break
+		}
+		for i, v := range vals {
+			res = p.add2(res, p.mul(v, p.c(c.Weights[i])))
+		}
+		return p.op("clamp", res)
+	case *MF:
+		if len(c.Weights) < oct {
+			break
+		}
+		for i, v := range vals {
+			res = p.add2(res, p.mul(p.add2(v, p.c(c.Offset)), p.c(c.Weights[i])))
+		}
+		return p.op("clamp", res)
+	}
+	return p.unsupported(n, "without an FBM or MF with a weight per octave")
+}
+
+// warp applies a warp function to x, y.
+func (p *shProg) warp(wf WarpFunc, x, y *shExpr) (*shExpr, *shExpr) {
+	center := func(c []float64) (*shExpr, *shExpr, bool) {
+		if len(c) < 2 {
+			p.unsupported(wf, "without a center")
+			return nil, nil, false
+		}
+		return p.c(c[0]), p.c(c[1]), true
+	}
+	polar := func(dx, dy *shExpr) (*shExpr, *shExpr) {
+		return p.op("hypot", dx, dy), p.op("atan2", dy, dx)
+	}
+	euclid := func(r, th *shExpr) (*shExpr, *shExpr) {
+		return p.mul(r, p.op("cos", th)), p.mul(r, p.op("sin", th))
+	}
+	// wave returns the sine of the position of v within lambda
+	wave := func(v *shExpr, lambda float64) *shExpr {
+		_, l := p.mapLambda(v, lambda)
+		return p.op("sin", p.mul(p.div(l, p.c(lambda)), p.c(twoPi)))
+	}
+
+	switch w := wf.(type) {
+	case *RadialWF:
+		if cx, cy, ok := center(w.Center); ok {
+			lx, ly := p.sub(x, cx), p.sub(y, cy)
+			rr := p.mul(p.op("hypot", lx, ly), p.c(w.RScale))
+			rx := p.add2(p.mul(p.mul(rr, p.op("atan2", ly, lx)), p.c(w.CScale)), cx)
+			return rx, p.add2(rr, cy)
+		}
+	case *SwirlWF:
+		if cx, cy, ok := center(w.Center); ok {
+			r, th := polar(p.sub(x, cx), p.sub(y, cy))
+			dx, dy := euclid(r, p.add2(th, p.mul(r, p.c(w.Scale))))
+			return p.add2(cx, dx), p.add2(cy, dy)
+		}
+	case *DrainWF:
+		if cx, cy, ok := center(w.Center); ok {
+			r, th := polar(p.sub(x, cx), p.sub(y, cy))
+			th = p.add2(th, p.mul(p.sub(p.c(1), p.div(r, p.c(w.Effct))), p.c(w.Scale)))
+			dx, dy := euclid(r, th)
+			far := p.gt(r, p.c(w.Effct))
+			return p.sel(far, x, p.add2(cx, dx)), p.sel(far, y, p.add2(cy, dy))
+		}
+	case *RadialNLWF:
+		if w.NL == nil {
+			break
+		}
+		if cx, cy, ok := center(w.Center); ok {
+			r, th := polar(p.sub(x, cx), p.sub(y, cy))
+			t := p.div(r, p.c(w.Effct))
+			tp := p.div(p.add2(p.curveEval(w.NL, t), p.c(1)), p.c(2))
+			dx, dy := euclid(p.mul(tp, p.c(w.Effct)), th)
+			far := p.gt(r, p.c(w.Effct))
+			return p.sel(far, x, p.add2(cx, dx)), p.sel(far, y, p.add2(cy, dy))
+		}
+	case *PinchXWF:
+		if cx, cy, ok := center(w.Center); ok {
+			dx := p.sub(x, cx)
+			dy := p.abs(p.mul(p.sub(y, cy), p.c(w.Scale)))
+			if !within(w.Alpha, 1, 0.00001) {
+				dy = p.op("pow", dy, p.c(w.Alpha))
+			}
+			dx = p.mul(dx, p.div(p.c(1), p.add2(dy, p.c(w.Init))))
+			return p.add2(cx, dx), y
+		}
+	case *RippleXWF:
+		return p.add2(x, p.mul(wave(p.add2(y, p.c(w.Offset)), w.Lambda), p.c(w.Amplit))), y
+	case *RadialRippleWF:
+		if cx, cy, ok := center(w.Center); ok {
+			r, th := polar(p.sub(x, cx), p.sub(y, cy))
+			dr := p.mul(wave(p.add2(r, p.c(w.Offset)), w.Lambda), p.c(w.Amplit))
+			return euclid(p.add2(r, dr), th)
+		}
+	case *RadialWiggleWF:
+		if cx, cy, ok := center(w.Center); ok {
+			r, th := polar(p.sub(x, cx), p.sub(y, cy))
+			dth := p.mul(wave(p.add2(r, p.c(w.Offset)), w.Lambda), p.c(w.Amplit))
+			return euclid(r, p.add2(th, dth))
+		}
+	default:
+		p.unsupported(wf, "")
+	}
+	return x, y
+}
+
+// mapLambda mirrors MapValueToLambda.
+func (p *shProg) mapLambda(v *shExpr, lambda float64) (*shExpr, *shExpr) {
+	l, zero, one := p.c(lambda), p.c(0), p.c(1)
+	q := p.div(v, l)
+	n := p.op("trunc", q)
+	f := p.sub(q, n)
+
+	// v >= 0
+	below := p.lt(v, l)
+	r := p.sel(below, zero, n)
+	rv := p.sel(below, v, p.mul(l, f))
+
+	// v < 0
+	near := p.gt(v, p.c(-lambda))
+	fneg := p.lt(f, zero)
+	nr := p.sel(near, zero, p.sel(fneg, p.op("neg", n), p.sub(p.op("neg", n), one)))
+	nv := p.sel(near, p.add2(v, l), p.sel(fneg, p.mul(l, p.add2(one, f)), f))
+
+	neg := p.lt(v, zero)
+	return p.sel(neg, nr, r), p.sel(neg, nv, rv)
+}
+
+// flip returns t, reversed if a mirrored wave is in a reversed segment.
+func (p *shProg) flip(t, ov, r *shExpr, i, nl int) *shExpr {
+	rn := p.mul(r, p.c(float64(nl)))
+	one, two := p.c(1), p.c(2)
+	up := p.op("&&", p.gt(ov, p.c(0)), p.op("==", p.op("mod", p.add2(rn, p.c(float64(i))), two), one))
+	down := p.op("&&", p.lt(ov, p.c(0)),
+		p.op("==", p.op("mod", p.sub(p.add2(rn, p.c(float64(nl))), p.c(float64(i))), two), one))
+	return p.sel(p.op("||", up, down), p.sub(one, t), t)
+}
+
+// segments selects the value of the segment containing v, given the cumulative lengths of the segments.
+// Values beyond the last are given by rolled.
+func (p *shProg) segments(v *shExpr, cum []float64, seg func(i int, v1 *shExpr) *shExpr, rolled *shExpr) *shExpr {
+	res := rolled
+	for i := len(cum) - 1; i >= 0; i-- {
+		v1 := v
+		if i > 0 {
+			v1 = p.sub(v, p.c(cum[i-1]))
+		}
+		res = p.sel(p.gt(v, p.c(cum[i])), res, seg(i, v1))
+	}
+	return res
+}
+
+// wave returns the value of a wave at v.
+func (p *shProg) wave(w Wave, v *shExpr) *shExpr {
+	ov := v
+	switch g := w.(type) {
+	case *NLWave:
+		nl := len(g.Lambdas)
+		if nl == 0 || len(g.NLFs) < nl || len(g.CumLambda) < nl || slices.Contains(g.NLFs[:nl], nil) {
+			break
+		}
+		r, v := p.mapLambda(v, g.CumLambda[nl-1])
+		seg := func(i int, t *shExpr) *shExpr {
+			if g.Mirrored {
+				t = p.flip(t, ov, r, i, nl)
+			}
+			return p.curveEval(g.NLFs[i], t)
+		}
+		res := p.segments(v, g.CumLambda[:nl], func(i int, v1 *shExpr) *shExpr {
+			return seg(i, p.div(v1, p.c(g.Lambdas[i])))
+		}, seg(nl-1, p.c(1)))
+		if g.Once {
+			if g.Mirrored {
+				res = p.sel(p.gt(r, p.c(1)), p.c(-1), res)
+			} else {
+				res = p.sel(p.gt(r, p.c(0)), p.c(1), res)
+			}
+			res = p.sel(p.lt(ov, p.c(0)), p.c(-1), res)
+		}
+		return res
+	case *DCWave:
+		if g.NL1 == nil || g.NL2 == nil {
+			break
+		}
+		r, v := p.mapLambda(v, g.Sum)
+		t2 := p.sub(p.c(1), p.div(p.sub(v, p.c(g.L1)), p.c(g.L2)))
+		res := p.sel(p.gt(v, p.c(g.L1)), p.curveEval(g.NL2, t2), p.curveEval(g.NL1, p.div(v, p.c(g.L1))))
+		if g.Once {
+			res = p.sel(p.op("||", p.lt(ov, p.c(0)), p.gt(r, p.c(0))), p.c(-1), res)
+		}
+		return res
+	case *ACWave:
+		if slices.Contains(g.NLFs[:], nil) {
+			break
+		}
+		r, v := p.mapLambda(v, g.CumLambda[3])
+		seg := func(q int, t *shExpr) *shExpr {
+			if q == 1 || q == 3 {
+				t = p.sub(p.c(1), t)
+			}
+			res := p.curve(g.NLFs[q], t)
+			if q > 1 {
+				res = p.op("neg", res)
+			}
+			return res
+		}
+		res := p.segments(v, g.CumLambda[:], func(q int, v1 *shExpr) *shExpr {
+			return seg(q, p.div(v1, p.c(g.Lambdas[q])))
+		}, seg(3, p.c(1)))
+		if g.Once {
+			res = p.sel(p.op("||", p.lt(ov, p.c(0)), p.gt(r, p.c(0))), p.c(0), res)
+		}
+		return res
+	case *PatternWave:
+		nl := len(g.Lambdas)
+		if nl == 0 || len(g.Patterns) < nl || len(g.CumLambda) < nl {
+			break
+		}
+		r, v := p.mapLambda(v, g.CumLambda[nl-1])
+		seg := func(i int, t *shExpr) *shExpr {
+			if g.Mirrored {
+				t = p.flip(t, ov, r, i, nl)
+			}
+			return p.pattern(g.Patterns[i], t)
+		}
+		res := p.segments(v, g.CumLambda[:nl], func(i int, v1 *shExpr) *shExpr {
+			return seg(i, p.div(v1, p.c(g.Lambdas[i])))
+		}, seg(nl-1, p.c(1)))
+		if g.Once {
+			if g.Mirrored {
+				res = p.sel(p.gt(r, p.c(1)), p.c(-1), res)
+			} else {
+				res = p.sel(p.gt(r, p.c(0)), p.c(1), res)
+			}
+		}
+		return res
+	case *InvertWave:
+		return p.op("neg", p.wave(g.Src, v))
+	}
+	return p.unsupported(w, "")
+}
+
+// pattern mirrors the cubic interpolation of a PatternWave pattern at t.
+func (p *shProg) pattern(pat []float64, t *shExpr) *shExpr {
+	pl := len(pat)
+	if pl < 4 {
+		return p.unsupported(nil, "pattern")
+	}
+	dt := 1 / float64(pl-3)
+	j, ft := p.mapLambda(t, dt)
+	tab := p.table(pat)
+	pts := make([]*shExpr, 4)
+	for k := range pts {
+		pts[k] = p.lookup(tab, p.add2(j, p.c(float64(k))))
+	}
+	end := p.lt(p.sub(p.c(1), t), p.c(0.0000001))
+	t = p.div(ft, p.c(dt))
+
+	// Cubic(t, pts)
+	p0, p1, p2, p3 := pts[0], pts[1], pts[2], pts[3]
+	inner := p.sub(p.add2(p.mul(p.c(3), p.sub(p1, p2)), p3), p0)
+	mid := p.sub(p.add2(p.sub(p.mul(p.c(2), p0), p.mul(p.c(5), p1)), p.mul(p.c(4), p2)), p3)
+	mid = p.add2(mid, p.mul(t, inner))
+	outer := p.add2(p.sub(p2, p0), p.mul(t, mid))
+	v := p.op("clamp", p.add2(p1, p.mul(p.mul(p.c(0.5), t), outer)))
+	return p.sel(end, p.c(pat[pl-1]), v)
+}
+
+// curveEval returns the value of a curve at t mapped to [-1,1], as NonLinear.Eval does.
+func (p *shProg) curveEval(nl *NonLinear, t *shExpr) *shExpr {
+	return p.sub(p.mul(p.curve(nl, t), p.c(2)), p.c(1))
+}
+
+// shCurves holds the closed forms of the polynomial curves. They're only used if they match the curve.
+var shCurves = map[string]func(t float64) float64{
+	"NLLinear": func(t float64) float64 { return t },
+	"NLSquare": func(t float64) float64 { return t * t },
+	"NLCube":   func(t float64) float64 { return t * t * t },
+	"NLP3":     func(t float64) float64 { return t * t * (3 - 2*t) },
+	"NLP5":     func(t float64) float64 { return t * t * t * (t*(t*6-15) + 10) },
+}
+
+// Number of intervals in curve tables.
+const shCurveSteps = 256
+
+// curve returns the value of a curve at t in [0,1], as NonLinear.Eval0 does.
+func (p *shProg) curve(nl *NonLinear, t *shExpr) *shExpr {
+	if nl == nil || nl.NLF == nil {
+		return p.unsupported(nil, "curve")
+	}
+	if f, ok := shCurves[nl.Name]; ok {
+		match := true
+		for i := 0; i <= 32 && match; i++ {
+			x := float64(i) / 32
+			match = math.Abs(f(x)-nl.Eval0(x)) < 1e-12
+		}
+		if match {
+			switch nl.Name {
+			case "NLLinear":
+				return t
+			case "NLSquare":
+				return p.mul(t, t)
+			case "NLCube":
+				return p.mul(p.mul(t, t), t)
+			case "NLP3":
+				return p.mul(p.mul(t, t), p.sub(p.c(3), p.mul(p.c(2), t)))
+			case "NLP5":
+				poly := p.add2(p.mul(t, p.sub(p.mul(t, p.c(6)), p.c(15))), p.c(10))
+				return p.mul(p.mul(p.mul(t, t), t), poly)
+			}
+		}
+	}
+
+	// Sample the curve and interpolate linearly
+	vals := make([]float64, shCurveSteps+1)
+	for i := range vals {
+		vals[i] = nl.Eval0(float64(i) / shCurveSteps)
+	}
+	tab := p.table(vals)
+	zero, one := p.c(0), p.c(1)
+	t = p.sel(p.lt(t, zero), zero, p.sel(p.gt(t, one), one, t))
+	u := p.mul(t, p.c(shCurveSteps))
+	i := p.op("floor", u)
+	i = p.sel(p.gt(i, p.c(shCurveSteps-1)), p.c(shCurveSteps-1), i)
+	a, b := p.lookup(tab, i), p.lookup(tab, p.add2(i, one))
+	return p.add2(a, p.mul(p.sub(u, i), p.sub(b, a)))
+}
@@ -0,0 +1,45 @@
+package surface
+
+import "math"
+
+// CameraMode defines the projection used by a camera.
+type CameraMode int
+
+// Constants for camera modes.
+const (
+	Orthographic CameraMode = iota
+	Perspective
+)
+
+// Camera provides the view vector, the unit vector from a location on the surface to the eye, for any
+// location. It implements the VectorField interface.
+// For an orthographic camera Eye holds the direction to the eye which is the same for all locations.
+// For a perspective camera Eye holds the position of the eye, with the surface lying in the XY plane.
+type Camera struct {
+	Mode CameraMode
+	Eye  []float64
+	FOV  float64 // Field of view in radians (perspective only)
+}
+
+// DefaultCamera is an orthographic camera looking straight down onto the XY plane.
+var DefaultCamera = &Camera{Orthographic, []float64{0, 0, 1}, 0}
+
+// NewOrthographic returns a new orthographic camera with the supplied direction to the eye.
+func NewOrthographic(dir []float64) *Camera {
+	return &Camera{Orthographic, Unit(dir), 0}
+}
+
+// NewPerspective returns a new perspective camera centered over cx, cy. The eye height is chosen so that
+// a region of the supplied width is subtended by the field of view.
+func NewPerspective(cx, cy, width, fov float64) *Camera {
+	h := width / 2 / math.Tan(fov/2)
+	return &Camera{Perspective, []float64{cx, cy, h}, fov}
+}
+
+// Eval2 implements the VectorField interface.
+func (c *Camera) Eval2(x, y float64) []float64 {
+	if c.Mode == Perspective {
+		return Unit([]float64{c.Eye[0] - x, c.Eye[1] - y, c.Eye[2]})
+	}
+	return c.Eye
+}
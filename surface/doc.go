@@ -5,7 +5,10 @@ Package surface contains functions that populate images with lit textured surfac
 	  Light -
 		Ambient
 	    Directional
+	    Environment
 	  Material
+	  Camera
 	  BumpMap
+	  Preview
 */
 package surface
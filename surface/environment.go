@@ -0,0 +1,245 @@
+package surface
+
+import (
+	g2d "github.com/jphsd/graphics2d"
+	"github.com/jphsd/texture"
+	"github.com/jphsd/texture/color"
+	"image"
+	"math"
+)
+
+// Environment describes an image based light. The source color field is evaluated over (theta, phi) where
+// theta [0,2Pi) is the azimuth about the Y axis, measured from +Z towards +X, and phi [0,Pi] is the angle
+// from -Y (up in image space). Diffuse irradiance and a set of roughness levels for specular lookups are
+// prefiltered from the source when the environment is created.
+type Environment struct {
+	Src    texture.ColorField
+	Levels int
+	avg    color.FRGBA
+	irr    *envMap
+	spec   []*envMap
+}
+
+// Sampling resolutions used for prefiltering.
+const (
+	envSampW = 128
+	envSampH = 64
+	envIrrW  = 32
+	envIrrH  = 16
+)
+
+// NewEnvironment returns a new environment light from a color field defined over (theta, phi). Levels
+// determines the number of roughness levels prefiltered for specular lookups (minimum 2).
+func NewEnvironment(src texture.ColorField, levels int) *Environment {
+	if levels < 2 {
+		levels = 2
+	}
+	env := &Environment{Src: src, Levels: levels}
+
+	// Sample the source, and note the solid angle for each sample
+	samps := make([]envSample, 0, envSampW*envSampH)
+	dth, dph := 2*math.Pi/envSampW, math.Pi/envSampH
+	var ar, ag, ab, sw float64
+	for j := 0; j < envSampH; j++ {
+		phi := (float64(j) + 0.5) * dph
+		w := math.Sin(phi) * dth * dph
+		for i := 0; i < envSampW; i++ {
+			th := (float64(i) + 0.5) * dth
+			c := color.NewFRGBA(src.Eval2(th, phi))
+			samps = append(samps, envSample{toDirection(th, phi), c, w})
+			ar += c.R * w
+			ag += c.G * w
+			ab += c.B * w
+			sw += w
+		}
+	}
+	env.avg = color.FRGBA{ar / sw, ag / sw, ab / sw, 1}
+
+	// Diffuse irradiance (cosine weighted)
+	env.irr = newEnvMap(envIrrW, envIrrH, func(dir []float64) color.FRGBA {
+		return convolve(samps, dir, 1)
+	})
+
+	// Specular levels - level 0 is looked up directly from the source
+	env.spec = make([]*envMap, levels)
+	for k := 1; k < levels; k++ {
+		r := float64(k) / float64(levels-1)
+		exp := roughToExponent(r)
+		w, h := envSampW>>k, envSampH>>k
+		if h < 4 {
+			w, h = 8, 4
+		}
+		env.spec[k] = newEnvMap(w, h, func(dir []float64) color.FRGBA {
+			return convolve(samps, dir, exp)
+		})
+	}
+
+	return env
+}
+
+// NewEnvironmentImage returns a new environment light from an equirectangular image.
+func NewEnvironmentImage(img image.Image, levels int) *Environment {
+	rect := img.Bounds()
+	w, h := float64(rect.Dx()), float64(rect.Dy())
+	xfm := g2d.Translate(float64(rect.Min.X), float64(rect.Min.Y))
+	xfm.Scale(w/(2*math.Pi), h/math.Pi)
+	src := texture.NewTransformCF(texture.NewImage(img, texture.LinearInterp), xfm)
+	return NewEnvironment(src, levels)
+}
+
+// Eval2 implements the Light interface. The environment's average color is returned with a nil direction so
+// that renderers unaware of environment lights treat it as ambient.
+func (e *Environment) Eval2(x, y float64) (color.FRGBA, []float64, float64, float64) {
+	return e.avg, nil, -1, 0
+}
+
+// Irradiance returns the prefiltered diffuse irradiance for the unit normal.
+func (e *Environment) Irradiance(normal []float64) color.FRGBA {
+	return e.irr.Lookup(normal)
+}
+
+// Specular returns the prefiltered specular radiance along the unit reflection vector for roughness [0,1].
+func (e *Environment) Specular(refl []float64, rough float64) color.FRGBA {
+	if rough <= 0 {
+		return e.direct(refl)
+	}
+	if rough > 1 {
+		rough = 1
+	}
+
+	// Interpolate between the bracketing levels
+	l := rough * float64(e.Levels-1)
+	k := int(l)
+	t := l - float64(k)
+	var c1 color.FRGBA
+	if k == 0 {
+		c1 = e.direct(refl)
+	} else {
+		c1 = e.spec[k].Lookup(refl)
+	}
+	if k == e.Levels-1 || t < 0.0001 {
+		return c1
+	}
+	c2 := e.spec[k+1].Lookup(refl)
+	return lerpFRGBA(t, c1, c2)
+}
+
+func (e *Environment) direct(dir []float64) color.FRGBA {
+	th, phi := fromDirection(dir)
+	return color.NewFRGBA(e.Src.Eval2(th, phi))
+}
+
+// envSample holds a direction, color and solid angle weight.
+type envSample struct {
+	Dir   []float64
+	Color color.FRGBA
+	W     float64
+}
+
+// convolve calculates the weighted average of the samples using a lobe of cos^exp around dir.
+func convolve(samps []envSample, dir []float64, exp float64) color.FRGBA {
+	var r, g, b, sw float64
+	for _, s := range samps {
+		d := Dot(dir, s.Dir)
+		if d <= 0 {
+			continue
+		}
+		if exp != 1 {
+			d = math.Pow(d, exp)
+		}
+		d *= s.W
+		r += s.Color.R * d
+		g += s.Color.G * d
+		b += s.Color.B * d
+		sw += d
+	}
+	if sw == 0 {
+		return color.FRGBA{0, 0, 0, 1}
+	}
+	return color.FRGBA{r / sw, g / sw, b / sw, 1}
+}
+
+// roughToExponent maps roughness to an equivalent Phong lobe exponent.
+func roughToExponent(r float64) float64 {
+	a := r * r
+	a *= a
+	if a < 0.0001 {
+		a = 0.0001
+	}
+	exp := 2/a - 2
+	if exp < 1 {
+		exp = 1
+	}
+	return exp
+}
+
+// envMap is a small equirectangular map of prefiltered colors.
+type envMap struct {
+	W, H int
+	Data []color.FRGBA
+}
+
+func newEnvMap(w, h int, f func([]float64) color.FRGBA) *envMap {
+	res := &envMap{w, h, make([]color.FRGBA, w*h)}
+	dth, dph := 2*math.Pi/float64(w), math.Pi/float64(h)
+	for j := 0; j < h; j++ {
+		phi := (float64(j) + 0.5) * dph
+		for i := 0; i < w; i++ {
+			res.Data[j*w+i] = f(toDirection((float64(i)+0.5)*dth, phi))
+		}
+	}
+	return res
+}
+
+// Lookup returns the bilinearly interpolated color for the direction.
+func (m *envMap) Lookup(dir []float64) color.FRGBA {
+	th, phi := fromDirection(dir)
+	fx := th/(2*math.Pi)*float64(m.W) - 0.5
+	fy := phi/math.Pi*float64(m.H) - 0.5
+	x0, y0 := math.Floor(fx), math.Floor(fy)
+	u, v := fx-x0, fy-y0
+	ix, iy := int(x0), int(y0)
+	c00, c10 := m.at(ix, iy), m.at(ix+1, iy)
+	c01, c11 := m.at(ix, iy+1), m.at(ix+1, iy+1)
+	return lerpFRGBA(v, lerpFRGBA(u, c00, c10), lerpFRGBA(u, c01, c11))
+}
+
+// at wraps x and clamps y.
+func (m *envMap) at(x, y int) color.FRGBA {
+	x %= m.W
+	if x < 0 {
+		x += m.W
+	}
+	if y < 0 {
+		y = 0
+	} else if y >= m.H {
+		y = m.H - 1
+	}
+	return m.Data[y*m.W+x]
+}
+
+func lerpFRGBA(t float64, c1, c2 color.FRGBA) color.FRGBA {
+	omt := 1 - t
+	return color.FRGBA{omt*c1.R + t*c2.R, omt*c1.G + t*c2.G, omt*c1.B + t*c2.B, omt*c1.A + t*c2.A}
+}
+
+// toDirection converts theta and phi to a unit vector.
+func toDirection(th, phi float64) []float64 {
+	sp := math.Sin(phi)
+	return []float64{sp * math.Sin(th), -math.Cos(phi), sp * math.Cos(th)}
+}
+
+// fromDirection converts a unit vector to theta and phi.
+func fromDirection(dir []float64) (float64, float64) {
+	y := -dir[1]
+	if y < -1 {
+		y = -1
+	} else if y > 1 {
+		y = 1
+	}
+	th := math.Atan2(dir[0], dir[2])
+	if th < 0 {
+		th += 2 * math.Pi
+	}
+	return th, math.Acos(y)
+}
@@ -0,0 +1,268 @@
+package surface
+
+import (
+	"github.com/jphsd/texture"
+	"github.com/jphsd/texture/color"
+	col "image/color"
+	"math"
+)
+
+// Primitive defines the geometry used by a preview.
+type Primitive int
+
+// Constants for preview primitives.
+const (
+	SpherePrimitive Primitive = iota
+	CubePrimitive
+	PlanePrimitive
+)
+
+// Mapping defines how a location on a primitive is mapped to a location in the texture.
+type Mapping int
+
+// Constants for texture mappings.
+const (
+	SphericalMapping Mapping = iota
+	TriplanarMapping
+	PlanarMapping
+)
+
+// Preview renders a surface wrapped onto a sphere, rounded cube or tilted plane. The surface's material and
+// normals are evaluated at the mapped texture location, with the normals treated as tangent space normals
+// that perturb the primitive's geometric normal. The surface's lights and camera are used for shading and
+// ray casting. The primitive is centered on the origin with a radius of 1, so the preview should be realized
+// over approximately [-1.2,1.2] in x and y. Scale is the number of texture units per unit of primitive.
+// If Albedo is set, it replaces the material's ambient and diffuse reflectances.
+type Preview struct {
+	Surf       *Surface
+	Prim       Primitive
+	Mapping    Mapping
+	Scale      float64
+	Albedo     texture.ColorField
+	Rotation   *Quaternion
+	Background col.Color
+	inv        *Quaternion
+}
+
+// NewPreview returns a new preview of the surface using the primitive's default orientation.
+func NewPreview(surf *Surface, prim Primitive, mapping Mapping, scale float64) *Preview {
+	var rot *Quaternion
+	switch prim {
+	case CubePrimitive:
+		rot = NewQuaternion([]float64{1, 1, 0}, math.Pi/5)
+	case PlanePrimitive:
+		rot = NewQuaternion([]float64{1, 0, 0}, math.Pi/3)
+	default:
+		rot = NewQuaternion([]float64{0, 0, 1}, 0)
+	}
+	return NewPreviewRotated(surf, prim, mapping, scale, rot)
+}
+
+// NewPreviewRotated returns a new preview of the surface with the primitive rotated by rot.
+func NewPreviewRotated(surf *Surface, prim Primitive, mapping Mapping, scale float64, rot *Quaternion) *Preview {
+	inv := NewQuaternion(rot.Vec, -rot.Ang)
+	return &Preview{surf, prim, mapping, scale, nil, rot, col.Transparent, inv}
+}
+
+// Eval2 implements the ColorField interface.
+func (p *Preview) Eval2(x, y float64) col.Color {
+	camera := p.Surf.Camera
+	if camera == nil {
+		camera = DefaultCamera
+	}
+
+	// Construct the ray from the camera through x, y
+	var org, dir []float64
+	if camera.Mode == Perspective {
+		org = camera.Eye
+		dir = Unit([]float64{x - org[0], y - org[1], -org[2]})
+	} else {
+		e := camera.Eye
+		org = []float64{x + e[0]*10, y + e[1]*10, e[2] * 10}
+		dir = []float64{-e[0], -e[1], -e[2]}
+	}
+
+	// Move ray into object space
+	od := p.inv.Apply(org, dir)
+	t, ok := p.intersect(od[0], od[1])
+	if !ok {
+		return p.Background
+	}
+	op := []float64{od[0][0] + t*od[1][0], od[0][1] + t*od[1][1], od[0][2] + t*od[1][2]}
+	on := p.normal(op)
+
+	em, amb, diff, spec, shine, rough, normal := p.texture(op, on)
+
+	// Move point and normal back into world space
+	wpn := p.Rotation.Apply(op, normal)
+	view := []float64{-dir[0], -dir[1], -dir[2]}
+	c := p.Surf.Shade(wpn[0][0], wpn[0][1], Unit(wpn[1]), view, em, amb, diff, spec, shine, rough)
+	c.A = 1 // Primitives are opaque
+	return c
+}
+
+// intersect returns the ray parameter of the first intersection with the primitive.
+func (p *Preview) intersect(org, dir []float64) (float64, bool) {
+	switch p.Prim {
+	case CubePrimitive:
+		// Sphere trace the rounded box from its bounding sphere
+		t, ok := sphereHit(org, dir, 1.5)
+		if !ok {
+			return 0, false
+		}
+		for i := 0; i < 128; i++ {
+			pt := []float64{org[0] + t*dir[0], org[1] + t*dir[1], org[2] + t*dir[2]}
+			d := roundBox(pt)
+			if d < 0.00001 {
+				return t, true
+			}
+			t += d
+			if t > 100 {
+				break
+			}
+		}
+		return 0, false
+	case PlanePrimitive:
+		if math.Abs(dir[2]) < 0.00001 {
+			return 0, false
+		}
+		t := -org[2] / dir[2]
+		if t < 0 {
+			return 0, false
+		}
+		x, y := org[0]+t*dir[0], org[1]+t*dir[1]
+		if x < -1 || x > 1 || y < -1 || y > 1 {
+			return 0, false
+		}
+		return t, true
+	default:
+		return sphereHit(org, dir, 1)
+	}
+}
+
+// normal returns the geometric normal at the object space point.
+func (p *Preview) normal(pt []float64) []float64 {
+	switch p.Prim {
+	case CubePrimitive:
+		const e = 0.0001
+		return Unit([]float64{
+			roundBox([]float64{pt[0] + e, pt[1], pt[2]}) - roundBox([]float64{pt[0] - e, pt[1], pt[2]}),
+			roundBox([]float64{pt[0], pt[1] + e, pt[2]}) - roundBox([]float64{pt[0], pt[1] - e, pt[2]}),
+			roundBox([]float64{pt[0], pt[1], pt[2] + e}) - roundBox([]float64{pt[0], pt[1], pt[2] - e})})
+	case PlanePrimitive:
+		return []float64{0, 0, 1}
+	default:
+		return Unit(pt)
+	}
+}
+
+// texture returns the material properties and perturbed normal for the object space point.
+func (p *Preview) texture(pt, n []float64) (color.FRGBA, color.FRGBA, color.FRGBA, color.FRGBA, float64, float64, []float64) {
+	switch p.Mapping {
+	case TriplanarMapping:
+		// Blend the three axis aligned projections
+		w := []float64{n[0] * n[0] * n[0] * n[0], n[1] * n[1] * n[1] * n[1], n[2] * n[2] * n[2] * n[2]}
+		sum := w[0] + w[1] + w[2]
+		var em, amb, diff, spec color.FRGBA
+		var shine, rough float64
+		norm := []float64{0, 0, 0}
+		for i := 0; i < 3; i++ {
+			wi := w[i] / sum
+			if wi < 0.0001 {
+				continue
+			}
+			u, v, tu, tv := axisProjection(i, pt, n)
+			e, a, d, s, sh, r, pn := p.sample(u, v, tu, tv, n)
+			em, amb, diff, spec = addScaled(em, e, wi), addScaled(amb, a, wi), addScaled(diff, d, wi), addScaled(spec, s, wi)
+			shine += sh * wi
+			rough += r * wi
+			norm[0] += pn[0] * wi
+			norm[1] += pn[1] * wi
+			norm[2] += pn[2] * wi
+		}
+		return em, amb, diff, spec, shine, rough, Unit(norm)
+	case PlanarMapping:
+		return p.sample(pt[0], pt[1], []float64{1, 0, 0}, []float64{0, 1, 0}, n)
+	default:
+		// Spherical - the tangent follows theta and the bitangent phi
+		th, phi := fromDirection(Unit(pt))
+		tu := Unit([]float64{n[2], 0, -n[0]})
+		if n[0]*n[0]+n[2]*n[2] < 0.000001 {
+			tu = []float64{1, 0, 0}
+		}
+		tv := Cross(n, tu)
+		return p.sample(th, phi, tu, tv, n)
+	}
+}
+
+// sample evaluates the surface's material and normals at u, v and perturbs n by the tangent space normal
+// using the supplied tangent and bitangent.
+func (p *Preview) sample(u, v float64, tu, tv, n []float64) (color.FRGBA, color.FRGBA, color.FRGBA, color.FRGBA, float64, float64, []float64) {
+	x, y := u*p.Scale, v*p.Scale
+	material := p.Surf.Mat
+	if material == nil {
+		material = DefaultMaterial
+	}
+	em, amb, diff, spec, shine, rough := material.Eval2(x, y)
+	if p.Albedo != nil {
+		c := color.NewFRGBA(p.Albedo.Eval2(x, y))
+		amb, diff = c, c
+	}
+	tn := []float64{0, 0, 1}
+	if p.Surf.Normals != nil {
+		tn = p.Surf.Normals.Eval2(x, y)
+	}
+	pn := []float64{
+		tu[0]*tn[0] + tv[0]*tn[1] + n[0]*tn[2],
+		tu[1]*tn[0] + tv[1]*tn[1] + n[1]*tn[2],
+		tu[2]*tn[0] + tv[2]*tn[1] + n[2]*tn[2]}
+	return em, amb, diff, spec, shine, rough, Unit(pn)
+}
+
+// axisProjection returns the planar coordinates, tangent and bitangent for projecting pt along axis i.
+func axisProjection(i int, pt, n []float64) (float64, float64, []float64, []float64) {
+	switch i {
+	case 0:
+		s := math.Copysign(1, n[0])
+		return -s * pt[2], pt[1], []float64{0, 0, -s}, []float64{0, 1, 0}
+	case 1:
+		s := math.Copysign(1, n[1])
+		return pt[0], -s * pt[2], []float64{1, 0, 0}, []float64{0, 0, -s}
+	default:
+		s := math.Copysign(1, n[2])
+		return s * pt[0], pt[1], []float64{s, 0, 0}, []float64{0, 1, 0}
+	}
+}
+
+func addScaled(c1, c2 color.FRGBA, s float64) color.FRGBA {
+	return color.FRGBA{c1.R + c2.R*s, c1.G + c2.G*s, c1.B + c2.B*s, c1.A + c2.A*s}
+}
+
+// sphereHit returns the ray parameter of the first intersection with a sphere of radius r at the origin.
+func sphereHit(org, dir []float64, r float64) (float64, bool) {
+	b := Dot(org, dir)
+	c := Dot(org, org) - r*r
+	disc := b*b - c
+	if disc < 0 {
+		return 0, false
+	}
+	sd := math.Sqrt(disc)
+	t := -b - sd
+	if t < 0 {
+		t = -b + sd
+		if t < 0 {
+			return 0, false
+		}
+		// Ray starts inside the sphere
+		return 0, true
+	}
+	return t, true
+}
+
+// roundBox is the signed distance to a box of half size 0.75 with edges rounded by 0.2.
+func roundBox(pt []float64) float64 {
+	const b, r = 0.55, 0.2
+	qx, qy, qz := math.Abs(pt[0])-b, math.Abs(pt[1])-b, math.Abs(pt[2])-b
+	ox, oy, oz := math.Max(qx, 0), math.Max(qy, 0), math.Max(qz, 0)
+	return math.Sqrt(ox*ox+oy*oy+oz*oz) + math.Min(math.Max(qx, math.Max(qy, qz)), 0) - r
+}
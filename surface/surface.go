@@ -8,13 +8,17 @@ import (
 	"math/rand"
 )
 
-// Surface collects the ambient light, lights, a material, and normal map required to describe
-// an area. If the normal map is nil then the standard normal is used {0, 0, 1}
+// Surface collects the ambient light, lights, a material, normal map and camera required to describe
+// an area. If the normal map is nil then the standard normal is used {0, 0, 1}, and if the camera is nil
+// then the view vector is {0, 0, 1}. If HDR is set, the light contributions aren't clamped to [0,1] and
+// the result should be realized with [texture.NewTextureFRGBA] or tone mapped (see [texture.ToneMap]).
 type Surface struct {
 	Ambient Light
 	Lights  []Light
 	Mat     Material
 	Normals texture.VectorField
+	Camera  *Camera
+	HDR     bool
 }
 
 var blinn = false
@@ -25,21 +29,34 @@ func (s *Surface) Eval2(x, y float64) col.Color {
 	// For any point, the color rendered is the sum of the emissive, ambient and the diffuse/specular
 	// contributions from all of the lights.
 
-	material := s.Mat
 	normals := s.Normals
 	if normals == nil {
 		normals = texture.DefaultNormal
 	}
-	ambient := s.Ambient
-	view := []float64{0, 0, 1}
+	camera := s.Camera
+	if camera == nil {
+		camera = DefaultCamera
+	}
 
-	em, amb, diff, spec, shine, rough := material.Eval2(x, y) // Emissive
+	em, amb, diff, spec, shine, rough := s.Mat.Eval2(x, y)
+	return s.Shade(x, y, normals.Eval2(x, y), camera.Eval2(x, y), em, amb, diff, spec, shine, rough)
+}
+
+// Shade calculates the color at a location given the unit normal and view vectors, and the material
+// properties there. It allows the surface's lights to be applied to geometry other than the XY plane.
+func (s *Surface) Shade(x, y float64, normal, view []float64,
+	em, amb, diff, spec color.FRGBA, shine, rough float64) color.FRGBA {
+	ambient := s.Ambient
+	add := color.FRGBA.Add
+	if s.HDR {
+		add = color.FRGBA.Sum
+	}
 
 	// Ambient
 	acol, _, _, _ := ambient.Eval2(x, y)
 	lamb := amb.Prod(acol) // Ambient
 	col := em              // Emissive
-	col = col.Add(lamb)
+	col = add(col, lamb)
 
 	// If material has no diffuse relflectance, we're done
 	if diff.IsBlack() {
@@ -47,7 +64,6 @@ func (s *Surface) Eval2(x, y float64) col.Color {
 	}
 
 	// Cummulative diffuse and specular for all lights
-	normal := normals.Eval2(x, y)
 	nd, ns := normal, normal
 	if rough > 0 {
 		nd = Roughen(rough, normal)
@@ -55,6 +71,14 @@ func (s *Surface) Eval2(x, y float64) col.Color {
 	}
 	cdiff, cspec := color.FRGBA{}, color.FRGBA{}
 	for _, light := range s.Lights {
+		if env, ok := light.(*Environment); ok {
+			// Image based - sampled using the normal and reflection vector
+			cdiff = add(cdiff, diff.Prod(env.Irradiance(nd)))
+			if !spec.IsBlack() {
+				cspec = add(cspec, spec.Prod(env.Specular(Reflect(view, ns), rough)))
+			}
+			continue
+		}
 		lcol, dir, dist, pow := light.Eval2(x, y)
 		if lcol.IsBlack() {
 			// Nothing to see here
@@ -67,7 +91,7 @@ func (s *Surface) Eval2(x, y float64) col.Color {
 		if dist > 0 {
 			lcol = lcol.Scale(pow / (dist * dist))
 		}
-		cdiff = cdiff.Add(lcol.Prod(diff.Scale(lambert))) // Diffuse
+		cdiff = add(cdiff, lcol.Prod(diff.Scale(lambert))) // Diffuse
 		if !spec.IsBlack() {
 			if blinn {
 				// Blinn-Phong
@@ -75,20 +99,20 @@ func (s *Surface) Eval2(x, y float64) col.Color {
 				dp := Dot(half, ns)
 				if dp > 0 {
 					phong := math.Pow(dp, shine*4)
-					cspec = cspec.Add(lcol.Prod(spec.Scale(phong))) // Specular
+					cspec = add(cspec, lcol.Prod(spec.Scale(phong))) // Specular
 				}
 			} else {
 				// Phong
 				dp := Dot(Reflect(dir, ns), view)
 				if dp > 0 {
 					phong := math.Pow(dp, shine)
-					cspec = cspec.Add(lcol.Prod(spec.Scale(phong))) // Specular
+					cspec = add(cspec, lcol.Prod(spec.Scale(phong))) // Specular
 				}
 			}
 		}
 	}
-	col = col.Add(cdiff)
-	col = col.Add(cspec)
+	col = add(col, cdiff)
+	col = add(col, cspec)
 	return col
 }
 
@@ -0,0 +1,259 @@
+package texture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/draw"
+	"strconv"
+	"strings"
+)
+
+// SheetCell is a labeled color field for a contact sheet.
+type SheetCell struct {
+	Label string
+	Src   ColorField
+}
+
+// Contact sheet layout in pixels.
+const (
+	sheetGap   = 4
+	sheetLabel = 16
+)
+
+// ContactSheet realizes each cell over the region width by height, starting at ox, oy with steps of dx
+// and dy, and lays them out in a grid of cols columns on a white background with the cell's label below it.
+func ContactSheet(cells []SheetCell, cols, width, height int, ox, oy, dx, dy float64) *image.RGBA {
+	if cols < 1 {
+		cols = 1
+	}
+	rows := (len(cells) + cols - 1) / cols
+	cw, ch := width+sheetGap, height+sheetLabel+sheetGap
+	res := image.NewRGBA(image.Rect(0, 0, cols*cw+sheetGap, rows*ch+sheetGap))
+	draw.Draw(res, res.Bounds(), image.White, image.Point{}, draw.Src)
+	d := &font.Drawer{Dst: res, Src: image.Black, Face: basicfont.Face7x13}
+	for i, c := range cells {
+		x, y := sheetGap+(i%cols)*cw, sheetGap+(i/cols)*ch
+		img := NewTextureRGBA(width, height, c.Src, ox, oy, dx, dy, false)
+		draw.Draw(res, image.Rect(x, y, x+width, y+height), img, image.Point{}, draw.Over)
+
+		// Truncate the label to the cell width
+		label := c.Label
+		for len(label) > 0 && d.MeasureString(label).Ceil() > width {
+			label = label[:len(label)-1]
+		}
+		d.Dot = fixed.P(x, y+height+sheetLabel-4)
+		d.DrawString(label)
+	}
+	return res
+}
+
+// SweepParam describes a parameter to vary and its values. Path is a dot separated list of field names,
+// with optional slice indices, from the root of the tree to the parameter, for example "Src.WF.Lambdas[0]".
+// A leading "$." is ignored so that the paths reported by [ReadJSON] can be used.
+type SweepParam struct {
+	Path   string
+	Values []float64
+}
+
+// NewSweepRange returns a SweepParam with n values spaced evenly from start to end inclusive.
+func NewSweepRange(path string, start, end float64, n int) *SweepParam {
+	vals := make([]float64, n)
+	for i := range vals {
+		if n == 1 {
+			vals[i] = start
+		} else {
+			vals[i] = start + (end-start)*float64(i)/float64(n-1)
+		}
+	}
+	return &SweepParam{path, vals}
+}
+
+// Sweep renders a contact sheet of the variants of tree, a field, vector field or color field, as the
+// parameter p1 takes its values. If p2 is not nil, the variants are laid out with a row per p1 value and a
+// column per p2 value. The variants are made with [SetParam], so the tree must be restorable from JSON.
+func Sweep(tree any, width, height int, ox, oy, dx, dy float64, p1, p2 *SweepParam) (*image.RGBA, error) {
+	return SweepFunc(func(v1, v2 float64) (ColorField, error) {
+		t, err := SetParam(tree, p1.Path, v1)
+		if err != nil {
+			return nil, err
+		}
+		if p2 != nil {
+			if t, err = SetParam(t, p2.Path, v2); err != nil {
+				return nil, err
+			}
+		}
+		return ToColorField(t)
+	}, width, height, ox, oy, dx, dy, p1, p2)
+}
+
+// SweepFunc is like [Sweep] but the variants are made by fn, which is passed the values of p1 and p2
+// (or 0 if p2 is nil). It allows trees that can't be restored from JSON, such as those lit with the
+// surface package, to be swept. Only the last element of each Path is used, to label the cells.
+func SweepFunc(fn func(v1, v2 float64) (ColorField, error), width, height int, ox, oy, dx, dy float64, p1, p2 *SweepParam) (*image.RGBA, error) {
+	var cells []SheetCell
+	cols := len(p1.Values)
+	if p2 != nil {
+		cols = len(p2.Values)
+	}
+	for _, v1 := range p1.Values {
+		label := fmt.Sprintf("%s=%g", paramName(p1.Path), v1)
+		if p2 == nil {
+			cf, err := fn(v1, 0)
+			if err != nil {
+				return nil, err
+			}
+			cells = append(cells, SheetCell{label, cf})
+			continue
+		}
+		for _, v2 := range p2.Values {
+			cf, err := fn(v1, v2)
+			if err != nil {
+				return nil, err
+			}
+			cells = append(cells, SheetCell{fmt.Sprintf("%s %s=%g", label, paramName(p2.Path), v2), cf})
+		}
+	}
+	return ContactSheet(cells, cols, width, height, ox, oy, dx, dy), nil
+}
+
+// SeedSweep renders a contact sheet of the trees returned by fn for each of the seeds, such as from
+// the random package, in a grid of cols columns.
+func SeedSweep(fn func(seed int64) ColorField, seeds []int64, cols, width, height int, ox, oy, dx, dy float64) *image.RGBA {
+	cells := []SheetCell{}
+	for _, s := range seeds {
+		cells = append(cells, SheetCell{fmt.Sprintf("seed=%d", s), fn(s)})
+	}
+	return ContactSheet(cells, cols, width, height, ox, oy, dx, dy)
+}
+
+// SetParam returns a copy of tree with the parameter at path (see [SweepParam]) set to v. The copy is made
+// by writing the tree to JSON and reading it back, so that any internal state, such as a Perlin's hash
+// table, is rebuilt from the new value. Integer parameters must be set to integral values and booleans are
+// set to v != 0.
+func SetParam(tree any, path string, v float64) (any, error) {
+	b, err := json.Marshal(jsonValue(tree))
+	if err != nil {
+		return nil, err
+	}
+	var root any
+	if err := json.Unmarshal(b, &root); err != nil {
+		return nil, err
+	}
+
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the container of the parameter
+	cur := root
+	for _, seg := range segs[:len(segs)-1] {
+		if cur, err = seg.get(cur); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	last := segs[len(segs)-1]
+	old, err := last.get(cur)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var nv any = v
+	switch old.(type) {
+	case bool:
+		nv = v != 0
+	case float64:
+	default:
+		return nil, fmt.Errorf("%s: not a number or boolean", path)
+	}
+	if c, ok := cur.(map[string]any); ok {
+		c[last.field] = nv
+	} else {
+		cur.([]any)[last.index] = nv
+	}
+
+	if b, err = json.Marshal(root); err != nil {
+		return nil, err
+	}
+	return ReadJSON(bytes.NewReader(b))
+}
+
+// pathSeg is either a field name or a slice index.
+type pathSeg struct {
+	field string
+	index int
+}
+
+func (s pathSeg) String() string {
+	if s.field != "" {
+		return s.field
+	}
+	return fmt.Sprintf("[%d]", s.index)
+}
+
+// get returns the segment's value from a decoded JSON node or list.
+func (s pathSeg) get(v any) (any, error) {
+	switch c := v.(type) {
+	case map[string]any:
+		if n, ok := c[s.field]; ok && s.field != "" {
+			return n, nil
+		}
+	case []any:
+		if s.field == "" && s.index >= 0 && s.index < len(c) {
+			return c[s.index], nil
+		}
+	}
+	return nil, fmt.Errorf("no %s", s)
+}
+
+func parsePath(path string) ([]pathSeg, error) {
+	p := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	res := []pathSeg{}
+	for _, part := range strings.Split(p, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name != "" {
+			res = append(res, pathSeg{name, -1})
+		}
+		for rest != "" {
+			ind, r, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, fmt.Errorf("%s: missing ]", path)
+			}
+			n, err := strconv.Atoi(ind)
+			if err != nil {
+				return nil, fmt.Errorf("%s: bad index %q", path, ind)
+			}
+			res = append(res, pathSeg{"", n})
+			rest = strings.TrimPrefix(r, "[")
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	return res, nil
+}
+
+// paramName returns the last field name in the path, with any indices following it.
+func paramName(path string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+// ToColorField converts a tree into a color field. Fields are rendered as gray and vector fields as RGB.
+func ToColorField(v any) (ColorField, error) {
+	switch f := v.(type) {
+	case ColorField:
+		return f, nil
+	case Field:
+		return NewColorGray(f), nil
+	case VectorField:
+		return NewColorVector(f, false), nil
+	}
+	return nil, fmt.Errorf("%T is not a field, vector field or color field", v)
+}
@@ -2,6 +2,7 @@ package texture
 
 import (
 	"github.com/jphsd/datastruct"
+	tcol "github.com/jphsd/texture/color"
 	"image"
 	"image/color"
 )
@@ -194,3 +195,120 @@ func (t *TextureGray16) Gray16At(x, y int) color.Color {
 
 	return g16
 }
+
+// TextureFRGBA is a lazily evaluated high dynamic range image. Colors are stored as non-premultiplied float32
+// RGBA values which, unlike the other realizers, are not clipped to [0,1].
+type TextureFRGBA struct {
+	Src    ColorField
+	Rect   image.Rectangle
+	Pix    []float32 // Evaluated pixels, R, G, B, A
+	Stride int
+	Ox, Oy float64
+	Dx, Dy float64
+	bits   datastruct.Bits // True if pixel has already been evaluated
+}
+
+// NewTextureFRGBA creates a new TextureFRGBA from the supplied parameters
+func NewTextureFRGBA(width, height int, src ColorField, ox, oy, dx, dy float64, cache bool) *TextureFRGBA {
+	var bits datastruct.Bits
+	if cache {
+		bits = datastruct.NewBits(width * height)
+	}
+	rect := image.Rectangle{image.Point{}, image.Point{width, height}}
+	pix := make([]float32, width*height*4)
+	return &TextureFRGBA{src, rect, pix, width, ox, oy, dx, dy, bits}
+}
+
+// ColorModel implements the ColorModel function in the Image interface.
+func (t *TextureFRGBA) ColorModel() color.Model {
+	return tcol.FRGBAModel
+}
+
+// Bounds implements the Bounds function in the Image interface.
+func (t *TextureFRGBA) Bounds() image.Rectangle {
+	return t.Rect
+}
+
+// At implements the At function in the Image interface.
+func (t *TextureFRGBA) At(x, y int) color.Color {
+	return t.FRGBAAt(x, y)
+}
+
+// FRGBAAt returns the unclipped color at x, y.
+func (t *TextureFRGBA) FRGBAAt(x, y int) tcol.FRGBA {
+	if !(image.Point{x, y}.In(t.Rect)) {
+		return tcol.FRGBA{}
+	}
+	i := x + y*t.Stride
+	if t.bits != nil && t.bits.Get(i) {
+		p := t.Pix[i*4 : i*4+4]
+		return tcol.FRGBA{float64(p[0]), float64(p[1]), float64(p[2]), float64(p[3])}
+	}
+	// Pixel not set - evaluate it
+	col := tcol.NewFRGBA(t.Src.Eval2(t.Ox+float64(x)*t.Dx, t.Oy+float64(y)*t.Dy))
+	p := t.Pix[i*4 : i*4+4]
+	p[0], p[1], p[2], p[3] = float32(col.R), float32(col.G), float32(col.B), float32(col.A)
+	if t.bits != nil {
+		t.bits.Set(i)
+	}
+
+	return col
+}
+
+// TextureFloat is a lazily evaluated single channel high dynamic range image. Values are stored as float32
+// and, unlike [TextureGray16], are not clipped to [-1,1]. At returns the value mapped from [-1,1] to a gray.
+type TextureFloat struct {
+	Src    Field
+	Rect   image.Rectangle
+	Pix    []float32 // Evaluated pixels
+	Stride int
+	Ox, Oy float64
+	Dx, Dy float64
+	bits   datastruct.Bits // True if pixel has already been evaluated
+}
+
+// NewTextureFloat creates a new TextureFloat from the supplied parameters
+func NewTextureFloat(width, height int, src Field, ox, oy, dx, dy float64, cache bool) *TextureFloat {
+	var bits datastruct.Bits
+	if cache {
+		bits = datastruct.NewBits(width * height)
+	}
+	rect := image.Rectangle{image.Point{}, image.Point{width, height}}
+	pix := make([]float32, width*height)
+	return &TextureFloat{src, rect, pix, width, ox, oy, dx, dy, bits}
+}
+
+// ColorModel implements the ColorModel function in the Image interface.
+func (t *TextureFloat) ColorModel() color.Model {
+	return tcol.FRGBAModel
+}
+
+// Bounds implements the Bounds function in the Image interface.
+func (t *TextureFloat) Bounds() image.Rectangle {
+	return t.Rect
+}
+
+// At implements the At function in the Image interface.
+func (t *TextureFloat) At(x, y int) color.Color {
+	v := (t.ValueAt(x, y) + 1) / 2
+	return tcol.FRGBA{v, v, v, 1}
+}
+
+// ValueAt returns the unclipped value at x, y.
+func (t *TextureFloat) ValueAt(x, y int) float64 {
+	if !(image.Point{x, y}.In(t.Rect)) {
+		return 0
+	}
+	i := x + y*t.Stride
+	if t.bits != nil && t.bits.Get(i) {
+		return float64(t.Pix[i])
+	}
+	// Pixel not set - evaluate it
+	v := t.Src.Eval2(t.Ox+float64(x)*t.Dx, t.Oy+float64(y)*t.Dy)
+	t.Pix[i] = float32(v)
+	if t.bits != nil {
+		t.bits.Set(i)
+	}
+
+	return v
+}
@@ -0,0 +1,163 @@
+package texture
+
+import (
+	"fmt"
+	gi "github.com/jphsd/graphics2d/image"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// TileFormat defines the layout of a tile pyramid.
+type TileFormat int
+
+// Constants for tile pyramid formats.
+const (
+	DZITiles TileFormat = iota // Deep Zoom - name.dzi and name_files/level/col_row.png
+	XYZTiles                   // Slippy map - name/z/x/y.png
+)
+
+// TilePyramid renders a color field into a multi-resolution tile pyramid for use with zoomable viewers. The
+// finest level covers Width by Height pixels starting at Ox, Oy with steps of Dx and Dy. Coarser levels are
+// evaluated at proportionally larger steps rather than being downsampled from finer ones.
+//
+// In the DZI format, the finest level is log2(max(Width, Height)) and each tile has Overlap pixels shared
+// with its neighbors. In the XYZ format, zoom 0 is a single tile covering the whole image and the finest
+// zoom is the one where a tile's pixels correspond to steps of Dx and Dy.
+type TilePyramid struct {
+	Src      ColorField
+	Width    int
+	Height   int
+	Ox, Oy   float64
+	Dx, Dy   float64
+	TileSize int
+	Overlap  int
+	Format   TileFormat
+}
+
+// NewTilePyramid creates a new TilePyramid from the supplied parameters. Overlap is ignored for XYZ tiles.
+func NewTilePyramid(width, height int, src ColorField, ox, oy, dx, dy float64, tileSize, overlap int, format TileFormat) *TilePyramid {
+	if format == XYZTiles {
+		overlap = 0
+	}
+	return &TilePyramid{src, width, height, ox, oy, dx, dy, tileSize, overlap, format}
+}
+
+// MaxLevel returns the finest level of the pyramid. Levels run from 0 to MaxLevel.
+func (p *TilePyramid) MaxLevel() int {
+	m := float64(max(p.Width, p.Height))
+	if p.Format == XYZTiles {
+		m /= float64(p.TileSize)
+	}
+	if m <= 1 {
+		return 0
+	}
+	return int(math.Ceil(math.Log2(m)))
+}
+
+// LevelSize returns the width and height, in pixels, of the image at the level.
+func (p *TilePyramid) LevelSize(level int) (int, int) {
+	s := math.Exp2(float64(p.MaxLevel() - level))
+	if p.Format == XYZTiles {
+		n := p.TileSize << level
+		return n, n
+	}
+	return int(math.Ceil(float64(p.Width) / s)), int(math.Ceil(float64(p.Height) / s))
+}
+
+// LevelStep returns the step values used to evaluate the level.
+func (p *TilePyramid) LevelStep(level int) (float64, float64) {
+	s := math.Exp2(float64(p.MaxLevel() - level))
+	return p.Dx * s, p.Dy * s
+}
+
+// Tiles returns the number of columns and rows of tiles in the level.
+func (p *TilePyramid) Tiles(level int) (int, int) {
+	w, h := p.LevelSize(level)
+	return (w + p.TileSize - 1) / p.TileSize, (h + p.TileSize - 1) / p.TileSize
+}
+
+// Tile returns a lazily evaluated image of the tile at col, row in the level, including any overlap.
+func (p *TilePyramid) Tile(level, col, row int) *TextureRGBA {
+	w, h := p.LevelSize(level)
+	dx, dy := p.LevelStep(level)
+	x0, y0 := max(col*p.TileSize-p.Overlap, 0), max(row*p.TileSize-p.Overlap, 0)
+	x1, y1 := min((col+1)*p.TileSize+p.Overlap, w), min((row+1)*p.TileSize+p.Overlap, h)
+	return NewTextureRGBA(x1-x0, y1-y0, p.Src, p.Ox+float64(x0)*dx, p.Oy+float64(y0)*dy, dx, dy, false)
+}
+
+// TilesFor returns the tiles in the level that cover the viewport with corners x0, y0 and x1, y1, in
+// field coordinates.
+func (p *TilePyramid) TilesFor(level int, x0, y0, x1, y1 float64) []image.Point {
+	dx, dy := p.LevelStep(level)
+	ts := float64(p.TileSize)
+	cols, rows := p.Tiles(level)
+	c0 := max(int(math.Floor((math.Min(x0, x1)-p.Ox)/dx/ts)), 0)
+	c1 := min(int(math.Floor((math.Max(x0, x1)-p.Ox)/dx/ts)), cols-1)
+	r0 := max(int(math.Floor((math.Min(y0, y1)-p.Oy)/dy/ts)), 0)
+	r1 := min(int(math.Floor((math.Max(y0, y1)-p.Oy)/dy/ts)), rows-1)
+	res := []image.Point{}
+	for r := r0; r <= r1; r++ {
+		for c := c0; c <= c1; c++ {
+			res = append(res, image.Point{c, r})
+		}
+	}
+	return res
+}
+
+// Save renders every tile in the pyramid into dir using name (see [TileFormat]).
+func (p *TilePyramid) Save(dir, name string) error {
+	for level := 0; level <= p.MaxLevel(); level++ {
+		cols, rows := p.Tiles(level)
+		for r := 0; r < rows; r++ {
+			for c := 0; c < cols; c++ {
+				if err := p.SaveTile(dir, name, level, c, r); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return p.saveDescriptor(dir, name)
+}
+
+// SaveViewport renders only the tiles in the level that cover the viewport with corners x0, y0 and x1, y1,
+// in field coordinates.
+func (p *TilePyramid) SaveViewport(dir, name string, level int, x0, y0, x1, y1 float64) error {
+	for _, t := range p.TilesFor(level, x0, y0, x1, y1) {
+		if err := p.SaveTile(dir, name, level, t.X, t.Y); err != nil {
+			return err
+		}
+	}
+	return p.saveDescriptor(dir, name)
+}
+
+// SaveTile renders a single tile into dir using name.
+func (p *TilePyramid) SaveTile(dir, name string, level, col, row int) error {
+	var path string
+	if p.Format == XYZTiles {
+		path = filepath.Join(dir, name, fmt.Sprint(level), fmt.Sprint(col), fmt.Sprint(row))
+	} else {
+		path = filepath.Join(dir, name+"_files", fmt.Sprint(level), fmt.Sprintf("%d_%d", col, row))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return gi.SaveImage(p.Tile(level, col, row), path)
+}
+
+// saveDescriptor writes name.dzi for DZI pyramids.
+func (p *TilePyramid) saveDescriptor(dir, name string) error {
+	if p.Format != DZITiles {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	dzi := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<Image xmlns="http://schemas.microsoft.com/deepzoom/2008" Format="png" Overlap="%d" TileSize="%d">
+  <Size Width="%d" Height="%d"/>
+</Image>
+`, p.Overlap, p.TileSize, p.Width, p.Height)
+	return os.WriteFile(filepath.Join(dir, name+".dzi"), []byte(dzi), 0o644)
+}
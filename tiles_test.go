@@ -0,0 +1,109 @@
+package texture_test
+
+import (
+	"github.com/jphsd/texture"
+	"image"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTilePyramidDZI(t *testing.T) {
+	cf := testColorField()
+	p := texture.NewTilePyramid(1000, 600, cf, 10, 20, 0.5, 0.5, 256, 1, texture.DZITiles)
+	if p.MaxLevel() != 10 {
+		t.Errorf("got max level %d, want 10", p.MaxLevel())
+	}
+	for _, c := range []struct{ level, w, h, cols, rows int }{
+		{10, 1000, 600, 4, 3},
+		{9, 500, 300, 2, 2},
+		{8, 250, 150, 1, 1},
+		{0, 1, 1, 1, 1},
+	} {
+		w, h := p.LevelSize(c.level)
+		cols, rows := p.Tiles(c.level)
+		if w != c.w || h != c.h || cols != c.cols || rows != c.rows {
+			t.Errorf("level %d: got %dx%d in %dx%d tiles, want %dx%d in %dx%d", c.level, w, h, cols, rows,
+				c.w, c.h, c.cols, c.rows)
+		}
+	}
+	if dx, dy := p.LevelStep(9); dx != 1 || dy != 1 {
+		t.Errorf("got level 9 step %g, %g, want 1, 1", dx, dy)
+	}
+
+	// Tiles include the overlap on their inner edges
+	for _, c := range []struct{ col, row, w, h int }{
+		{0, 0, 257, 257},
+		{1, 1, 258, 258},
+		{3, 2, 233, 89},
+	} {
+		tile := p.Tile(10, c.col, c.row)
+		b := tile.Bounds()
+		if b.Dx() != c.w || b.Dy() != c.h {
+			t.Errorf("tile %d,%d: got %dx%d, want %dx%d", c.col, c.row, b.Dx(), b.Dy(), c.w, c.h)
+		}
+		// The top left pixel is at the tile's offset less the overlap
+		x0, y0 := max(c.col*256-1, 0), max(c.row*256-1, 0)
+		want := cf.Eval2(10+float64(x0)*0.5, 20+float64(y0)*0.5)
+		if !colorNear(tile.At(b.Min.X, b.Min.Y), want, 0x101) {
+			t.Errorf("tile %d,%d: got %v at its origin, want %v", c.col, c.row, tile.At(b.Min.X, b.Min.Y), want)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := p.SaveViewport(dir, "img", 10, 10, 20, 200, 200); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"img.dzi", "img_files/10/0_0.png", "img_files/10/1_1.png"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Error(err)
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "img.dzi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); !strings.Contains(s, `<Size Width="1000" Height="600"/>`) ||
+		!strings.Contains(s, `Overlap="1" TileSize="256"`) {
+		t.Errorf("descriptor %s", s)
+	}
+}
+
+func TestTilePyramidXYZ(t *testing.T) {
+	p := texture.NewTilePyramid(1024, 1024, testColorField(), 0, 0, 1, 1, 256, 4, texture.XYZTiles)
+	if p.Overlap != 0 {
+		t.Errorf("got overlap %d, want 0", p.Overlap)
+	}
+	if p.MaxLevel() != 2 {
+		t.Errorf("got max level %d, want 2", p.MaxLevel())
+	}
+	for z, want := range []int{1, 2, 4} {
+		if cols, rows := p.Tiles(z); cols != want || rows != want {
+			t.Errorf("zoom %d: got %dx%d tiles, want %dx%d", z, cols, rows, want, want)
+		}
+	}
+	if dx, _ := p.LevelStep(0); dx != 4 {
+		t.Errorf("got zoom 0 step %g, want 4", dx)
+	}
+
+	// A viewport over field coordinates 300-700 at zoom 2 needs tiles 1 and 2 in each direction
+	got := p.TilesFor(2, 300, 300, 700, 700)
+	want := []image.Point{{1, 1}, {2, 1}, {1, 2}, {2, 2}}
+	if !slices.Equal(got, want) {
+		t.Errorf("got tiles %v, want %v", got, want)
+	}
+	// And is clamped to the pyramid
+	if got := p.TilesFor(1, -100, -100, 5000, 5000); len(got) != 4 {
+		t.Errorf("got %d tiles for an oversized viewport, want 4", len(got))
+	}
+
+	dir := t.TempDir()
+	if err := p.SaveTile(dir, "map", 2, 3, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "map/2/3/1.png")); err != nil {
+		t.Error(err)
+	}
+}
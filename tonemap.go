@@ -0,0 +1,130 @@
+package texture
+
+import (
+	tcol "github.com/jphsd/texture/color"
+	"image/color"
+	"math"
+)
+
+// ToneMapOp defines the operator used to map high dynamic range colors into [0,1].
+type ToneMapOp int
+
+// Constants for tone mapping operators. ClipToneMap only applies the exposure and gamma.
+const (
+	ClipToneMap ToneMapOp = iota
+	ReinhardToneMap
+	ACESToneMap
+	FilmicToneMap
+)
+
+// ToneMap scales the high dynamic range colors from the source by 2^Exposure, compresses them into [0,1]
+// using the operator and then applies the gamma correction. ACESToneMap uses Narkowicz's fit of the ACES
+// curve and FilmicToneMap uses Hable's Uncharted 2 curve.
+type ToneMap struct {
+	Name     string
+	Src      ColorField
+	Op       ToneMapOp
+	Exposure float64
+	Gamma    float64
+}
+
+func NewToneMap(src ColorField, op ToneMapOp, exposure, gamma float64) *ToneMap {
+	return &ToneMap{"ToneMap", src, op, exposure, gamma}
+}
+
+// Eval2 implements the ColorField interface.
+func (t *ToneMap) Eval2(x, y float64) color.Color {
+	c := tcol.NewFRGBA(t.Src.Eval2(x, y))
+	c = c.Scale(math.Pow(2, t.Exposure))
+	var f func(float64) float64
+	switch t.Op {
+	default:
+		fallthrough
+	case ClipToneMap:
+		f = func(v float64) float64 { return v }
+	case ReinhardToneMap:
+		f = func(v float64) float64 { return v / (1 + v) }
+	case ACESToneMap:
+		f = func(v float64) float64 { return (v * (2.51*v + 0.03)) / (v*(2.43*v+0.59) + 0.14) }
+	case FilmicToneMap:
+		const w = 11.2
+		f = func(v float64) float64 { return hable(2*v) / hable(w) }
+	}
+	g := 1.0
+	if t.Gamma > 0 {
+		g = 1 / t.Gamma
+	}
+	tm := func(v float64) float64 {
+		return math.Pow(bcclamp(f(math.Max(v, 0))), g)
+	}
+	return tcol.FRGBA{tm(c.R), tm(c.G), tm(c.B), bcclamp(c.A)}
+}
+
+func hable(v float64) float64 {
+	const a, b, c, d, e, f = 0.15, 0.5, 0.1, 0.2, 0.02, 0.3
+	return ((v*(a*v+c*b) + d*e) / (v*(a*v+b) + d*f)) - e/f
+}
+
+// ColorHighlights extracts the parts of the source brighter than Threshold, with a soft transition of
+// width Knee, for use in bloom effects.
+type ColorHighlights struct {
+	Name      string
+	Src       ColorField
+	Threshold float64
+	Knee      float64
+}
+
+func NewColorHighlights(src ColorField, threshold, knee float64) *ColorHighlights {
+	return &ColorHighlights{"ColorHighlights", src, threshold, knee}
+}
+
+// Eval2 implements the ColorField interface.
+func (h *ColorHighlights) Eval2(x, y float64) color.Color {
+	return highlight(tcol.NewFRGBA(h.Src.Eval2(x, y)), h.Threshold, h.Knee)
+}
+
+func highlight(c tcol.FRGBA, threshold, knee float64) tcol.FRGBA {
+	b := math.Max(c.R, math.Max(c.G, c.B))
+	if b < 0.000001 {
+		return tcol.FRGBA{0, 0, 0, c.A}
+	}
+	// Quadratic soft knee
+	k := math.Max(knee, 0.000001)
+	s := math.Min(math.Max(b-threshold+k, 0), 2*k)
+	s = s * s / (4 * k)
+	w := math.Max(s, b-threshold) / b
+	return c.Scale(w)
+}
+
+// ColorBloom adds a blurred copy of the source's highlights (see [ColorHighlights]) back to the source
+// without clamping. The blur is a Gaussian of the given radius sampled on a 7x7 grid, so the source is
+// evaluated 50 times per location.
+type ColorBloom struct {
+	Name      string
+	Src       ColorField
+	Threshold float64
+	Knee      float64
+	Radius    float64
+	Intensity float64
+}
+
+func NewColorBloom(src ColorField, threshold, knee, radius, intensity float64) *ColorBloom {
+	return &ColorBloom{"ColorBloom", src, threshold, knee, radius, intensity}
+}
+
+// Eval2 implements the ColorField interface.
+func (b *ColorBloom) Eval2(x, y float64) color.Color {
+	c := tcol.NewFRGBA(b.Src.Eval2(x, y))
+	step := b.Radius / 3
+	ws := 0.0
+	blur := tcol.FRGBA{}
+	for j := -3; j <= 3; j++ {
+		for i := -3; i <= 3; i++ {
+			w := math.Exp(-float64(i*i+j*j) / 4.5)
+			h := highlight(tcol.NewFRGBA(b.Src.Eval2(x+float64(i)*step, y+float64(j)*step)), b.Threshold, b.Knee)
+			blur = blur.Sum(h.Scale(w * h.A))
+			ws += w
+		}
+	}
+	return c.Sum(blur.Scale(b.Intensity / ws))
+}
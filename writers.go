@@ -0,0 +1,472 @@
+package texture
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	tcol "github.com/jphsd/texture/color"
+	"io"
+	"math"
+	"os"
+)
+
+// The writers in this file realize a Field, VectorField or ColorField over the region defined by the width,
+// height, origin and step values, one row at a time, so that large renders don't need to be held in memory.
+// Field values are written as a single channel, vectors as three (R, G, B = X, Y, Z) and colors as four
+// (R, G, B, A), except for PFM which has no alpha channel. Float formats store values unmapped and unclamped.
+
+// EXRPixelType defines the precision of the channels in an OpenEXR file.
+type EXRPixelType int
+
+// Constants for OpenEXR pixel types.
+const (
+	EXRHalf EXRPixelType = iota
+	EXRFloat
+)
+
+// EXRCompression defines the compression used for the scanlines in an OpenEXR file.
+type EXRCompression int
+
+// Constants for OpenEXR compression.
+const (
+	EXRNoCompression EXRCompression = iota
+	EXRZIPCompression
+)
+
+// SaveEXR writes the source to name.exr (see [WriteEXR]).
+func SaveEXR(name string, src any, width, height int, ox, oy, dx, dy float64, ptype EXRPixelType, comp EXRCompression) error {
+	f, err := os.Create(fmt.Sprintf("%s.exr", name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return WriteEXR(f, src, width, height, ox, oy, dx, dy, ptype, comp)
+}
+
+// WriteEXR writes the source as a single part, scanline OpenEXR image with either half or float channels,
+// uncompressed or ZIP compressed in blocks of 16 lines. Colors are written with premultiplied alpha, as
+// required by OpenEXR. The writer must support seeking so that the line offset table can be filled in.
+func WriteEXR(w io.WriteSeeker, src any, width, height int, ox, oy, dx, dy float64, ptype EXRPixelType, comp EXRCompression) error {
+	n, eval, err := rowSampler(src, width, ox, dx)
+	if err != nil {
+		return err
+	}
+
+	// Channels must be in alphabetical order, map them to the sampler's order
+	names, order := []string{"Y"}, []int{0}
+	switch n {
+	case 3:
+		names, order = []string{"B", "G", "R"}, []int{2, 1, 0}
+	case 4:
+		names, order = []string{"A", "B", "G", "R"}, []int{3, 2, 1, 0}
+	}
+	lpb := 1
+	if comp == EXRZIPCompression {
+		lpb = 16
+	}
+
+	// Header
+	hdr := &bytes.Buffer{}
+	hdr.Write([]byte{0x76, 0x2f, 0x31, 0x01, 2, 0, 0, 0})
+	chl := &bytes.Buffer{}
+	for _, name := range names {
+		chl.WriteString(name)
+		chl.WriteByte(0)
+		binary.Write(chl, binary.LittleEndian, []int32{int32(ptype) + 1})
+		chl.Write([]byte{0, 0, 0, 0})
+		binary.Write(chl, binary.LittleEndian, []int32{1, 1})
+	}
+	chl.WriteByte(0)
+	exrAttr(hdr, "channels", "chlist", chl.Bytes())
+	ctype := byte(0)
+	if comp == EXRZIPCompression {
+		ctype = 3
+	}
+	exrAttr(hdr, "compression", "compression", []byte{ctype})
+	box := &bytes.Buffer{}
+	binary.Write(box, binary.LittleEndian, []int32{0, 0, int32(width - 1), int32(height - 1)})
+	exrAttr(hdr, "dataWindow", "box2i", box.Bytes())
+	exrAttr(hdr, "displayWindow", "box2i", box.Bytes())
+	exrAttr(hdr, "lineOrder", "lineOrder", []byte{0})
+	exrAttr(hdr, "pixelAspectRatio", "float", le32(math.Float32bits(1)))
+	exrAttr(hdr, "screenWindowCenter", "v2f", append(le32(0), le32(0)...))
+	exrAttr(hdr, "screenWindowWidth", "float", le32(math.Float32bits(1)))
+	hdr.WriteByte(0)
+	if _, err := w.Write(hdr.Bytes()); err != nil {
+		return err
+	}
+
+	// Placeholder line offset table
+	nblocks := (height + lpb - 1) / lpb
+	offsets := make([]uint64, nblocks)
+	tpos := int64(hdr.Len())
+	if err := binary.Write(w, binary.LittleEndian, offsets); err != nil {
+		return err
+	}
+	pos := tpos + int64(nblocks*8)
+
+	row := make([]float64, width*n)
+	raw := &bytes.Buffer{}
+	for b := 0; b < nblocks; b++ {
+		raw.Reset()
+		y0 := b * lpb
+		for y := y0; y < y0+lpb && y < height; y++ {
+			eval(oy+float64(y)*dy, row)
+			if n == 4 {
+				// Premultiply
+				for i := 0; i < width; i++ {
+					a := row[i*4+3]
+					row[i*4], row[i*4+1], row[i*4+2] = row[i*4]*a, row[i*4+1]*a, row[i*4+2]*a
+				}
+			}
+			for _, c := range order {
+				for i := 0; i < width; i++ {
+					v := float32(row[i*n+c])
+					if ptype == EXRHalf {
+						raw.Write(le16(float32ToHalf(v)))
+					} else {
+						raw.Write(le32(math.Float32bits(v)))
+					}
+				}
+			}
+		}
+		data := raw.Bytes()
+		if comp == EXRZIPCompression {
+			if zd, err := exrZIP(data); err != nil {
+				return err
+			} else if len(zd) < len(data) {
+				data = zd
+			}
+		}
+		offsets[b] = uint64(pos)
+		if err := binary.Write(w, binary.LittleEndian, []int32{int32(y0), int32(len(data))}); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		pos += int64(8 + len(data))
+	}
+
+	// Fill in line offset table
+	if _, err := w.Seek(tpos, io.SeekStart); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, offsets); err != nil {
+		return err
+	}
+	_, err = w.Seek(pos, io.SeekStart)
+	return err
+}
+
+func exrAttr(buf *bytes.Buffer, name, typ string, value []byte) {
+	buf.WriteString(name)
+	buf.WriteByte(0)
+	buf.WriteString(typ)
+	buf.WriteByte(0)
+	buf.Write(le32(uint32(len(value))))
+	buf.Write(value)
+}
+
+// exrZIP applies OpenEXR's byte interleaving and delta predictor before zlib compressing the data.
+func exrZIP(data []byte) ([]byte, error) {
+	n := len(data)
+	tmp := make([]byte, n)
+	h := (n + 1) / 2
+	for i := 0; i < n; i++ {
+		if i&1 == 0 {
+			tmp[i/2] = data[i]
+		} else {
+			tmp[h+i/2] = data[i]
+		}
+	}
+	p := tmp[0]
+	for i := 1; i < n; i++ {
+		d := tmp[i] - p + 128
+		p = tmp[i]
+		tmp[i] = d
+	}
+	out := &bytes.Buffer{}
+	zw := zlib.NewWriter(out)
+	if _, err := zw.Write(tmp); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// float32ToHalf converts a float32 to an IEEE 754 half precision float, rounding to nearest even.
+func float32ToHalf(f float32) uint16 {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int(b>>23&0xff) - 127 + 15
+	mant := b & 0x7fffff
+	switch {
+	case b&0x7fffffff == 0:
+		return sign
+	case b>>23&0xff == 0xff:
+		// Inf or NaN
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	case exp >= 0x1f:
+		// Overflow to Inf
+		return sign | 0x7c00
+	case exp <= 0:
+		// Subnormal or zero
+		if exp < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		shift := uint(14 - exp)
+		hm := mant >> shift
+		rem := mant & (1<<shift - 1)
+		half := uint32(1) << (shift - 1)
+		if rem > half || (rem == half && hm&1 == 1) {
+			hm++
+		}
+		return sign | uint16(hm)
+	}
+	hm := mant >> 13
+	rem := mant & 0x1fff
+	h := uint32(exp)<<10 | hm
+	if rem > 0x1000 || (rem == 0x1000 && hm&1 == 1) {
+		h++ // May carry into the exponent, which is correct
+	}
+	return sign | uint16(h)
+}
+
+// SavePFM writes the source to name.pfm (see [WritePFM]).
+func SavePFM(name string, src any, width, height int, ox, oy, dx, dy float64) error {
+	f, err := os.Create(fmt.Sprintf("%s.pfm", name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	bw := bufio.NewWriter(f)
+	if err := WritePFM(bw, src, width, height, ox, oy, dx, dy); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// WritePFM writes the source as a little endian Portable Float Map. Fields are written as grayscale,
+// vectors and colors as RGB. PFM stores rows bottom to top, so the rows are evaluated in that order.
+func WritePFM(w io.Writer, src any, width, height int, ox, oy, dx, dy float64) error {
+	n, eval, err := rowSampler(src, width, ox, dx)
+	if err != nil {
+		return err
+	}
+	id, nc := "PF", 3
+	if n == 1 {
+		id, nc = "Pf", 1
+	}
+	if _, err := fmt.Fprintf(w, "%s\n%d %d\n-1.0\n", id, width, height); err != nil {
+		return err
+	}
+	row := make([]float64, width*n)
+	out := make([]byte, width*nc*4)
+	for y := height - 1; y >= 0; y-- {
+		eval(oy+float64(y)*dy, row)
+		for i := 0; i < width; i++ {
+			for c := 0; c < nc; c++ {
+				binary.LittleEndian.PutUint32(out[(i*nc+c)*4:], math.Float32bits(float32(row[i*n+c])))
+			}
+		}
+		if _, err := w.Write(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveTIFF writes the source to name.tiff (see [WriteTIFF]).
+func SaveTIFF(name string, src any, width, height int, ox, oy, dx, dy float64, float bool) error {
+	f, err := os.Create(fmt.Sprintf("%s.tiff", name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	bw := bufio.NewWriter(f)
+	if err := WriteTIFF(bw, src, width, height, ox, oy, dx, dy, float); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// WriteTIFF writes the source as an uncompressed, little endian TIFF with either 16 bit unsigned or 32 bit
+// float samples. For 16 bit output, field and vector values are mapped from [-1,1] to [0,1] and all values
+// are clamped. Colors are written as RGBA with unassociated alpha. The image data must be less than 4GB.
+func WriteTIFF(w io.Writer, src any, width, height int, ox, oy, dx, dy float64, float bool) error {
+	n, eval, err := rowSampler(src, width, ox, dx)
+	if err != nil {
+		return err
+	}
+	bps, sfmt := uint16(16), uint16(1)
+	if float {
+		bps, sfmt = 32, 3
+	}
+	row := make([]float64, width*n)
+	return writeTIFF(w, width, height, n, bps, sfmt, func(y int, out []byte) error {
+		eval(oy+float64(y)*dy, row)
+		for i, v := range row {
+			if float {
+				binary.LittleEndian.PutUint32(out[i*4:], math.Float32bits(float32(v)))
+				continue
+			}
+			if n != 4 {
+				v = (v + 1) / 2
+			}
+			binary.LittleEndian.PutUint16(out[i*2:], uint16(bcclamp(v)*0xffff+0.5))
+		}
+		return nil
+	})
+}
+
+// writeTIFF writes an uncompressed, little endian TIFF with n samples per pixel, each of bps bits, in the
+// sample format (1 unsigned, 3 float), filling each row of data with the row function.
+func writeTIFF(w io.Writer, width, height, n int, bps, sfmt uint16, rowf func(y int, out []byte) error) error {
+	rowBytes := width * n * int(bps/8)
+	dataSize := uint64(rowBytes) * uint64(height)
+	if dataSize > math.MaxUint32-0x10000 {
+		return fmt.Errorf("image too large for TIFF")
+	}
+
+	// Header - the IFD follows the image data, padded to a word boundary
+	pad := dataSize & 1
+	ifd := uint32(8 + dataSize + pad)
+	if _, err := w.Write(append([]byte{'I', 'I', 42, 0}, le32(ifd)...)); err != nil {
+		return err
+	}
+
+	out := make([]byte, rowBytes)
+	for y := 0; y < height; y++ {
+		if err := rowf(y, out); err != nil {
+			return err
+		}
+		if _, err := w.Write(out); err != nil {
+			return err
+		}
+	}
+	if pad > 0 {
+		if _, err := w.Write([]byte{0}); err != nil {
+			return err
+		}
+	}
+
+	// IFD entries, in tag order, with out of line values following the IFD
+	type entry struct {
+		tag, typ uint16
+		vals     []uint32
+	}
+	photo := uint16(1)
+	if n > 1 {
+		photo = 2
+	}
+	offs, counts := make([]uint32, height), make([]uint32, height)
+	for y := range offs {
+		offs[y] = uint32(8 + y*rowBytes)
+		counts[y] = uint32(rowBytes)
+	}
+	rep := func(v uint16) []uint32 {
+		res := make([]uint32, n)
+		for i := range res {
+			res[i] = uint32(v)
+		}
+		return res
+	}
+	entries := []entry{
+		{256, 4, []uint32{uint32(width)}},
+		{257, 4, []uint32{uint32(height)}},
+		{258, 3, rep(bps)},
+		{259, 3, []uint32{1}},
+		{262, 3, []uint32{uint32(photo)}},
+		{273, 4, offs},
+		{277, 3, []uint32{uint32(n)}},
+		{278, 4, []uint32{1}},
+		{279, 4, counts},
+		{284, 3, []uint32{1}},
+	}
+	if n == 4 {
+		entries = append(entries, entry{338, 3, []uint32{2}})
+	}
+	entries = append(entries, entry{339, 3, rep(sfmt)})
+
+	buf := &bytes.Buffer{}
+	extra := &bytes.Buffer{}
+	extraOff := ifd + 2 + uint32(len(entries))*12 + 4
+	buf.Write(le16(uint16(len(entries))))
+	for _, e := range entries {
+		buf.Write(le16(e.tag))
+		buf.Write(le16(e.typ))
+		buf.Write(le32(uint32(len(e.vals))))
+		val := &bytes.Buffer{}
+		for _, v := range e.vals {
+			if e.typ == 3 {
+				val.Write(le16(uint16(v)))
+			} else {
+				val.Write(le32(v))
+			}
+		}
+		if val.Len() <= 4 {
+			vb := append(val.Bytes(), make([]byte, 4-val.Len())...)
+			buf.Write(vb)
+			continue
+		}
+		buf.Write(le32(extraOff + uint32(extra.Len())))
+		extra.Write(val.Bytes())
+		if extra.Len()&1 == 1 {
+			extra.WriteByte(0)
+		}
+	}
+	buf.Write(le32(0))
+	buf.Write(extra.Bytes())
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// rowSampler returns the number of channels for the source and a function that evaluates a row of
+// width samples into dst.
+func rowSampler(src any, width int, ox, dx float64) (int, func(y float64, dst []float64), error) {
+	switch s := src.(type) {
+	case Field:
+		return 1, func(y float64, dst []float64) {
+			for i := 0; i < width; i++ {
+				dst[i] = s.Eval2(ox+float64(i)*dx, y)
+			}
+		}, nil
+	case VectorField:
+		return 3, func(y float64, dst []float64) {
+			for i := 0; i < width; i++ {
+				v := s.Eval2(ox+float64(i)*dx, y)
+				for c := 0; c < 3; c++ {
+					dst[i*3+c] = 0
+					if c < len(v) {
+						dst[i*3+c] = v[c]
+					}
+				}
+			}
+		}, nil
+	case ColorField:
+		return 4, func(y float64, dst []float64) {
+			for i := 0; i < width; i++ {
+				c := tcol.NewFRGBA(s.Eval2(ox+float64(i)*dx, y))
+				dst[i*4], dst[i*4+1], dst[i*4+2], dst[i*4+3] = c.R, c.G, c.B, c.A
+			}
+		}, nil
+	}
+	return 0, nil, fmt.Errorf("unsupported source type %T", src)
+}
+
+func le16(v uint16) []byte {
+	return binary.LittleEndian.AppendUint16(nil, v)
+}
+
+func le32(v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(nil, v)
+}
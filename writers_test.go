@@ -0,0 +1,271 @@
+package texture_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"github.com/jphsd/texture"
+	tcol "github.com/jphsd/texture/color"
+	"golang.org/x/image/tiff"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testField returns a field that varies in x and y.
+func testField() texture.Field {
+	w := texture.NewNLWave([]float64{23}, []*texture.NonLinear{texture.NewNLP3()}, true, false)
+	return texture.NewAddCombiner(texture.NewLinearGradient(w), texture.NewRadialGradient(w))
+}
+
+// testColorField returns a color field with varying alpha.
+func testColorField() texture.ColorField {
+	return texture.NewColorConv(testField(), color.NRGBA{255, 0, 0, 255}, color.NRGBA{0, 0, 255, 64},
+		[]color.Color{color.NRGBA{0, 255, 0, 160}}, []float64{0.4}, texture.LerpRGBA)
+}
+
+func TestWritePFM(t *testing.T) {
+	f := testField()
+	var buf bytes.Buffer
+	if err := texture.WritePFM(&buf, f, 7, 5, 1, 2, 3, 4); err != nil {
+		t.Fatal(err)
+	}
+	hdr := "Pf\n7 5\n-1.0\n"
+	if !strings.HasPrefix(buf.String(), hdr) {
+		t.Fatalf("header %q", buf.String()[:len(hdr)])
+	}
+	data := buf.Bytes()[len(hdr):]
+	if len(data) != 7*5*4 {
+		t.Fatalf("got %d bytes of data, want %d", len(data), 7*5*4)
+	}
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 7; x++ {
+			// Rows are stored bottom up
+			i := ((4-y)*7 + x) * 4
+			got := math.Float32frombits(binary.LittleEndian.Uint32(data[i:]))
+			if want := float32(f.Eval2(1+float64(x)*3, 2+float64(y)*4)); got != want {
+				t.Errorf("%d,%d: got %g, want %g", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestWriteTIFF(t *testing.T) {
+	f, cf := testField(), testColorField()
+	for _, src := range []any{f, cf} {
+		var buf bytes.Buffer
+		if err := texture.WriteTIFF(&buf, src, 9, 6, 0, 0, 2, 3, false); err != nil {
+			t.Fatal(err)
+		}
+		img, err := tiff.Decode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := img.Bounds(); b.Dx() != 9 || b.Dy() != 6 {
+			t.Fatalf("got size %v, want 9x6", b)
+		}
+		for y := 0; y < 6; y++ {
+			for x := 0; x < 9; x++ {
+				fx, fy := float64(x)*2, float64(y)*3
+				var want color.Color
+				if src == any(f) {
+					want = color.Gray16{uint16((f.Eval2(fx, fy)+1)/2*0xffff + 0.5)}
+				} else {
+					c := tcol.NewFRGBA(cf.Eval2(fx, fy))
+					want = color.NRGBA64{uint16(c.R*0xffff + 0.5), uint16(c.G*0xffff + 0.5),
+						uint16(c.B*0xffff + 0.5), uint16(c.A*0xffff + 0.5)}
+				}
+				if !colorNear(img.At(x, y), want, 2) {
+					t.Errorf("%T %d,%d: got %v, want %v", src, x, y, img.At(x, y), want)
+				}
+			}
+		}
+	}
+}
+
+// colorNear reports whether the RGBA values of two colors differ by at most d.
+func colorNear(c1, c2 color.Color, d int) bool {
+	r1, g1, b1, a1 := c1.RGBA()
+	r2, g2, b2, a2 := c2.RGBA()
+	v1, v2 := []uint32{r1, g1, b1, a1}, []uint32{r2, g2, b2, a2}
+	for i := range v1 {
+		if diff := int(v1[i]) - int(v2[i]); diff > d || diff < -d {
+			return false
+		}
+	}
+	return true
+}
+
+func TestWriteEXR(t *testing.T) {
+	cf := testColorField()
+	for _, ptype := range []texture.EXRPixelType{texture.EXRHalf, texture.EXRFloat} {
+		for _, comp := range []texture.EXRCompression{texture.EXRNoCompression, texture.EXRZIPCompression} {
+			name := filepath.Join(t.TempDir(), "t")
+			if err := texture.SaveEXR(name, cf, 20, 37, 0, 0, 1.5, 1.5, ptype, comp); err != nil {
+				t.Fatal(err)
+			}
+			chans, pix, err := readEXR(name + ".exr")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(chans, "") != "ABGR" {
+				t.Fatalf("channels %v", chans)
+			}
+			tol := 1e-6
+			if ptype == texture.EXRHalf {
+				tol = 1e-3
+			}
+			for y := 0; y < 37; y++ {
+				for x := 0; x < 20; x++ {
+					c := tcol.NewFRGBA(cf.Eval2(float64(x)*1.5, float64(y)*1.5))
+					// Premultiplied, in channel order
+					want := []float64{c.A, c.B * c.A, c.G * c.A, c.R * c.A}
+					for k, v := range want {
+						if got := pix[k][y*20+x]; math.Abs(got-v) > tol {
+							t.Fatalf("%d %d: %s at %d,%d: got %g, want %g", ptype, comp, chans[k], x, y, got, v)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// readEXR decodes the single part, scanline OpenEXR files written by WriteEXR, returning the channel
+// names and their values.
+func readEXR(name string) ([]string, [][]float64, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.HasPrefix(b, []byte{0x76, 0x2f, 0x31, 0x01, 2, 0, 0, 0}) {
+		return nil, nil, fmt.Errorf("bad magic or version")
+	}
+	// cstr returns the null terminated string at p in buf and the position after it
+	cstr := func(buf []byte, p int) (string, int) {
+		e := bytes.IndexByte(buf[p:], 0)
+		return string(buf[p : p+e]), p + e + 1
+	}
+
+	// Header attributes
+	var chans []string
+	var types []int32
+	var comp byte
+	var w, h int
+	p := 8
+	for b[p] != 0 {
+		var an, at string
+		an, p = cstr(b, p)
+		at, p = cstr(b, p)
+		size := int(binary.LittleEndian.Uint32(b[p:]))
+		p += 4
+		v := b[p : p+size]
+		switch at {
+		case "chlist":
+			for q := 0; v[q] != 0; q += 16 {
+				var cn string
+				cn, q = cstr(v, q)
+				chans = append(chans, cn)
+				types = append(types, int32(binary.LittleEndian.Uint32(v[q:])))
+			}
+		case "compression":
+			comp = v[0]
+		case "box2i":
+			if an == "dataWindow" {
+				w = int(int32(binary.LittleEndian.Uint32(v[8:]))) + 1
+				h = int(int32(binary.LittleEndian.Uint32(v[12:]))) + 1
+			}
+		}
+		p += size
+	}
+	p++
+
+	lpb := 1
+	if comp == 3 {
+		lpb = 16
+	}
+	nb := (h + lpb - 1) / lpb
+	pix := make([][]float64, len(chans))
+	for k := range pix {
+		pix[k] = make([]float64, w*h)
+	}
+	for bi := 0; bi < nb; bi++ {
+		off := int(binary.LittleEndian.Uint64(b[p+bi*8:]))
+		y0 := int(int32(binary.LittleEndian.Uint32(b[off:])))
+		size := int(binary.LittleEndian.Uint32(b[off+4:]))
+		data := b[off+8 : off+8+size]
+		lines := min(lpb, h-y0)
+		// Half channels have type 1 and two bytes per sample, float ones type 2 and four
+		raw := 0
+		for _, t := range types {
+			raw += w * lines * 2 * int(t)
+		}
+		if comp == 3 && size < raw {
+			if data, err = exrUnzip(data); err != nil {
+				return nil, nil, err
+			}
+		}
+		q := 0
+		for y := y0; y < y0+lines; y++ {
+			for k, t := range types {
+				for x := 0; x < w; x++ {
+					if t == 1 {
+						pix[k][y*w+x] = halfToFloat(binary.LittleEndian.Uint16(data[q:]))
+						q += 2
+					} else {
+						pix[k][y*w+x] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[q:])))
+						q += 4
+					}
+				}
+			}
+		}
+	}
+	return chans, pix, nil
+}
+
+func exrUnzip(data []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(tmp); i++ {
+		tmp[i] = tmp[i-1] + tmp[i] - 128
+	}
+	n, h := len(tmp), (len(tmp)+1)/2
+	out := make([]byte, n)
+	for i := 0; i < n; i++ {
+		if i&1 == 0 {
+			out[i] = tmp[i/2]
+		} else {
+			out[i] = tmp[h+i/2]
+		}
+	}
+	return out, nil
+}
+
+func halfToFloat(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exp, mant := int(h>>10&0x1f), float64(h&0x3ff)
+	switch exp {
+	case 0:
+		return sign * mant * math.Pow(2, -24)
+	case 0x1f:
+		if mant != 0 {
+			return math.NaN()
+		}
+		return sign * math.Inf(1)
+	}
+	return sign * (1 + mant/1024) * math.Pow(2, float64(exp-15))
+}